@@ -0,0 +1,220 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distro
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+const (
+	// baggageAttributesKey is the comma-separated list of baggage keys, or
+	// key patterns, copied onto spans and log records.
+	baggageAttributesKey = "SPLUNK_BAGGAGE_ATTRIBUTES"
+	// baggageAttributesMaxCountKey is the maximum number of baggage members
+	// copied onto a span or log record.
+	baggageAttributesMaxCountKey = "SPLUNK_BAGGAGE_ATTRIBUTES_MAX_COUNT"
+	// baggageAttributesMaxValueLengthKey is the maximum length of the
+	// baggage values copied, longer values are truncated.
+	baggageAttributesMaxValueLengthKey = "SPLUNK_BAGGAGE_ATTRIBUTES_MAX_VALUE_LENGTH"
+
+	defaultBaggageAttributesMaxCount       = 10
+	defaultBaggageAttributesMaxValueLength = 256
+)
+
+// BaggageAttributesConfig configures the baggage members copied onto spans
+// and log records.
+type BaggageAttributesConfig struct {
+	// Keys are the baggage keys copied. A key can be a pattern using the
+	// syntax of path.Match, e.g. "tenant.*".
+	Keys []string
+	// MaxCount is the maximum number of baggage members copied onto a span
+	// or log record. Above it, the members with the first keys in
+	// lexicographic order are copied. If zero, 10 is used.
+	MaxCount int
+	// MaxValueLength is the maximum length of a copied value. Longer values
+	// are truncated. If zero, 256 is used.
+	MaxValueLength int
+}
+
+// WithBaggageAttributes configures the distro to copy the W3C baggage
+// members matching conf.Keys onto every span when it starts and every log
+// record when it is emitted. Only allowlisted members are copied, and the
+// number and size of the copied members are limited, so baggage set by
+// untrusted callers cannot bloat the telemetry.
+//
+// This option takes precedence over the SPLUNK_BAGGAGE_ATTRIBUTES,
+// SPLUNK_BAGGAGE_ATTRIBUTES_MAX_COUNT, and
+// SPLUNK_BAGGAGE_ATTRIBUTES_MAX_VALUE_LENGTH environment variables.
+func WithBaggageAttributes(conf BaggageAttributesConfig) Option {
+	return optionFunc(func(c *config) {
+		c.BaggageAttributes = &conf
+	})
+}
+
+// baggageAttributesConfig returns the baggage attributes configuration of c,
+// or nil if no baggage is copied.
+func baggageAttributesConfig(c *config) *BaggageAttributesConfig {
+	conf := c.BaggageAttributes
+	if conf == nil {
+		conf = &BaggageAttributesConfig{
+			MaxCount:       envInt(c.Logger, baggageAttributesMaxCountKey, defaultBaggageAttributesMaxCount),
+			MaxValueLength: envInt(c.Logger, baggageAttributesMaxValueLengthKey, defaultBaggageAttributesMaxValueLength),
+		}
+		for _, k := range strings.Split(os.Getenv(baggageAttributesKey), ",") {
+			if k = strings.TrimSpace(k); k != "" {
+				conf.Keys = append(conf.Keys, k)
+			}
+		}
+	}
+	if len(conf.Keys) == 0 {
+		return nil
+	}
+	return conf
+}
+
+// baggageFilter selects the baggage members to copy.
+type baggageFilter struct {
+	keys     map[string]struct{}
+	patterns []string
+
+	maxCount       int
+	maxValueLength int
+}
+
+func newBaggageFilter(l logr.Logger, conf BaggageAttributesConfig) *baggageFilter {
+	f := &baggageFilter{
+		keys:           make(map[string]struct{}),
+		maxCount:       conf.MaxCount,
+		maxValueLength: conf.MaxValueLength,
+	}
+	if f.maxCount <= 0 {
+		f.maxCount = defaultBaggageAttributesMaxCount
+	}
+	if f.maxValueLength <= 0 {
+		f.maxValueLength = defaultBaggageAttributesMaxValueLength
+	}
+	for _, k := range conf.Keys {
+		if !strings.ContainsAny(k, `*?[\`) {
+			f.keys[k] = struct{}{}
+			continue
+		}
+		if _, err := path.Match(k, ""); err != nil {
+			l.Error(fmt.Errorf("invalid baggage key pattern: %q", k), "ignoring pattern")
+			continue
+		}
+		f.patterns = append(f.patterns, k)
+	}
+	return f
+}
+
+func (f *baggageFilter) match(key string) bool {
+	if _, ok := f.keys[key]; ok {
+		return true
+	}
+	for _, p := range f.patterns {
+		if ok, _ := path.Match(p, key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// members calls fn with the key and value of each baggage member in ctx to
+// copy. The members are sorted by key, the baggage has no defined order, so
+// the same members are copied when there are more than the maximum count.
+func (f *baggageFilter) members(ctx context.Context, fn func(key, value string)) {
+	members := baggage.FromContext(ctx).Members()
+	slices.SortFunc(members, func(a, b baggage.Member) int { return strings.Compare(a.Key(), b.Key()) })
+
+	var n int
+	for _, m := range members {
+		if n >= f.maxCount {
+			return
+		}
+		if !f.match(m.Key()) {
+			continue
+		}
+		v := m.Value()
+		if len(v) > f.maxValueLength {
+			v = truncate(v, f.maxValueLength)
+		}
+		fn(m.Key(), v)
+		n++
+	}
+}
+
+// truncate returns s truncated to at most n bytes without splitting a UTF-8
+// encoded rune.
+func truncate(s string, n int) string {
+	for n > 0 && n < len(s) && !isRuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
+
+// baggageSpanProcessor copies baggage members onto spans when they start.
+type baggageSpanProcessor struct {
+	filter *baggageFilter
+}
+
+var _ trace.SpanProcessor = baggageSpanProcessor{}
+
+func (p baggageSpanProcessor) OnStart(ctx context.Context, s trace.ReadWriteSpan) {
+	p.filter.members(ctx, func(key, value string) {
+		s.SetAttributes(attribute.String(key, value))
+	})
+}
+
+func (baggageSpanProcessor) OnEnd(trace.ReadOnlySpan)         {}
+func (baggageSpanProcessor) Shutdown(context.Context) error   { return nil }
+func (baggageSpanProcessor) ForceFlush(context.Context) error { return nil }
+
+// baggageLogProcessor copies baggage members onto log records when they are
+// emitted. It needs to be registered before the processors exporting the
+// records.
+type baggageLogProcessor struct {
+	filter *baggageFilter
+}
+
+var _ log.Processor = baggageLogProcessor{}
+
+// Enabled returns false, the processor does not need any record to be
+// emitted.
+func (baggageLogProcessor) Enabled(context.Context, log.EnabledParameters) bool { return false }
+
+func (p baggageLogProcessor) OnEmit(ctx context.Context, r *log.Record) error {
+	p.filter.members(ctx, func(key, value string) {
+		r.AddAttributes(attribute.String(key, value))
+	})
+	return nil
+}
+
+func (baggageLogProcessor) Shutdown(context.Context) error   { return nil }
+func (baggageLogProcessor) ForceFlush(context.Context) error { return nil }
@@ -0,0 +1,161 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distro
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tonglil/buflogr"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	otellog "go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func baggageContext(t *testing.T, members ...string) context.Context {
+	t.Helper()
+	b, err := baggage.Parse(strings.Join(members, ","))
+	require.NoError(t, err)
+	return baggage.ContextWithBaggage(context.Background(), b)
+}
+
+func TestBaggageAttributesConfig(t *testing.T) {
+	assert.Nil(t, baggageAttributesConfig(newConfig()), "disabled by default")
+
+	t.Setenv(baggageAttributesKey, "tenant.id, feature.*")
+	t.Setenv(baggageAttributesMaxCountKey, "3")
+	assert.Equal(t, &BaggageAttributesConfig{
+		Keys:           []string{"tenant.id", "feature.*"},
+		MaxCount:       3,
+		MaxValueLength: defaultBaggageAttributesMaxValueLength,
+	}, baggageAttributesConfig(newConfig()))
+
+	conf := BaggageAttributesConfig{Keys: []string{"user.id"}}
+	assert.Equal(t, &conf, baggageAttributesConfig(newConfig(WithBaggageAttributes(conf))))
+}
+
+func TestBaggageFilter(t *testing.T) {
+	var buf bytes.Buffer
+	f := newBaggageFilter(buflogr.NewWithBuffer(&buf), BaggageAttributesConfig{
+		Keys: []string{"tenant.id", "feature.*", "[invalid"},
+	})
+	assert.Contains(t, buf.String(), "[invalid")
+
+	assert.True(t, f.match("tenant.id"))
+	assert.True(t, f.match("feature.flag"))
+	assert.False(t, f.match("tenant.name"))
+	assert.False(t, f.match("[invalid"))
+}
+
+func TestBaggageFilterLimits(t *testing.T) {
+	f := newBaggageFilter(logr.Discard(), BaggageAttributesConfig{
+		Keys:           []string{"*"},
+		MaxCount:       2,
+		MaxValueLength: 4,
+	})
+	ctx := baggageContext(t, "a=1", "b=2", "c=3", "d=%C3%A9%C3%A9%C3%A9")
+
+	got := make(map[string]string)
+	f.members(ctx, func(k, v string) { got[k] = v })
+	assert.Len(t, got, 2, "count not limited")
+
+	f.maxCount = 10
+	got = make(map[string]string)
+	f.members(ctx, func(k, v string) { got[k] = v })
+	assert.Equal(t, "éé", got["d"], "value not truncated on rune boundary")
+}
+
+func TestBaggageFilterMaxCountOrder(t *testing.T) {
+	f := newBaggageFilter(logr.Discard(), BaggageAttributesConfig{
+		Keys:     []string{"*"},
+		MaxCount: 3,
+	})
+	ctx := baggageContext(t, "k=1", "f=1", "a=1", "j=1", "c=1", "h=1", "b=1", "e=1", "i=1", "d=1", "g=1")
+
+	for range 20 {
+		var got []string
+		f.members(ctx, func(k, _ string) { got = append(got, k) })
+		require.Equal(t, []string{"a", "b", "c"}, got, "members not truncated in key order")
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "abc", truncate("abcdef", 3))
+	assert.Equal(t, "é", truncate("éé", 3))
+	assert.Empty(t, truncate("é", 1))
+}
+
+func TestBaggageSpanProcessor(t *testing.T) {
+	f := newBaggageFilter(logr.Discard(), BaggageAttributesConfig{Keys: []string{"tenant.id"}})
+	exp := tracetest.NewInMemoryExporter()
+	tp := trace.NewTracerProvider(
+		trace.WithSpanProcessor(baggageSpanProcessor{filter: f}),
+		trace.WithSyncer(exp),
+	)
+	t.Cleanup(func() { require.NoError(t, tp.Shutdown(context.Background())) })
+
+	ctx := baggageContext(t, "tenant.id=acme", "session=secret")
+	_, span := tp.Tracer("test").Start(ctx, "span")
+	span.End()
+
+	spans := exp.GetSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, []attribute.KeyValue{attribute.String("tenant.id", "acme")}, spans[0].Attributes)
+}
+
+type recordsProcessor struct {
+	log.Processor
+	records []log.Record
+}
+
+func (*recordsProcessor) Enabled(context.Context, log.EnabledParameters) bool { return true }
+
+func (p *recordsProcessor) OnEmit(_ context.Context, r *log.Record) error {
+	p.records = append(p.records, r.Clone())
+	return nil
+}
+
+func (*recordsProcessor) Shutdown(context.Context) error { return nil }
+
+func TestBaggageLogProcessor(t *testing.T) {
+	f := newBaggageFilter(logr.Discard(), BaggageAttributesConfig{Keys: []string{"tenant.id"}})
+	rec := &recordsProcessor{}
+	lp := log.NewLoggerProvider(
+		log.WithProcessor(baggageLogProcessor{filter: f}),
+		log.WithProcessor(rec),
+	)
+	t.Cleanup(func() { require.NoError(t, lp.Shutdown(context.Background())) })
+
+	ctx := baggageContext(t, "tenant.id=acme", "session=secret")
+	var r otellog.Record
+	r.SetBody(attribute.StringValue("body"))
+	lp.Logger("test").Emit(ctx, r)
+
+	require.Len(t, rec.records, 1)
+	var got []attribute.KeyValue
+	rec.records[0].WalkAttributes(func(kv attribute.KeyValue) bool {
+		got = append(got, kv)
+		return true
+	})
+	assert.Equal(t, []attribute.KeyValue{attribute.String("tenant.id", "acme")}, got)
+}
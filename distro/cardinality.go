@@ -0,0 +1,482 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distro
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+const (
+	// metricsCardinalityLimitKey is the maximum number of attribute sets
+	// exported for each instrument.
+	metricsCardinalityLimitKey = "SPLUNK_METRICS_CARDINALITY_LIMIT"
+	// metricsInstrumentCardinalityLimitsKey is a comma-separated list of
+	// instrument and cardinality limit pairs, e.g. "pool.usage=100". The
+	// instrument name is optionally prefixed with its scope name and a colon,
+	// e.g. "go.opentelemetry.io/contrib/instrumentation/runtime:pool.usage=100".
+	metricsInstrumentCardinalityLimitsKey = "SPLUNK_METRICS_INSTRUMENT_CARDINALITY_LIMITS"
+
+	// defaultMetricsCardinalityLimit is the default limit of the
+	// OpenTelemetry SDK.
+	defaultMetricsCardinalityLimit = 2000
+
+	// overflowMetricName is the name of the self-metric counting the
+	// collections an instrument exceeded its cardinality limit in.
+	overflowMetricName = "splunk.metric.cardinality.overflows"
+)
+
+// overflowSet is the attribute set of the overflow series.
+var overflowSet = attribute.NewSet(overflowAttr)
+
+// WithMetricsCardinalityLimit sets the maximum number of distinct attribute
+// sets exported for each instrument. Measurements with new attribute sets
+// above the limit are aggregated into a single series with only the
+// otel.metric.overflow attribute set to true. The number of collections each
+// instrument overflowed in is reported with the
+// splunk.metric.cardinality.overflows counter. A limit of zero or less means
+// no limit.
+//
+// This option takes precedence over the SPLUNK_METRICS_CARDINALITY_LIMIT
+// environment variable. If neither are set, a limit of 2000 is used.
+func WithMetricsCardinalityLimit(limit int) Option {
+	return optionFunc(func(c *config) {
+		c.MetricsCardinalityLimit = &limit
+	})
+}
+
+// WithInstrumentCardinalityLimit sets the cardinality limit of the
+// instrument with name of the instrumentation scope with the scope name,
+// overriding the limit set by WithMetricsCardinalityLimit. If scope is empty,
+// the limit applies to the instruments with name of all the scopes without a
+// limit of their own. A limit of zero or less means no limit.
+//
+// Exponential histograms are only limited by the largest limit configured.
+//
+// This option takes precedence over the limit set for the same instrument by
+// the SPLUNK_METRICS_INSTRUMENT_CARDINALITY_LIMITS environment variable.
+func WithInstrumentCardinalityLimit(scope, name string, limit int) Option {
+	return optionFunc(func(c *config) {
+		if c.InstrumentCardinalityLimits == nil {
+			c.InstrumentCardinalityLimits = make(map[instrumentKey]int)
+		}
+		c.InstrumentCardinalityLimits[instrumentKey{scope: scope, name: name}] = limit
+	})
+}
+
+// instrumentKey identifies the instruments with name of the instrumentation
+// scope with the scope name, or of all the scopes if scope is empty.
+type instrumentKey struct {
+	scope string
+	name  string
+}
+
+// cardinalityLimits are the resolved cardinality limits.
+type cardinalityLimits struct {
+	global      int
+	instruments map[instrumentKey]int
+}
+
+func newCardinalityLimits(c *config) cardinalityLimits {
+	l := cardinalityLimits{
+		global:      envInt(c.Logger, metricsCardinalityLimitKey, defaultMetricsCardinalityLimit),
+		instruments: envCardinalityLimits(c.Logger),
+	}
+	if c.MetricsCardinalityLimit != nil {
+		l.global = *c.MetricsCardinalityLimit
+	}
+	for key, limit := range c.InstrumentCardinalityLimits {
+		l.instruments[key] = limit
+	}
+	return l
+}
+
+// envCardinalityLimits returns the limits set with
+// SPLUNK_METRICS_INSTRUMENT_CARDINALITY_LIMITS.
+func envCardinalityLimits(l logr.Logger) map[instrumentKey]int {
+	limits := make(map[instrumentKey]int)
+	for _, pair := range strings.Split(os.Getenv(metricsInstrumentCardinalityLimitsKey), ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		name, v, ok := strings.Cut(pair, "=")
+		limit, err := strconv.Atoi(strings.TrimSpace(v))
+		if !ok || err != nil {
+			err := fmt.Errorf("invalid %s: %q", metricsInstrumentCardinalityLimitsKey, pair)
+			l.Error(err, "ignoring instrument cardinality limit")
+			continue
+		}
+		// Instrument names cannot contain a colon, scope names can.
+		var key instrumentKey
+		if i := strings.LastIndex(name, ":"); i >= 0 {
+			key.scope = strings.TrimSpace(name[:i])
+			name = name[i+1:]
+		}
+		key.name = strings.TrimSpace(name)
+		limits[key] = limit
+	}
+	return limits
+}
+
+// limit returns the limit of the instrument with name of scope.
+func (l cardinalityLimits) limit(scope, name string) int {
+	if limit, ok := l.instruments[instrumentKey{scope: scope, name: name}]; ok {
+		return limit
+	}
+	if limit, ok := l.instruments[instrumentKey{name: name}]; ok {
+		return limit
+	}
+	return l.global
+}
+
+// max returns the largest limit, or zero if any instrument is not limited.
+func (l cardinalityLimits) max() int {
+	m := l.global
+	for _, limit := range l.instruments {
+		if limit <= 0 || m <= 0 {
+			return 0
+		}
+		m = max(m, limit)
+	}
+	return max(m, 0)
+}
+
+// sdkOption returns the MeterProvider option bounding the memory used by the
+// SDK aggregations. The exporter applies the per-instrument limits.
+func (l cardinalityLimits) sdkOption() metric.Option {
+	return metric.WithCardinalityLimit(l.max())
+}
+
+// seriesLimiter admits the attribute sets of an instrument up to its limit.
+// The attribute sets admitted are kept across collections of cumulative
+// series, and reset every collection of delta series.
+type seriesLimiter struct {
+	limit    int
+	admitted map[attribute.Distinct]struct{}
+}
+
+// admit returns if the series with set is exported as-is. Otherwise, it is
+// folded into the overflow series.
+func (l *seriesLimiter) admit(set attribute.Set) bool {
+	key := set.Equivalent()
+	if key == overflowSet.Equivalent() {
+		// Already overflowed in the SDK.
+		return false
+	}
+	if l.limit <= 0 {
+		return true
+	}
+	if _, ok := l.admitted[key]; ok {
+		return true
+	}
+	// Reserve one series for the overflow.
+	if len(l.admitted) >= l.limit-1 {
+		return false
+	}
+	l.admitted[key] = struct{}{}
+	return true
+}
+
+// cardinalityLimitExporter folds the series of each instrument above its
+// cardinality limit into an overflow series and reports the overflows.
+type cardinalityLimitExporter struct {
+	metric.Exporter
+
+	limits cardinalityLimits
+	start  time.Time
+	// last is the time of the last collection, the start time of the delta
+	// overflow self-metric.
+	last time.Time
+
+	mu        sync.Mutex
+	limiters  map[instrumentation.Scope]map[string]*seriesLimiter
+	overflows map[instrumentKey]int64
+}
+
+func newCardinalityLimitExporter(exp metric.Exporter, limits cardinalityLimits) *cardinalityLimitExporter {
+	now := time.Now()
+	return &cardinalityLimitExporter{
+		Exporter:  exp,
+		limits:    limits,
+		start:     now,
+		last:      now,
+		limiters:  make(map[instrumentation.Scope]map[string]*seriesLimiter),
+		overflows: make(map[instrumentKey]int64),
+	}
+}
+
+func (e *cardinalityLimitExporter) Export(ctx context.Context, rm *metricdata.ResourceMetrics) error {
+	return e.Exporter.Export(ctx, e.limit(rm))
+}
+
+// limit returns a copy of rm with the cardinality limits applied. The data of
+// rm is owned by the reader and is not modified.
+func (e *cardinalityLimitExporter) limit(rm *metricdata.ResourceMetrics) *metricdata.ResourceMetrics {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	overflowTemporality := e.Temporality(metric.InstrumentKindCounter)
+	if overflowTemporality == metricdata.DeltaTemporality {
+		clear(e.overflows)
+	}
+
+	out := &metricdata.ResourceMetrics{
+		Resource:     rm.Resource,
+		ScopeMetrics: make([]metricdata.ScopeMetrics, len(rm.ScopeMetrics)),
+	}
+	for i, sm := range rm.ScopeMetrics {
+		out.ScopeMetrics[i] = metricdata.ScopeMetrics{
+			Scope:   sm.Scope,
+			Metrics: make([]metricdata.Metrics, len(sm.Metrics)),
+		}
+		for j, m := range sm.Metrics {
+			l := e.limiter(sm.Scope, m.Name)
+			if e.temporality(m.Data) == metricdata.DeltaTemporality {
+				clear(l.admitted)
+			}
+			var overflowed bool
+			m.Data, overflowed = limitAggregation(l, m.Data)
+			if overflowed {
+				e.overflows[instrumentKey{scope: sm.Scope.Name, name: m.Name}]++
+			}
+			out.ScopeMetrics[i].Metrics[j] = m
+		}
+	}
+
+	now := time.Now()
+	if len(e.overflows) > 0 {
+		e.appendOverflows(out, overflowTemporality, now)
+	}
+	e.last = now
+	return out
+}
+
+// temporality returns the temporality of data. The temporality of gauges is
+// the one the exporter selects for them.
+func (e *cardinalityLimitExporter) temporality(data metricdata.Aggregation) metricdata.Temporality {
+	switch d := data.(type) {
+	case metricdata.Sum[int64]:
+		return d.Temporality
+	case metricdata.Sum[float64]:
+		return d.Temporality
+	case metricdata.Histogram[int64]:
+		return d.Temporality
+	case metricdata.Histogram[float64]:
+		return d.Temporality
+	default:
+		return e.Temporality(metric.InstrumentKindGauge)
+	}
+}
+
+func (e *cardinalityLimitExporter) limiter(scope instrumentation.Scope, name string) *seriesLimiter {
+	byName, ok := e.limiters[scope]
+	if !ok {
+		byName = make(map[string]*seriesLimiter)
+		e.limiters[scope] = byName
+	}
+	l, ok := byName[name]
+	if !ok {
+		l = &seriesLimiter{limit: e.limits.limit(scope.Name, name), admitted: make(map[attribute.Distinct]struct{})}
+		byName[name] = l
+	}
+	return l
+}
+
+// appendOverflows adds the overflow self-metric, with temporality, collected
+// at now to rm.
+func (e *cardinalityLimitExporter) appendOverflows(rm *metricdata.ResourceMetrics, temporality metricdata.Temporality, now time.Time) {
+	start := e.start
+	if temporality == metricdata.DeltaTemporality {
+		start = e.last
+	}
+	sum := metricdata.Sum[int64]{
+		Temporality: temporality,
+		IsMonotonic: true,
+		DataPoints:  make([]metricdata.DataPoint[int64], 0, len(e.overflows)),
+	}
+	for key, n := range e.overflows {
+		sum.DataPoints = append(sum.DataPoints, metricdata.DataPoint[int64]{
+			Attributes: attribute.NewSet(
+				attribute.String("otel.scope.name", key.scope),
+				attribute.String("metric.name", key.name),
+			),
+			StartTime: start,
+			Time:      now,
+			Value:     n,
+		})
+	}
+	m := metricdata.Metrics{
+		Name:        overflowMetricName,
+		Description: "Number of collections an instrument exceeded its cardinality limit in.",
+		Unit:        "{collection}",
+		Data:        sum,
+	}
+
+	scope := instrumentation.Scope{Name: "github.com/signalfx/splunk-otel-go/distro", Version: Version()}
+	for i := range rm.ScopeMetrics {
+		if rm.ScopeMetrics[i].Scope == scope {
+			rm.ScopeMetrics[i].Metrics = append(rm.ScopeMetrics[i].Metrics, m)
+			return
+		}
+	}
+	rm.ScopeMetrics = append(rm.ScopeMetrics, metricdata.ScopeMetrics{
+		Scope:   scope,
+		Metrics: []metricdata.Metrics{m},
+	})
+}
+
+// limitAggregation returns data with the series not admitted by l folded
+// into the overflow series, and if any series was folded.
+func limitAggregation(l *seriesLimiter, data metricdata.Aggregation) (metricdata.Aggregation, bool) {
+	var overflowed bool
+	switch d := data.(type) {
+	case metricdata.Sum[int64]:
+		d.DataPoints, overflowed = foldPoints(l, d.DataPoints, mergeSum[int64])
+		return d, overflowed
+	case metricdata.Sum[float64]:
+		d.DataPoints, overflowed = foldPoints(l, d.DataPoints, mergeSum[float64])
+		return d, overflowed
+	case metricdata.Gauge[int64]:
+		d.DataPoints, overflowed = foldPoints(l, d.DataPoints, mergeGauge[int64])
+		return d, overflowed
+	case metricdata.Gauge[float64]:
+		d.DataPoints, overflowed = foldPoints(l, d.DataPoints, mergeGauge[float64])
+		return d, overflowed
+	case metricdata.Histogram[int64]:
+		d.DataPoints, overflowed = foldHistogramPoints(l, d.DataPoints)
+		return d, overflowed
+	case metricdata.Histogram[float64]:
+		d.DataPoints, overflowed = foldHistogramPoints(l, d.DataPoints)
+		return d, overflowed
+	default:
+		// Exponential histograms are only limited by the SDK.
+		return data, false
+	}
+}
+
+func foldPoints[N int64 | float64](
+	l *seriesLimiter,
+	points []metricdata.DataPoint[N],
+	merge func(dst *metricdata.DataPoint[N], src metricdata.DataPoint[N]),
+) ([]metricdata.DataPoint[N], bool) {
+	out := make([]metricdata.DataPoint[N], 0, len(points))
+	var overflow *metricdata.DataPoint[N]
+	for _, p := range points {
+		if l.admit(p.Attributes) {
+			out = append(out, p)
+			continue
+		}
+		if overflow == nil {
+			o := metricdata.DataPoint[N]{
+				Attributes: overflowSet,
+				StartTime:  p.StartTime,
+				Time:       p.Time,
+				Value:      p.Value,
+			}
+			overflow = &o
+			continue
+		}
+		merge(overflow, p)
+	}
+	if overflow == nil {
+		return out, false
+	}
+	return append(out, *overflow), true
+}
+
+func mergeSum[N int64 | float64](dst *metricdata.DataPoint[N], src metricdata.DataPoint[N]) {
+	dst.Value += src.Value
+	if src.StartTime.Before(dst.StartTime) {
+		dst.StartTime = src.StartTime
+	}
+	if src.Time.After(dst.Time) {
+		dst.Time = src.Time
+	}
+}
+
+// mergeGauge keeps the last value.
+func mergeGauge[N int64 | float64](dst *metricdata.DataPoint[N], src metricdata.DataPoint[N]) {
+	if !src.Time.Before(dst.Time) {
+		dst.Value = src.Value
+		dst.Time = src.Time
+	}
+}
+
+func foldHistogramPoints[N int64 | float64](
+	l *seriesLimiter,
+	points []metricdata.HistogramDataPoint[N],
+) ([]metricdata.HistogramDataPoint[N], bool) {
+	out := make([]metricdata.HistogramDataPoint[N], 0, len(points))
+	var overflow *metricdata.HistogramDataPoint[N]
+	for _, p := range points {
+		if l.admit(p.Attributes) {
+			out = append(out, p)
+			continue
+		}
+		if overflow == nil {
+			o := p
+			o.Attributes = overflowSet
+			o.BucketCounts = slices.Clone(p.BucketCounts)
+			o.Exemplars = nil
+			overflow = &o
+			continue
+		}
+		mergeHistogram(overflow, p)
+	}
+	if overflow == nil {
+		return out, false
+	}
+	return append(out, *overflow), true
+}
+
+func mergeHistogram[N int64 | float64](dst *metricdata.HistogramDataPoint[N], src metricdata.HistogramDataPoint[N]) {
+	if !slices.Equal(dst.Bounds, src.Bounds) {
+		// All points of an instrument share its bounds.
+		return
+	}
+	for i, c := range src.BucketCounts {
+		dst.BucketCounts[i] += c
+	}
+	dst.Count += src.Count
+	dst.Sum += src.Sum
+	if v, ok := src.Min.Value(); ok {
+		if cur, ok := dst.Min.Value(); !ok || v < cur {
+			dst.Min = metricdata.NewExtrema(v)
+		}
+	}
+	if v, ok := src.Max.Value(); ok {
+		if cur, ok := dst.Max.Value(); !ok || v > cur {
+			dst.Max = metricdata.NewExtrema(v)
+		}
+	}
+	if src.StartTime.Before(dst.StartTime) {
+		dst.StartTime = src.StartTime
+	}
+	if src.Time.After(dst.Time) {
+		dst.Time = src.Time
+	}
+}
@@ -0,0 +1,251 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distro
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tonglil/buflogr"
+	"go.opentelemetry.io/otel/attribute"
+	otelmetric "go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestNewCardinalityLimits(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		l := newCardinalityLimits(newConfig())
+		assert.Equal(t, defaultMetricsCardinalityLimit, l.limit("pool", "pool.usage"))
+		assert.Equal(t, defaultMetricsCardinalityLimit, l.max())
+	})
+
+	t.Run("environment", func(t *testing.T) {
+		t.Setenv(metricsCardinalityLimitKey, "100")
+		t.Setenv(metricsInstrumentCardinalityLimitsKey, "pool.usage=10, http.server.duration = 500,invalid,net/http:pool.usage=20")
+		var buf bytes.Buffer
+
+		l := newCardinalityLimits(newConfig(WithLogger(buflogr.NewWithBuffer(&buf))))
+		assert.Equal(t, 100, l.limit("pool", "other"))
+		assert.Equal(t, 10, l.limit("pool", "pool.usage"))
+		assert.Equal(t, 20, l.limit("net/http", "pool.usage"))
+		assert.Equal(t, 500, l.limit("net/http", "http.server.duration"))
+		assert.Equal(t, 500, l.max())
+		assert.Contains(t, buf.String(), `"invalid"`)
+	})
+
+	t.Run("options", func(t *testing.T) {
+		t.Setenv(metricsCardinalityLimitKey, "100")
+		t.Setenv(metricsInstrumentCardinalityLimitsKey, "pool.usage=10")
+
+		l := newCardinalityLimits(newConfig(
+			WithMetricsCardinalityLimit(50),
+			WithInstrumentCardinalityLimit("", "pool.usage", 0),
+			WithInstrumentCardinalityLimit("net/http", "pool.usage", 5),
+		))
+		assert.Equal(t, 50, l.limit("pool", "other"))
+		assert.Equal(t, 0, l.limit("pool", "pool.usage"))
+		assert.Equal(t, 5, l.limit("net/http", "pool.usage"))
+		assert.Equal(t, 0, l.max(), "unlimited instrument limited by the SDK")
+	})
+}
+
+// temporalityExporter is a metric.Exporter selecting temporality for all the
+// instruments.
+type temporalityExporter struct {
+	metric.Exporter
+
+	temporality metricdata.Temporality
+}
+
+func (e temporalityExporter) Temporality(metric.InstrumentKind) metricdata.Temporality {
+	return e.temporality
+}
+
+// sumValues returns the values by encoded attributes of the sum.
+func sumValues(t *testing.T, data metricdata.Aggregation) map[string]int64 {
+	t.Helper()
+	sum, ok := data.(metricdata.Sum[int64])
+	require.True(t, ok)
+	values := make(map[string]int64)
+	for _, dp := range sum.DataPoints {
+		values[dp.Attributes.Encoded(attribute.DefaultEncoder())] = dp.Value
+	}
+	return values
+}
+
+func TestCardinalityLimitExporter(t *testing.T) {
+	reader := metric.NewManualReader()
+	mp := metric.NewMeterProvider(metric.WithReader(reader))
+	t.Cleanup(func() { require.NoError(t, mp.Shutdown(context.Background())) })
+	meter := mp.Meter("test")
+
+	counter, err := meter.Int64Counter("pool.usage")
+	require.NoError(t, err)
+	hist, err := meter.Float64Histogram("latency", otelmetric.WithExplicitBucketBoundaries(1, 10))
+	require.NoError(t, err)
+	other, err := meter.Int64Counter("other")
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	for i := range 5 {
+		attrs := otelmetric.WithAttributes(attribute.String("pool.name", strconv.Itoa(i)))
+		counter.Add(ctx, int64(i+1), attrs)
+		hist.Record(ctx, float64(i*5), attrs)
+		other.Add(ctx, 1, attrs)
+	}
+
+	exp := newCardinalityLimitExporter(temporalityExporter{temporality: metricdata.CumulativeTemporality}, cardinalityLimits{
+		global:      3,
+		instruments: map[instrumentKey]int{{name: "other"}: 0},
+	})
+	collect := func() map[string]metricdata.Aggregation {
+		var rm metricdata.ResourceMetrics
+		require.NoError(t, reader.Collect(ctx, &rm))
+		got := make(map[string]metricdata.Aggregation)
+		for _, sm := range exp.limit(&rm).ScopeMetrics {
+			for _, m := range sm.Metrics {
+				got[m.Name] = m.Data
+			}
+		}
+		return got
+	}
+
+	const overflowKey = "otel.metric.overflow=true"
+
+	got := collect()
+
+	values := sumValues(t, got["pool.usage"])
+	require.Len(t, values, 3, "limit not applied")
+	require.Contains(t, values, overflowKey)
+	var total int64
+	for _, v := range values {
+		total += v
+	}
+	assert.Equal(t, int64(1+2+3+4+5), total, "measurements lost")
+
+	h, ok := got["latency"].(metricdata.Histogram[float64])
+	require.True(t, ok)
+	require.Len(t, h.DataPoints, 3)
+	overflow := h.DataPoints[2]
+	assert.Equal(t, overflowSet, overflow.Attributes)
+	assert.Equal(t, uint64(3), overflow.Count)
+	var (
+		sum     float64
+		buckets = make([]uint64, 3)
+	)
+	for _, dp := range h.DataPoints {
+		sum += dp.Sum
+		for i, c := range dp.BucketCounts {
+			buckets[i] += c
+		}
+	}
+	assert.Equal(t, 0.0+5+10+15+20, sum)
+	assert.Equal(t, []uint64{1, 2, 2}, buckets)
+
+	assert.Len(t, sumValues(t, got["other"]), 5, "unlimited instrument limited")
+
+	overflows := sumValues(t, got[overflowMetricName])
+	assert.Equal(t, map[string]int64{
+		"metric.name=pool.usage,otel.scope.name=test": 1,
+		"metric.name=latency,otel.scope.name=test":    1,
+	}, overflows)
+
+	// Admitted series stay admitted in following collections.
+	for i := range 5 {
+		counter.Add(ctx, 1, otelmetric.WithAttributes(attribute.String("pool.name", strconv.Itoa(i))))
+	}
+	got = collect()
+	next := sumValues(t, got["pool.usage"])
+	for k, v := range values {
+		assert.Contains(t, next, k)
+		if k != overflowKey {
+			assert.Equal(t, v+1, next[k])
+		}
+	}
+	assert.Equal(t, map[string]int64{
+		"metric.name=pool.usage,otel.scope.name=test": 2,
+		"metric.name=latency,otel.scope.name=test":    2,
+	}, sumValues(t, got[overflowMetricName]))
+}
+
+func TestCardinalityLimitExporterDelta(t *testing.T) {
+	delta := func(metric.InstrumentKind) metricdata.Temporality { return metricdata.DeltaTemporality }
+	reader := metric.NewManualReader(metric.WithTemporalitySelector(delta))
+	mp := metric.NewMeterProvider(metric.WithReader(reader))
+	t.Cleanup(func() { require.NoError(t, mp.Shutdown(context.Background())) })
+
+	counter, err := mp.Meter("test").Int64Counter("pool.usage")
+	require.NoError(t, err)
+	// The same instrument name in another scope has its own limit.
+	unlimited, err := mp.Meter("unlimited").Int64Counter("pool.usage")
+	require.NoError(t, err)
+
+	exp := newCardinalityLimitExporter(temporalityExporter{temporality: metricdata.DeltaTemporality}, cardinalityLimits{
+		global:      3,
+		instruments: map[instrumentKey]int{{scope: "unlimited", name: "pool.usage"}: 0},
+	})
+	ctx := context.Background()
+	collect := func() map[string]map[string]metricdata.Aggregation {
+		var rm metricdata.ResourceMetrics
+		require.NoError(t, reader.Collect(ctx, &rm))
+		got := make(map[string]map[string]metricdata.Aggregation)
+		for _, sm := range exp.limit(&rm).ScopeMetrics {
+			got[sm.Scope.Name] = make(map[string]metricdata.Aggregation)
+			for _, m := range sm.Metrics {
+				got[sm.Scope.Name][m.Name] = m.Data
+			}
+		}
+		return got
+	}
+	record := func(from, to int) {
+		for i := from; i < to; i++ {
+			attrs := otelmetric.WithAttributes(attribute.String("pool.name", strconv.Itoa(i)))
+			counter.Add(ctx, 1, attrs)
+			unlimited.Add(ctx, 1, attrs)
+		}
+	}
+	const overflowKey = "otel.metric.overflow=true"
+
+	record(0, 5)
+	got := collect()
+	assert.Len(t, sumValues(t, got["test"]["pool.usage"]), 3, "limit not applied")
+	assert.Contains(t, sumValues(t, got["test"]["pool.usage"]), overflowKey)
+	assert.Len(t, sumValues(t, got["unlimited"]["pool.usage"]), 5, "limit of another scope applied")
+
+	distro := got["github.com/signalfx/splunk-otel-go/distro"][overflowMetricName]
+	sum, ok := distro.(metricdata.Sum[int64])
+	require.True(t, ok, "overflows not reported")
+	assert.Equal(t, metricdata.DeltaTemporality, sum.Temporality)
+	assert.Equal(t, map[string]int64{"metric.name=pool.usage,otel.scope.name=test": 1}, sumValues(t, sum))
+
+	// The series admitted are reset every delta collection.
+	record(3, 5)
+	got = collect()
+	values := sumValues(t, got["test"]["pool.usage"])
+	assert.Len(t, values, 2)
+	assert.NotContains(t, values, overflowKey, "new delta series folded")
+	assert.NotContains(t, got, "github.com/signalfx/splunk-otel-go/distro", "overflows reported again")
+}
+
+func TestSeriesLimiterSDKOverflow(t *testing.T) {
+	l := &seriesLimiter{limit: 0, admitted: make(map[attribute.Distinct]struct{})}
+	assert.True(t, l.admit(attribute.NewSet(attribute.String("key", "value"))))
+	assert.False(t, l.admit(overflowSet), "SDK overflow series not folded")
+}
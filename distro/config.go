@@ -16,19 +16,34 @@ package distro
 
 import (
 	"crypto/tls"
+	"net/http"
+	"net/url"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
 	"go.opentelemetry.io/contrib/propagators/autoprop"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/grpc"
 )
 
 // Environment variable keys that set values of the configuration.
 const (
+	// Disable the SDK for all signals.
+	otelSDKDisabledKey = "OTEL_SDK_DISABLED"
+
+	// Disable the Go runtime metrics instrumentation.
+	splunkRuntimeMetricsEnabledKey = "SPLUNK_RUNTIME_METRICS_ENABLED"
+
 	// Access token added to exported data.
 	accessTokenKey = "SPLUNK_ACCESS_TOKEN"
 
+	// File containing the access token added to exported data. The file is
+	// re-read when it changes.
+	accessTokenFileKey = "SPLUNK_ACCESS_TOKEN_FILE"
+
 	// OpenTelemetry TextMapPropagator to set as global.
 	otelPropagatorsKey = "OTEL_PROPAGATORS"
 
@@ -39,6 +54,7 @@ const (
 
 	// OpenTelemetry exporter endpoints.
 	otelExporterJaegerEndpointKey      = "OTEL_EXPORTER_JAEGER_ENDPOINT"
+	otelExporterZipkinEndpointKey      = "OTEL_EXPORTER_ZIPKIN_ENDPOINT"
 	otelExporterOTLPEndpointKey        = "OTEL_EXPORTER_OTLP_ENDPOINT"
 	otelExporterOTLPTracesEndpointKey  = "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"
 	otelExporterOTLPMetricsEndpointKey = "OTEL_EXPORTER_OTLP_METRICS_ENDPOINT"
@@ -50,11 +66,24 @@ const (
 	otelMetricsExporterOTLPProtocolKey = "OTEL_EXPORTER_OTLP_METRICS_PROTOCOL"
 	otelLogsExporterOTLPProtocolKey    = "OTEL_EXPORTER_OTLP_LOGS_PROTOCOL"
 
+	// File exporter configuration.
+	fileExporterTracesPathKey       = "SPLUNK_FILE_EXPORTER_TRACES_PATH"
+	fileExporterMetricsPathKey      = "SPLUNK_FILE_EXPORTER_METRICS_PATH"
+	fileExporterLogsPathKey         = "SPLUNK_FILE_EXPORTER_LOGS_PATH"
+	fileExporterMaxMegabytesKey     = "SPLUNK_FILE_EXPORTER_MAX_MEGABYTES"
+	fileExporterRotationIntervalKey = "SPLUNK_FILE_EXPORTER_ROTATION_INTERVAL"
+	fileExporterMaxBackupsKey       = "SPLUNK_FILE_EXPORTER_MAX_BACKUPS"
+	fileExporterCompressionKey      = "SPLUNK_FILE_EXPORTER_COMPRESSION"
+
 	// Logging level to set when using the default logger.
 	otelLogLevelKey = "OTEL_LOG_LEVEL"
 
 	// splunkRealmKey defines the Splunk realm to build an endpoint from.
 	splunkRealmKey = "SPLUNK_REALM"
+
+	// diagnosticsEndpointKey is the address the diagnostics HTTP endpoint
+	// listens on. The endpoint is disabled if not set.
+	diagnosticsEndpointKey = "SPLUNK_DIAGNOSTICS_ENDPOINT"
 )
 
 // Default configuration values.
@@ -63,6 +92,10 @@ const (
 
 	otlpValue = "otlp"
 
+	fileValue = "file"
+
+	zipkinValue = "zipkin"
+
 	logLevelDebug = "debug"
 	logLevelInfo  = "info"
 	logLevelWarn  = "warn"
@@ -75,6 +108,12 @@ const (
 	defaultLogLevel        = logLevelInfo
 	defaultOTLPProtocol    = otlpProtocolGRPC
 
+	defaultFileTracesPath   = "traces.jsonl"
+	defaultFileMetricsPath  = "metrics.jsonl"
+	defaultFileLogsPath     = "logs.jsonl"
+	defaultFileMaxMegabytes = 100
+	defaultFileMaxBackups   = 10
+
 	jaegerDefaultEndpoint     = "http://127.0.0.1:9080/v1/trace"
 	jaegerRealmEndpointFormat = "https://ingest.%s.observability.splunkcloud.com/v2/trace"
 
@@ -87,11 +126,46 @@ const (
 const (
 	otlpProtocolGRPC         = "grpc"
 	otlpProtocolHTTPProtobuf = "http/protobuf"
+	otlpProtocolHTTPJSON     = "http/json"
 )
 
 type exporterConfig struct {
 	accessToken string
 	TLSConfig   *tls.Config
+
+	// TokenProvider provides the access token for each export request. If
+	// nil, the static accessToken is used.
+	TokenProvider TokenProvider
+	// tokenSource is where TokenProvider was configured.
+	tokenSource ConfigSource
+
+	// HTTPClient, HTTPTransport, and Proxy customize the HTTP client of the
+	// exporters using an HTTP protocol.
+	HTTPClient    *http.Client
+	HTTPTransport http.RoundTripper
+	Proxy         func(*http.Request) (*url.URL, error)
+	// DialOptions are added to the dial options of the gRPC exporters.
+	DialOptions []grpc.DialOption
+	// Timeouts and Compressions are the export request timeout and
+	// compression by signal set with options.
+	Timeouts     map[Signal]time.Duration
+	Compressions map[Signal]Compression
+	// MaxRequestSize is the maximum size of the requests sent to Splunk
+	// Observability Cloud ingest. If zero, it is configured with an
+	// environment variable.
+	MaxRequestSize int
+
+	// resolved is the configuration the exporters resolved when they were
+	// created, by signal.
+	resolved map[Signal]SignalConfig
+}
+
+// record records sc as the configuration resolved by the exporter of s.
+func (c *exporterConfig) record(s Signal, sc SignalConfig) {
+	if c.resolved == nil {
+		c.resolved = make(map[Signal]SignalConfig)
+	}
+	c.resolved[s] = sc
 }
 
 // config is the configuration used to create and operate an SDK.
@@ -101,10 +175,44 @@ type config struct {
 	SpanLimits  *trace.SpanLimits
 	IDGenerator trace.IDGenerator
 
+	RuntimeMetricsEnabled bool
+	DiagnosticsEndpoint   string
+	// REDMetrics configures the metrics derived from spans. If nil, they are
+	// configured with environment variables.
+	REDMetrics *REDMetricsConfig
+	// BaggageAttributes configures the baggage members copied onto spans and
+	// log records. If nil, they are configured with environment variables.
+	BaggageAttributes *BaggageAttributesConfig
+	// ContextAttributes are the functions extracting the attributes added to
+	// spans and log records from their context.
+	ContextAttributes []ContextAttributesFunc
+	// MetricsCardinalityLimit is the cardinality limit of all instruments. If
+	// nil, it is configured with an environment variable.
+	MetricsCardinalityLimit *int
+	// InstrumentCardinalityLimits are the cardinality limits by instrument
+	// scope and name, added to the ones configured with an environment
+	// variable.
+	InstrumentCardinalityLimits map[instrumentKey]int
+
+	// Sampler is the sampler of the traces, configured with environment
+	// variables. samplerSource is where it is configured.
+	Sampler       trace.Sampler
+	samplerSource ConfigSource
+
 	ExportConfig        *exporterConfig
 	TracesExporterFunc  traceExporterFunc
 	MetricsExporterFunc metricsExporterFunc
 	LogsExporterFunc    logsExporterFunc
+	// Exporters are the names of the exporters of each signal.
+	Exporters map[Signal]string
+
+	// diagnostics is the state served by the diagnostics endpoint. It is nil
+	// if the endpoint is disabled.
+	diagnostics *diagnostics
+
+	// optionErrs are the errors of the invalid options ignored. They are
+	// logged once all the options are applied.
+	optionErrs []error
 }
 
 // newConfig returns a validated config with Splunk defaults.
@@ -113,16 +221,29 @@ func newConfig(opts ...Option) *config {
 		Logger:     logger(zapConfig(envOr(otelLogLevelKey, defaultLogLevel))),
 		Propagator: autoprop.NewTextMapPropagator(),
 		SpanLimits: newSpanLimits(),
+
+		RuntimeMetricsEnabled: envEnabled(splunkRuntimeMetricsEnabledKey),
+		DiagnosticsEndpoint:   os.Getenv(diagnosticsEndpointKey),
+
 		ExportConfig: &exporterConfig{
 			accessToken: envOr(accessTokenKey, defaultAccessToken),
 		},
 	}
+	if path := os.Getenv(accessTokenFileKey); path != "" {
+		c.ExportConfig.TokenProvider = newFileTokenProvider(path)
+		c.ExportConfig.tokenSource = SourceEnv
+	}
 	for _, o := range opts {
 		o.apply(c)
 	}
-	c.TracesExporterFunc = tracesExporter(c.Logger)
-	c.MetricsExporterFunc = metricsExporter(c.Logger)
-	c.LogsExporterFunc = logsExporter(c.Logger)
+	for _, err := range c.optionErrs {
+		c.Logger.Error(err, "ignoring invalid option")
+	}
+	c.Sampler, c.samplerSource = newSampler(c.Logger)
+	c.Exporters = make(map[Signal]string)
+	c.Exporters[SignalTraces], c.TracesExporterFunc = tracesExporter(c.Logger)
+	c.Exporters[SignalMetrics], c.MetricsExporterFunc = metricsExporter(c.Logger)
+	c.Exporters[SignalLogs], c.LogsExporterFunc = logsExporter(c.Logger)
 	return c
 }
 
@@ -135,6 +256,18 @@ func envOr(key, alt string) string {
 	return alt
 }
 
+// envEnabled returns false if the environment variable value associated with
+// key is "false" (case-insensitive), otherwise it returns true.
+func envEnabled(key string) bool {
+	return !strings.EqualFold(os.Getenv(key), "false")
+}
+
+// sdkDisabled returns true if OTEL_SDK_DISABLED is set to "true"
+// (case-insensitive).
+func sdkDisabled() bool {
+	return strings.EqualFold(os.Getenv(otelSDKDisabledKey), "true")
+}
+
 // Option sets a config setting value.
 type Option interface {
 	apply(*config)
@@ -157,6 +290,33 @@ func WithTLSConfig(conf *tls.Config) Option {
 	})
 }
 
+// WithAccessTokenFile configures the exporters to send the access token
+// stored in the file at path. The file is re-read whenever it changes, so
+// rotated tokens are used for subsequent exports.
+//
+// If this option is not provided, the SPLUNK_ACCESS_TOKEN_FILE environment
+// variable is used, if set. Otherwise, the static SPLUNK_ACCESS_TOKEN value is
+// used.
+func WithAccessTokenFile(path string) Option {
+	return optionFunc(func(c *config) {
+		c.ExportConfig.TokenProvider = newFileTokenProvider(path)
+		c.ExportConfig.tokenSource = SourceOption
+	})
+}
+
+// WithAccessTokenProvider configures the exporters to send the access token
+// returned by p with every export request. An error returned by p fails the
+// export and is reported to the OpenTelemetry error handler.
+//
+// This option takes precedence over the SPLUNK_ACCESS_TOKEN and
+// SPLUNK_ACCESS_TOKEN_FILE environment variables.
+func WithAccessTokenProvider(p TokenProvider) Option {
+	return optionFunc(func(c *config) {
+		c.ExportConfig.TokenProvider = p
+		c.ExportConfig.tokenSource = SourceOption
+	})
+}
+
 // WithLogger configures the logger used by this distro.
 //
 // The logr.Logger provided should be configured with a verbosity enabled to
@@ -181,3 +341,17 @@ func WithIDGenerator(g trace.IDGenerator) Option {
 		c.IDGenerator = g
 	})
 }
+
+// WithDiagnosticsEndpoint configures the distro to serve its state on a local
+// HTTP endpoint listening on addr, e.g. "localhost:55679". The endpoint shows
+// the resolved configuration, the resource, recent spans, errors, and the
+// health of the exporters. It is meant to debug instrumentation where no
+// backend is available and should not be exposed publicly.
+//
+// This option takes precedence over the SPLUNK_DIAGNOSTICS_ENDPOINT
+// environment variable. If neither are set, the endpoint is not started.
+func WithDiagnosticsEndpoint(addr string) Option {
+	return optionFunc(func(c *config) {
+		c.DiagnosticsEndpoint = addr
+	})
+}
@@ -44,6 +44,16 @@ var configurationTests = []*configFieldTest{
 			{Key: accessTokenKey, Value: "secret"},
 		},
 	},
+	{
+		Name: "RuntimeMetricsEnabled",
+		ValueFunc: func(c *config) interface{} {
+			return c.RuntimeMetricsEnabled
+		},
+		DefaultValue: true,
+		EnvironmentTests: []keyValue{
+			{Key: splunkRuntimeMetricsEnabledKey, Value: "False"},
+		},
+	},
 	{
 		Name: "Propagator",
 		ValueFunc: func(c *config) interface{} {
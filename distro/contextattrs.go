@@ -0,0 +1,91 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distro
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+// ContextAttributesFunc returns the attributes to add to a span or log record
+// created with ctx.
+type ContextAttributesFunc func(ctx context.Context) []attribute.KeyValue
+
+// WithContextAttributes configures the distro to add the attributes returned
+// by fn to every span when it starts and every log record when it is
+// emitted. fn is passed the context the span is started or the record is
+// emitted with, so values the application stores in it (e.g. a request or
+// tenant ID) are recorded without adding them in each handler.
+//
+// fn is called for every span and log record, it needs to be fast and safe to
+// call concurrently. This option can be used multiple times, the attributes of
+// all functions are added in the order they were passed.
+func WithContextAttributes(fn ContextAttributesFunc) Option {
+	return optionFunc(func(c *config) {
+		if fn != nil {
+			c.ContextAttributes = append(c.ContextAttributes, fn)
+		}
+	})
+}
+
+// contextAttributesSpanProcessor adds the attributes extracted from the
+// context to spans when they start.
+type contextAttributesSpanProcessor struct {
+	funcs []ContextAttributesFunc
+}
+
+var _ trace.SpanProcessor = contextAttributesSpanProcessor{}
+
+func (p contextAttributesSpanProcessor) OnStart(ctx context.Context, s trace.ReadWriteSpan) {
+	for _, fn := range p.funcs {
+		if attrs := fn(ctx); len(attrs) > 0 {
+			s.SetAttributes(attrs...)
+		}
+	}
+}
+
+func (contextAttributesSpanProcessor) OnEnd(trace.ReadOnlySpan)         {}
+func (contextAttributesSpanProcessor) Shutdown(context.Context) error   { return nil }
+func (contextAttributesSpanProcessor) ForceFlush(context.Context) error { return nil }
+
+// contextAttributesLogProcessor adds the attributes extracted from the
+// context to log records when they are emitted. It needs to be registered
+// before the processors exporting the records.
+type contextAttributesLogProcessor struct {
+	funcs []ContextAttributesFunc
+}
+
+var _ log.Processor = contextAttributesLogProcessor{}
+
+// Enabled returns false, the processor does not need any record to be
+// emitted.
+func (contextAttributesLogProcessor) Enabled(context.Context, log.EnabledParameters) bool {
+	return false
+}
+
+func (p contextAttributesLogProcessor) OnEmit(ctx context.Context, r *log.Record) error {
+	for _, fn := range p.funcs {
+		if attrs := fn(ctx); len(attrs) > 0 {
+			r.AddAttributes(attrs...)
+		}
+	}
+	return nil
+}
+
+func (contextAttributesLogProcessor) Shutdown(context.Context) error   { return nil }
+func (contextAttributesLogProcessor) ForceFlush(context.Context) error { return nil }
@@ -0,0 +1,102 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distro
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	otellog "go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+type tenantKey struct{}
+
+func tenantAttributes(ctx context.Context) []attribute.KeyValue {
+	if v, ok := ctx.Value(tenantKey{}).(string); ok {
+		return []attribute.KeyValue{attribute.String("tenant.id", v)}
+	}
+	return nil
+}
+
+func requestAttributes(context.Context) []attribute.KeyValue {
+	return []attribute.KeyValue{attribute.String("request.id", "42")}
+}
+
+func TestWithContextAttributes(t *testing.T) {
+	c := newConfig(
+		WithContextAttributes(tenantAttributes),
+		WithContextAttributes(nil),
+		WithContextAttributes(requestAttributes),
+	)
+	assert.Len(t, c.ContextAttributes, 2)
+}
+
+func TestContextAttributesSpanProcessor(t *testing.T) {
+	exp := tracetest.NewInMemoryExporter()
+	tp := trace.NewTracerProvider(
+		trace.WithSpanProcessor(contextAttributesSpanProcessor{
+			funcs: []ContextAttributesFunc{tenantAttributes, requestAttributes},
+		}),
+		trace.WithSyncer(exp),
+	)
+	t.Cleanup(func() { require.NoError(t, tp.Shutdown(context.Background())) })
+
+	tracer := tp.Tracer("test")
+	_, span := tracer.Start(context.WithValue(context.Background(), tenantKey{}, "acme"), "tenant")
+	span.End()
+	_, span = tracer.Start(context.Background(), "anonymous")
+	span.End()
+
+	spans := exp.GetSpans()
+	require.Len(t, spans, 2)
+	assert.Equal(t, []attribute.KeyValue{
+		attribute.String("tenant.id", "acme"),
+		attribute.String("request.id", "42"),
+	}, spans[0].Attributes)
+	assert.Equal(t, []attribute.KeyValue{
+		attribute.String("request.id", "42"),
+	}, spans[1].Attributes)
+}
+
+func TestContextAttributesLogProcessor(t *testing.T) {
+	rec := &recordsProcessor{}
+	lp := log.NewLoggerProvider(
+		log.WithProcessor(contextAttributesLogProcessor{
+			funcs: []ContextAttributesFunc{tenantAttributes, requestAttributes},
+		}),
+		log.WithProcessor(rec),
+	)
+	t.Cleanup(func() { require.NoError(t, lp.Shutdown(context.Background())) })
+
+	ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+	lp.Logger("test").Emit(ctx, otellog.Record{})
+
+	require.Len(t, rec.records, 1)
+	var got []attribute.KeyValue
+	rec.records[0].WalkAttributes(func(kv attribute.KeyValue) bool {
+		got = append(got, kv)
+		return true
+	})
+	assert.Equal(t, []attribute.KeyValue{
+		attribute.String("tenant.id", "acme"),
+		attribute.String("request.id", "42"),
+	}, got)
+}
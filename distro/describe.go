@@ -0,0 +1,274 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distro
+
+import (
+	"fmt"
+	"maps"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+)
+
+// redacted replaces secret values in an EffectiveConfig.
+const redacted = "REDACTED"
+
+// Default endpoints of the exporters when nothing is configured.
+const (
+	otlpGRPCDefaultEndpoint = "localhost:4317"
+	otlpHTTPDefaultEndpoint = "localhost:4318"
+	zipkinDefaultEndpoint   = "http://localhost:9411/api/v2/spans"
+)
+
+// ConfigSource is the origin of a configuration value.
+type ConfigSource string
+
+// Configuration value origins.
+const (
+	// SourceDefault is used for values that are the default of the distro.
+	SourceDefault ConfigSource = "default"
+	// SourceEnv is used for values resolved from environment variables.
+	SourceEnv ConfigSource = "env"
+	// SourceOption is used for values set with an Option passed to Run.
+	SourceOption ConfigSource = "option"
+)
+
+// ConfigValue is a resolved configuration value.
+type ConfigValue struct {
+	Value  string       `json:"value"`
+	Source ConfigSource `json:"source"`
+}
+
+// String returns the value followed by its source in parentheses.
+func (v ConfigValue) String() string {
+	if v.Source == "" {
+		return v.Value
+	}
+	return fmt.Sprintf("%s (%s)", v.Value, v.Source)
+}
+
+// SignalConfig is the resolved exporter configuration of a signal.
+//
+// Fields not applicable to the exporter in use are empty.
+type SignalConfig struct {
+	// Exporter is the exporter used: "otlp", "none", etc.
+	Exporter ConfigValue `json:"exporter,omitzero"`
+	// Endpoint is the URL, or file path, telemetry is exported to.
+	Endpoint ConfigValue `json:"endpoint,omitzero"`
+	// Protocol is the OTLP protocol used.
+	Protocol ConfigValue `json:"protocol,omitzero"`
+	// TLS is the transport security used: "insecure", "system" (the system
+	// certificate pool), or "custom" (the WithTLSConfig, WithHTTPClient, or
+	// WithHTTPTransport options, or the OTLP certificate environment
+	// variables).
+	TLS ConfigValue `json:"tls,omitzero"`
+	// AccessToken is "REDACTED" if an access token is sent.
+	AccessToken ConfigValue `json:"access_token,omitzero"`
+	// Headers are the headers sent with the OTLP export requests, with
+	// their values redacted.
+	Headers ConfigValue `json:"headers,omitzero"`
+	// Timeout is the maximum duration of an OTLP export request.
+	Timeout ConfigValue `json:"timeout,omitzero"`
+	// Compression is the compression of the OTLP export requests.
+	Compression ConfigValue `json:"compression,omitzero"`
+}
+
+// EffectiveConfig is the configuration resolved by Run. Secrets are
+// redacted.
+type EffectiveConfig struct {
+	Propagators    ConfigValue `json:"propagators"`
+	Sampler        ConfigValue `json:"sampler"`
+	RuntimeMetrics ConfigValue `json:"runtime_metrics"`
+
+	Traces  SignalConfig `json:"traces"`
+	Metrics SignalConfig `json:"metrics"`
+	Logs    SignalConfig `json:"logs"`
+}
+
+// keysAndValues returns c as flattened key-value pairs to be logged.
+func (c EffectiveConfig) keysAndValues() []any {
+	kv := []any{
+		"propagators", c.Propagators.String(),
+		"sampler", c.Sampler.String(),
+		"runtime_metrics", c.RuntimeMetrics.String(),
+	}
+	for _, s := range []struct {
+		name string
+		conf SignalConfig
+	}{
+		{"traces", c.Traces},
+		{"metrics", c.Metrics},
+		{"logs", c.Logs},
+	} {
+		for _, f := range []struct {
+			name string
+			val  ConfigValue
+		}{
+			{"exporter", s.conf.Exporter},
+			{"endpoint", s.conf.Endpoint},
+			{"protocol", s.conf.Protocol},
+			{"tls", s.conf.TLS},
+			{"access_token", s.conf.AccessToken},
+			{"headers", s.conf.Headers},
+			{"timeout", s.conf.Timeout},
+			{"compression", s.conf.Compression},
+		} {
+			if f.val.Value != "" {
+				kv = append(kv, s.name+"."+f.name, f.val.String())
+			}
+		}
+	}
+	return kv
+}
+
+// Config returns the configuration resolved when s was created by Run.
+//
+// It is the zero value if the SDK is disabled.
+func (s SDK) Config() EffectiveConfig {
+	return s.config
+}
+
+// signalKeys are the configuration keys and defaults of a signal.
+type signalKeys struct {
+	signal      Signal
+	exporterKey string
+	endpointKey string
+	protocolKey string
+	timeoutKey  string
+	// urlPath is the default OTLP HTTP URL path.
+	urlPath string
+	// realmEndpoint returns the SPLUNK_REALM OTLP endpoint, if supported.
+	realmEndpoint func() string
+	realmPath     string
+}
+
+var (
+	tracesKeys = signalKeys{
+		signal:        SignalTraces,
+		exporterKey:   otelTracesExporterKey,
+		endpointKey:   otelExporterOTLPTracesEndpointKey,
+		protocolKey:   otelTracesExporterOTLPProtocolKey,
+		timeoutKey:    otelExporterOTLPTracesTimeoutKey,
+		urlPath:       "/v1/traces",
+		realmEndpoint: otlpRealmTracesEndpoint,
+		realmPath:     otlpRealmTracesEndpointPath,
+	}
+	metricsKeys = signalKeys{
+		signal:        SignalMetrics,
+		exporterKey:   otelMetricsExporterKey,
+		endpointKey:   otelExporterOTLPMetricsEndpointKey,
+		protocolKey:   otelMetricsExporterOTLPProtocolKey,
+		timeoutKey:    otelExporterOTLPMetricsTimeoutKey,
+		urlPath:       "/v1/metrics",
+		realmEndpoint: otlpRealmMetricsEndpoint,
+		realmPath:     otlpRealmMetricsEndpointPath,
+	}
+	logsKeys = signalKeys{
+		signal:      SignalLogs,
+		exporterKey: otelLogsExporterKey,
+		endpointKey: otelExporterOTLPLogsEndpointKey,
+		protocolKey: otelLogsExporterOTLPProtocolKey,
+		timeoutKey:  otelExporterOTLPLogsTimeoutKey,
+		urlPath:     "/v1/logs",
+	}
+)
+
+// describe returns the effective configuration of c. The exporters of c
+// record their configuration when they are created, they need to be created
+// first.
+func describe(c *config) EffectiveConfig {
+	ec := EffectiveConfig{
+		Propagators:    envValue(otelPropagatorsKey, "tracecontext,baggage"),
+		Sampler:        ConfigValue{Value: c.Sampler.Description(), Source: c.samplerSource},
+		RuntimeMetrics: ConfigValue{Value: fmt.Sprint(c.RuntimeMetricsEnabled), Source: SourceDefault},
+	}
+	if _, ok := os.LookupEnv(splunkRuntimeMetricsEnabledKey); ok {
+		ec.RuntimeMetrics.Source = SourceEnv
+	}
+
+	ec.Traces = describeSignal(c, tracesKeys, c.TracesExporterFunc == nil)
+	ec.Metrics = describeSignal(c, metricsKeys, c.MetricsExporterFunc == nil)
+	ec.Logs = describeSignal(c, logsKeys, c.LogsExporterFunc == nil)
+	return ec
+}
+
+// describeSignal returns the configuration the exporter of the signal of k
+// recorded.
+func describeSignal(c *config, k signalKeys, disabled bool) SignalConfig {
+	exporter := ConfigValue{Value: c.Exporters[k.signal], Source: SourceDefault}
+	if strings.EqualFold(os.Getenv(k.exporterKey), exporter.Value) {
+		exporter.Source = SourceEnv
+	}
+	if disabled {
+		return SignalConfig{Exporter: ConfigValue{Value: noneValue, Source: exporter.Source}}
+	}
+
+	sc := c.ExportConfig.resolved[k.signal]
+	sc.Exporter = exporter
+	return sc
+}
+
+// endpointTLS returns the transport security used to connect to endpoint.
+func endpointTLS(c *exporterConfig, endpoint ConfigValue) ConfigValue {
+	switch {
+	case c.TLSConfig != nil:
+		return ConfigValue{Value: "custom", Source: SourceOption}
+	case strings.HasPrefix(endpoint.Value, "http://"):
+		return ConfigValue{Value: "insecure", Source: endpoint.Source}
+	default:
+		return ConfigValue{Value: "system", Source: endpoint.Source}
+	}
+}
+
+// redactURL returns rawURL with its user information redacted.
+func redactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.User == nil {
+		return rawURL
+	}
+	u.User = url.User(redacted)
+	return u.String()
+}
+
+// redactHeaders returns the keys of headers, sorted, with their values
+// redacted.
+func redactHeaders(headers map[string]string) string {
+	pairs := make([]string, 0, len(headers))
+	for _, k := range slices.Sorted(maps.Keys(headers)) {
+		pairs = append(pairs, k+"="+redacted)
+	}
+	return strings.Join(pairs, ",")
+}
+
+func describeToken(c *exporterConfig) ConfigValue {
+	switch {
+	case c.TokenProvider != nil:
+		return ConfigValue{Value: redacted, Source: c.tokenSource}
+	case c.accessToken != "":
+		return ConfigValue{Value: redacted, Source: SourceEnv}
+	default:
+		return ConfigValue{}
+	}
+}
+
+// envValue returns the value of the environment variable key if it is set
+// and not empty, otherwise it returns alt as the default.
+func envValue(key, alt string) ConfigValue {
+	if v := os.Getenv(key); v != "" {
+		return ConfigValue{Value: v, Source: SourceEnv}
+	}
+	return ConfigValue{Value: alt, Source: SourceDefault}
+}
@@ -0,0 +1,517 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distro
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+const (
+	// maxDiagnosticsErrors is the number of errors and error spans retained.
+	maxDiagnosticsErrors = 20
+	// maxDiagnosticsSpanNames is the number of span names statistics are
+	// kept for. Spans with other names are only counted as dropped.
+	maxDiagnosticsSpanNames = 1000
+	// maxDiagnosticsSamples is the number of sample spans retained per
+	// latency bucket of a span name.
+	maxDiagnosticsSamples = 5
+	// diagnosticsReadHeaderTimeout bounds the time to read request headers.
+	diagnosticsReadHeaderTimeout = 5 * time.Second
+)
+
+// latencyBounds are the upper bounds of the span latency buckets.
+var latencyBounds = []time.Duration{
+	10 * time.Microsecond,
+	100 * time.Microsecond,
+	time.Millisecond,
+	10 * time.Millisecond,
+	100 * time.Millisecond,
+	time.Second,
+	10 * time.Second,
+	100 * time.Second,
+}
+
+// Default queue and batch sizes of the batch processors, used to estimate the
+// items they drop.
+const (
+	defaultBSPMaxQueueSize        = 2048
+	defaultBSPMaxExportBatchSize  = 512
+	defaultBLRPMaxQueueSize       = 2048
+	defaultBLRPMaxExportBatchSize = 512
+)
+
+// diagnostics is the SDK state served by the diagnostics endpoint.
+type diagnostics struct {
+	config   EffectiveConfig
+	resource *resource.Resource
+
+	spans  *spanzProcessor
+	logs   *logzProcessor
+	errors ring[errorEntry]
+
+	traces, metrics, logsHealth exporterHealth
+}
+
+func newDiagnostics() *diagnostics {
+	d := &diagnostics{errors: newRing[errorEntry](maxDiagnosticsErrors)}
+	// The batch processors hold up to a queue and a batch of items.
+	d.traces.queueSize = int64(envInt(logr.Discard(), "OTEL_BSP_MAX_QUEUE_SIZE", defaultBSPMaxQueueSize) +
+		envInt(logr.Discard(), "OTEL_BSP_MAX_EXPORT_BATCH_SIZE", defaultBSPMaxExportBatchSize))
+	d.logsHealth.queueSize = int64(envInt(logr.Discard(), "OTEL_BLRP_MAX_QUEUE_SIZE", defaultBLRPMaxQueueSize) +
+		envInt(logr.Discard(), "OTEL_BLRP_MAX_EXPORT_BATCH_SIZE", defaultBLRPMaxExportBatchSize))
+	d.spans = newSpanzProcessor(&d.traces)
+	d.logs = &logzProcessor{h: &d.logsHealth}
+	return d
+}
+
+// handleError records err as the last error of the SDK.
+func (d *diagnostics) handleError(err error) {
+	d.errors.add(errorEntry{Time: time.Now(), Error: err.Error()})
+}
+
+// start serves the diagnostics on addr until the returned shutdown function
+// is called.
+func (d *diagnostics) start(l logr.Logger, addr string) (func(context.Context) error, error) {
+	var lc net.ListenConfig
+	ln, err := lc.Listen(context.Background(), "tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("diagnostics endpoint: %w", err)
+	}
+
+	srv := &http.Server{
+		Handler:           d.handler(),
+		ReadHeaderTimeout: diagnosticsReadHeaderTimeout,
+	}
+	go func() {
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			l.Error(err, "diagnostics endpoint failed")
+		}
+	}()
+	l.Info("Diagnostics endpoint started", "address", ln.Addr().String())
+
+	return srv.Shutdown, nil
+}
+
+func (d *diagnostics) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, _ = fmt.Fprint(w, `Splunk Distribution of OpenTelemetry Go diagnostics
+
+/debug/configz    resolved configuration
+/debug/resourcez  resource
+/debug/tracez     spans grouped by name with recent sample spans
+/debug/errorz     last errors and error spans
+/debug/exporterz  exporter health
+`)
+	})
+	mux.HandleFunc("GET /debug/configz", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, d.config)
+	})
+	mux.HandleFunc("GET /debug/resourcez", func(w http.ResponseWriter, _ *http.Request) {
+		attrs := make(map[string]string)
+		if d.resource != nil {
+			for _, kv := range d.resource.Attributes() {
+				attrs[string(kv.Key)] = kv.Value.Emit()
+			}
+		}
+		writeJSON(w, attrs)
+	})
+	mux.HandleFunc("GET /debug/tracez", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, d.spans.snapshot())
+	})
+	mux.HandleFunc("GET /debug/errorz", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, struct {
+			Errors     []errorEntry `json:"errors"`
+			ErrorSpans []errorSpan  `json:"error_spans"`
+		}{d.errors.items(), d.spans.errorSpans.items()})
+	})
+	mux.HandleFunc("GET /debug/exporterz", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, map[string]exporterStatus{
+			"traces":  d.traces.status(d.config.Traces.Exporter),
+			"metrics": d.metrics.status(d.config.Metrics.Exporter),
+			"logs":    d.logsHealth.status(d.config.Logs.Exporter),
+		})
+	})
+	return mux
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// ring retains the last added items.
+type ring[T any] struct {
+	mu   sync.Mutex
+	buf  []T
+	next int
+	full bool
+}
+
+func newRing[T any](n int) ring[T] {
+	return ring[T]{buf: make([]T, n)}
+}
+
+func (r *ring[T]) add(v T) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.buf[r.next] = v
+	r.next = (r.next + 1) % len(r.buf)
+	r.full = r.full || r.next == 0
+}
+
+// items returns the retained items, newest first.
+func (r *ring[T]) items() []T {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := r.next
+	if r.full {
+		n = len(r.buf)
+	}
+	out := make([]T, 0, n)
+	for i := 1; i <= n; i++ {
+		out = append(out, r.buf[(r.next-i+len(r.buf))%len(r.buf)])
+	}
+	return out
+}
+
+// errorEntry is an error reported to the OpenTelemetry error handler.
+type errorEntry struct {
+	Time  time.Time `json:"time"`
+	Error string    `json:"error"`
+}
+
+// errorSpan is a span that ended with an error status.
+type errorSpan struct {
+	Time        time.Time `json:"time"`
+	Name        string    `json:"name"`
+	TraceID     string    `json:"trace_id"`
+	SpanID      string    `json:"span_id"`
+	Description string    `json:"description,omitempty"`
+	Exceptions  []string  `json:"exceptions,omitempty"`
+}
+
+// spanStats are the statistics of the spans with the same name.
+type spanStats struct {
+	Name    string          `json:"name"`
+	Count   int64           `json:"count"`
+	Errors  int64           `json:"errors"`
+	Latency []latencyBucket `json:"latency"`
+}
+
+type latencyBucket struct {
+	// UpperBound is the exclusive upper bound of the bucket, empty for the
+	// last bucket.
+	UpperBound string `json:"lt,omitempty"`
+	Count      int64  `json:"count"`
+	// Samples are the last spans of the bucket, oldest first.
+	Samples []spanSample `json:"samples,omitempty"`
+}
+
+// spanSample is a span recorded in a latency bucket.
+type spanSample struct {
+	Time     time.Time `json:"time"`
+	TraceID  string    `json:"trace_id"`
+	SpanID   string    `json:"span_id"`
+	Duration string    `json:"duration"`
+	Error    bool      `json:"error,omitempty"`
+}
+
+// spanzProcessor records statistics of ended spans.
+type spanzProcessor struct {
+	mu      sync.Mutex
+	byName  map[string]*spanStats
+	dropped int64
+
+	errorSpans ring[errorSpan]
+	// h counts the sampled spans ended, and therefore queued to be exported.
+	h *exporterHealth
+}
+
+var _ trace.SpanProcessor = (*spanzProcessor)(nil)
+
+func newSpanzProcessor(h *exporterHealth) *spanzProcessor {
+	return &spanzProcessor{
+		byName:     make(map[string]*spanStats),
+		errorSpans: newRing[errorSpan](maxDiagnosticsErrors),
+		h:          h,
+	}
+}
+
+func (*spanzProcessor) OnStart(context.Context, trace.ReadWriteSpan) {}
+
+func (p *spanzProcessor) OnEnd(s trace.ReadOnlySpan) {
+	if s.SpanContext().IsSampled() {
+		p.h.enqueue()
+	}
+
+	isErr := s.Status().Code == codes.Error
+	if isErr {
+		p.errorSpans.add(newErrorSpan(s))
+	}
+
+	latency := s.EndTime().Sub(s.StartTime())
+	bucket := len(latencyBounds)
+	for i, b := range latencyBounds {
+		if latency < b {
+			bucket = i
+			break
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	stats, ok := p.byName[s.Name()]
+	if !ok {
+		if len(p.byName) >= maxDiagnosticsSpanNames {
+			p.dropped++
+			return
+		}
+		stats = newSpanStats(s.Name())
+		p.byName[s.Name()] = stats
+	}
+	stats.Count++
+	b := &stats.Latency[bucket]
+	b.Count++
+	if len(b.Samples) == maxDiagnosticsSamples {
+		b.Samples = append(b.Samples[:0], b.Samples[1:]...)
+	}
+	b.Samples = append(b.Samples, spanSample{
+		Time:     s.EndTime(),
+		TraceID:  s.SpanContext().TraceID().String(),
+		SpanID:   s.SpanContext().SpanID().String(),
+		Duration: latency.String(),
+		Error:    isErr,
+	})
+	if isErr {
+		stats.Errors++
+	}
+}
+
+func (*spanzProcessor) Shutdown(context.Context) error   { return nil }
+func (*spanzProcessor) ForceFlush(context.Context) error { return nil }
+
+func newSpanStats(name string) *spanStats {
+	s := &spanStats{Name: name, Latency: make([]latencyBucket, len(latencyBounds)+1)}
+	for i, b := range latencyBounds {
+		s.Latency[i].UpperBound = b.String()
+	}
+	return s
+}
+
+func newErrorSpan(s trace.ReadOnlySpan) errorSpan {
+	e := errorSpan{
+		Time:        s.EndTime(),
+		Name:        s.Name(),
+		TraceID:     s.SpanContext().TraceID().String(),
+		SpanID:      s.SpanContext().SpanID().String(),
+		Description: s.Status().Description,
+	}
+	for _, event := range s.Events() {
+		if event.Name != semconv.ExceptionEventName {
+			continue
+		}
+		for _, kv := range event.Attributes {
+			if kv.Key == semconv.ExceptionMessageKey {
+				e.Exceptions = append(e.Exceptions, kv.Value.Emit())
+			}
+		}
+	}
+	return e
+}
+
+// tracez is the content of the tracez page.
+type tracez struct {
+	Spans []spanStats `json:"spans"`
+	// Dropped is the number of spans not recorded because too many span
+	// names were seen.
+	Dropped int64 `json:"dropped"`
+}
+
+func (p *spanzProcessor) snapshot() tracez {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	out := tracez{Spans: make([]spanStats, 0, len(p.byName)), Dropped: p.dropped}
+	for _, s := range p.byName {
+		c := *s
+		c.Latency = append([]latencyBucket(nil), s.Latency...)
+		for i := range c.Latency {
+			c.Latency[i].Samples = slices.Clone(c.Latency[i].Samples)
+		}
+		out.Spans = append(out.Spans, c)
+	}
+	slices.SortFunc(out.Spans, func(a, b spanStats) int { return cmp.Compare(a.Name, b.Name) })
+	return out
+}
+
+// logzProcessor counts the emitted log records, queued to be exported, in h.
+type logzProcessor struct {
+	h *exporterHealth
+}
+
+var _ log.Processor = (*logzProcessor)(nil)
+
+// Enabled returns false, the processor does not need any record to be
+// emitted.
+func (*logzProcessor) Enabled(context.Context, log.EnabledParameters) bool { return false }
+
+func (p *logzProcessor) OnEmit(context.Context, *log.Record) error {
+	p.h.enqueue()
+	return nil
+}
+
+func (*logzProcessor) Shutdown(context.Context) error   { return nil }
+func (*logzProcessor) ForceFlush(context.Context) error { return nil }
+
+// exporterHealth records the outcome of exports.
+type exporterHealth struct {
+	// queueSize is the number of items the processor of a queued signal
+	// holds before dropping them. It is zero if the signal is not queued.
+	queueSize int64
+
+	mu          sync.Mutex
+	lastSuccess time.Time
+	lastFailure time.Time
+	lastError   string
+	exported    int64
+	failed      int64
+	queued      int64
+	dropped     int64
+}
+
+// enqueue records an item queued to be exported. Items queued when queueSize
+// items are already waiting are counted as dropped by the processor.
+func (h *exporterHealth) enqueue() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.queued >= h.queueSize {
+		h.dropped++
+		return
+	}
+	h.queued++
+}
+
+func (h *exporterHealth) record(n int, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.queued = max(h.queued-int64(n), 0)
+	if err != nil {
+		h.lastFailure = time.Now()
+		h.lastError = err.Error()
+		h.failed += int64(n)
+		return
+	}
+	h.lastSuccess = time.Now()
+	h.exported += int64(n)
+}
+
+// exporterStatus is the content of the exporterz page for an exporter.
+type exporterStatus struct {
+	Exporter    string    `json:"exporter"`
+	LastSuccess time.Time `json:"last_success,omitzero"`
+	LastFailure time.Time `json:"last_failure,omitzero"`
+	LastError   string    `json:"last_error,omitempty"`
+	Exported    int64     `json:"exported"`
+	Failed      int64     `json:"failed"`
+	// QueueDepth is the number of items waiting to be exported. It is nil
+	// if the signal is not queued.
+	QueueDepth *int64 `json:"queue_depth,omitempty"`
+	// Dropped is the estimated number of items dropped because the queue was
+	// full.
+	Dropped int64 `json:"dropped,omitempty"`
+}
+
+// status returns the status of the exporter.
+func (h *exporterHealth) status(exporter ConfigValue) exporterStatus {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s := exporterStatus{
+		Exporter:    exporter.Value,
+		LastSuccess: h.lastSuccess,
+		LastFailure: h.lastFailure,
+		LastError:   h.lastError,
+		Exported:    h.exported,
+		Failed:      h.failed,
+	}
+	if h.queueSize > 0 {
+		depth := h.queued
+		s.QueueDepth = &depth
+		s.Dropped = h.dropped
+	}
+	return s
+}
+
+// healthSpanExporter records the outcome of the exports to h.
+type healthSpanExporter struct {
+	trace.SpanExporter
+	h *exporterHealth
+}
+
+func (e healthSpanExporter) ExportSpans(ctx context.Context, spans []trace.ReadOnlySpan) error {
+	err := e.SpanExporter.ExportSpans(ctx, spans)
+	e.h.record(len(spans), err)
+	return err
+}
+
+// healthMetricExporter records the outcome of the exports to h.
+type healthMetricExporter struct {
+	metric.Exporter
+	h *exporterHealth
+}
+
+func (e healthMetricExporter) Export(ctx context.Context, rm *metricdata.ResourceMetrics) error {
+	err := e.Exporter.Export(ctx, rm)
+	var n int
+	for _, sm := range rm.ScopeMetrics {
+		n += len(sm.Metrics)
+	}
+	e.h.record(n, err)
+	return err
+}
+
+// healthLogExporter records the outcome of the exports to h.
+type healthLogExporter struct {
+	log.Exporter
+	h *exporterHealth
+}
+
+func (e healthLogExporter) Export(ctx context.Context, records []log.Record) error {
+	err := e.Exporter.Export(ctx, records)
+	e.h.record(len(records), err)
+	return err
+}
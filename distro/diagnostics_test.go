@@ -0,0 +1,240 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distro
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+func getJSON(t *testing.T, h http.Handler, path string, v any) {
+	t.Helper()
+	req := httptest.NewRequestWithContext(context.Background(), http.MethodGet, path, http.NoBody)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), v))
+}
+
+func TestRing(t *testing.T) {
+	r := newRing[int](3)
+	assert.Empty(t, r.items())
+	r.add(1)
+	r.add(2)
+	assert.Equal(t, []int{2, 1}, r.items())
+	r.add(3)
+	r.add(4)
+	assert.Equal(t, []int{4, 3, 2}, r.items())
+}
+
+func TestDiagnosticsTracez(t *testing.T) {
+	d := newDiagnostics()
+	exp := tracetest.NewInMemoryExporter()
+	tp := trace.NewTracerProvider(
+		trace.WithSpanProcessor(d.spans),
+		trace.WithSyncer(healthSpanExporter{SpanExporter: exp, h: &d.traces}),
+	)
+	t.Cleanup(func() { require.NoError(t, tp.Shutdown(context.Background())) })
+	tracer := tp.Tracer("test")
+
+	start := time.Now()
+	_, span := tracer.Start(context.Background(), "fast", oteltrace.WithTimestamp(start))
+	span.End(oteltrace.WithTimestamp(start.Add(time.Microsecond)))
+	_, span = tracer.Start(context.Background(), "slow", oteltrace.WithTimestamp(start))
+	span.RecordError(errors.New("boom"))
+	span.SetStatus(codes.Error, "failed")
+	span.End(oteltrace.WithTimestamp(start.Add(2 * time.Second)))
+
+	h := d.handler()
+
+	var tz tracez
+	getJSON(t, h, "/debug/tracez", &tz)
+	require.Len(t, tz.Spans, 2)
+	assert.Equal(t, "fast", tz.Spans[0].Name)
+	assert.Equal(t, int64(1), tz.Spans[0].Count)
+	fast := tz.Spans[0].Latency[0]
+	assert.Equal(t, "10µs", fast.UpperBound)
+	assert.Equal(t, int64(1), fast.Count)
+	require.Len(t, fast.Samples, 1)
+	assert.Equal(t, "1µs", fast.Samples[0].Duration)
+	assert.Len(t, fast.Samples[0].SpanID, 16)
+	assert.False(t, fast.Samples[0].Error)
+	assert.Equal(t, "slow", tz.Spans[1].Name)
+	assert.Equal(t, int64(1), tz.Spans[1].Errors)
+	slow := tz.Spans[1].Latency[6]
+	assert.Equal(t, "10s", slow.UpperBound)
+	assert.Equal(t, int64(1), slow.Count)
+	require.Len(t, slow.Samples, 1)
+	assert.True(t, slow.Samples[0].Error)
+	assert.Empty(t, tz.Spans[1].Latency[0].Samples)
+
+	var ez struct {
+		ErrorSpans []errorSpan `json:"error_spans"`
+	}
+	getJSON(t, h, "/debug/errorz", &ez)
+	require.Len(t, ez.ErrorSpans, 1)
+	assert.Equal(t, "slow", ez.ErrorSpans[0].Name)
+	assert.Equal(t, "failed", ez.ErrorSpans[0].Description)
+	assert.Equal(t, []string{"boom"}, ez.ErrorSpans[0].Exceptions)
+	assert.Len(t, ez.ErrorSpans[0].TraceID, 32)
+
+	var xz map[string]exporterStatus
+	getJSON(t, h, "/debug/exporterz", &xz)
+	assert.Equal(t, int64(2), xz["traces"].Exported)
+	require.NotNil(t, xz["traces"].QueueDepth)
+	assert.Equal(t, int64(0), *xz["traces"].QueueDepth)
+	assert.False(t, xz["traces"].LastSuccess.IsZero())
+	assert.Nil(t, xz["metrics"].QueueDepth)
+}
+
+func TestDiagnosticsTracezSamples(t *testing.T) {
+	d := newDiagnostics()
+	tp := trace.NewTracerProvider(trace.WithSpanProcessor(d.spans))
+	t.Cleanup(func() { require.NoError(t, tp.Shutdown(context.Background())) })
+	tracer := tp.Tracer("test")
+
+	start := time.Now()
+	var last oteltrace.SpanContext
+	for range maxDiagnosticsSamples + 2 {
+		_, span := tracer.Start(context.Background(), "span", oteltrace.WithTimestamp(start))
+		span.End(oteltrace.WithTimestamp(start.Add(time.Microsecond)))
+		last = span.SpanContext()
+	}
+
+	tz := d.spans.snapshot()
+	require.Len(t, tz.Spans, 1)
+	b := tz.Spans[0].Latency[0]
+	assert.Equal(t, int64(maxDiagnosticsSamples+2), b.Count)
+	require.Len(t, b.Samples, maxDiagnosticsSamples)
+	assert.Equal(t, last.SpanID().String(), b.Samples[maxDiagnosticsSamples-1].SpanID, "newest sample last")
+}
+
+func TestDiagnosticsQueueDepth(t *testing.T) {
+	h := exporterHealth{queueSize: 3}
+	for range 5 {
+		h.enqueue()
+	}
+	s := h.status(ConfigValue{Value: otlpValue})
+	require.NotNil(t, s.QueueDepth)
+	assert.Equal(t, int64(3), *s.QueueDepth)
+	assert.Equal(t, int64(2), s.Dropped)
+
+	// Dropped items are not waiting to be exported.
+	h.record(3, nil)
+	s = h.status(ConfigValue{Value: otlpValue})
+	assert.Equal(t, int64(0), *s.QueueDepth)
+	assert.Equal(t, int64(3), s.Exported)
+	assert.Equal(t, int64(2), s.Dropped)
+
+	h.enqueue()
+	s = h.status(ConfigValue{Value: otlpValue})
+	assert.Equal(t, int64(1), *s.QueueDepth)
+}
+
+func TestDiagnosticsExporterFailure(t *testing.T) {
+	h := exporterHealth{queueSize: 10}
+	for range 10 {
+		h.enqueue()
+	}
+	h.record(3, nil)
+	h.record(2, errors.New("unavailable"))
+
+	s := h.status(ConfigValue{Value: otlpValue})
+	assert.Equal(t, otlpValue, s.Exporter)
+	assert.Equal(t, int64(3), s.Exported)
+	assert.Equal(t, int64(2), s.Failed)
+	assert.Equal(t, "unavailable", s.LastError)
+	assert.False(t, s.LastFailure.IsZero())
+	require.NotNil(t, s.QueueDepth)
+	assert.Equal(t, int64(5), *s.QueueDepth)
+}
+
+func TestDiagnosticsConfigAndResource(t *testing.T) {
+	d := newDiagnostics()
+	d.config = EffectiveConfig{Traces: SignalConfig{
+		Exporter:    ConfigValue{Value: otlpValue, Source: SourceDefault},
+		AccessToken: ConfigValue{Value: redacted, Source: SourceEnv},
+	}}
+	d.resource = resource.NewSchemaless(attribute.String("service.name", "checkout"))
+	d.handleError(errors.New("export failed"))
+	h := d.handler()
+
+	var conf map[string]any
+	getJSON(t, h, "/debug/configz", &conf)
+	assert.Equal(t, map[string]any{
+		"exporter":     map[string]any{"value": "otlp", "source": "default"},
+		"access_token": map[string]any{"value": "REDACTED", "source": "env"},
+	}, conf["traces"])
+
+	var res map[string]string
+	getJSON(t, h, "/debug/resourcez", &res)
+	assert.Equal(t, map[string]string{"service.name": "checkout"}, res)
+
+	var ez struct {
+		Errors []errorEntry `json:"errors"`
+	}
+	getJSON(t, h, "/debug/errorz", &ez)
+	require.Len(t, ez.Errors, 1)
+	assert.Equal(t, "export failed", ez.Errors[0].Error)
+}
+
+func TestRunDiagnosticsEndpoint(t *testing.T) {
+	// Reserve a free port for the endpoint.
+	var lc net.ListenConfig
+	ln, err := lc.Listen(context.Background(), "tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := ln.Addr().String()
+	require.NoError(t, ln.Close())
+
+	t.Setenv(otelTracesExporterKey, noneValue)
+	sdk, err := Run(WithDiagnosticsEndpoint(addr))
+	require.NoError(t, err)
+
+	otel.Handle(errors.New("test error"))
+
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "http://"+addr+"/debug/errorz", http.NoBody)
+	require.NoError(t, err)
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	var ez struct {
+		Errors []errorEntry `json:"errors"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&ez))
+	require.NoError(t, resp.Body.Close())
+	require.Len(t, ez.Errors, 1)
+	assert.Equal(t, "test error", ez.Errors[0].Error)
+
+	require.NoError(t, sdk.Shutdown(context.Background()))
+	_, err = client.Do(req) //nolint:bodyclose // the request fails
+	assert.Error(t, err, "endpoint not shut down")
+}
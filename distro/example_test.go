@@ -16,6 +16,9 @@ package distro_test
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 
 	"github.com/signalfx/splunk-otel-go/distro"
 )
@@ -36,3 +39,32 @@ func Example() {
 		}
 	}()
 }
+
+func ExampleSDK_ShutdownOnSignal() {
+	sdk, err := distro.Run()
+	if err != nil {
+		panic(err)
+	}
+
+	// ctx is canceled on SIGINT or SIGTERM. The shutdown function flushes
+	// and shuts down the SDK after running the hooks.
+	ctx, shutdown := sdk.ShutdownOnSignal(
+		context.Background(),
+		distro.WithShutdownTimeout(5*time.Second),
+		distro.WithShutdownHook(func(context.Context) error {
+			// Close servers, database connections, etc.
+			return nil
+		}),
+	)
+	defer func() {
+		if err := shutdown(); err != nil {
+			var sErr *distro.SignalError
+			if errors.As(err, &sErr) {
+				fmt.Printf("failed to shut down %s: %v\n", sErr.Signal, sErr.Err)
+			}
+		}
+	}()
+
+	// Run the application until ctx is done.
+	_ = ctx
+}
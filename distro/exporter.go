@@ -29,11 +29,10 @@ import (
 	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/zipkin" //nolint:staticcheck // Zipkin is deprecated, but still required by legacy backends.
 	"go.opentelemetry.io/otel/sdk/log"
 	"go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/trace"
-	"google.golang.org/grpc/credentials"
-	"google.golang.org/grpc/credentials/insecure"
 )
 
 type traceExporterFunc func(logr.Logger, *exporterConfig) (trace.SpanExporter, error)
@@ -45,77 +44,42 @@ var traceExporters = map[string]traceExporterFunc{
 	otlpValue: newOTLPTracesExporter,
 	// Jaeger thrift exporter.
 	"jaeger-thrift-splunk": newJaegerThriftExporter,
+	// Zipkin v2 JSON exporter.
+	zipkinValue: newZipkinExporter,
+	// OTLP/JSON file exporter.
+	fileValue: newFileTracesExporter,
 	// None, explicitly do not set an exporter.
 	noneValue: nil,
 }
 
-func tracesExporter(l logr.Logger) traceExporterFunc {
+// tracesExporter returns the name and creation function of the exporter
+// configured with OTEL_TRACES_EXPORTER.
+func tracesExporter(l logr.Logger) (string, traceExporterFunc) {
 	key := strings.ToLower(envOr(otelTracesExporterKey, defaultTraceExporter))
 	tef, ok := traceExporters[key]
 	if !ok {
 		err := fmt.Errorf("invalid %s: %q", otelTracesExporterKey, key)
 		l.Error(err, "using default %s: %q", otelTracesExporterKey, defaultTraceExporter)
 
-		return traceExporters[defaultTraceExporter]
+		return defaultTraceExporter, traceExporters[defaultTraceExporter]
 	}
-	return tef
+	return key, tef
 }
 
 func newOTLPTracesExporter(l logr.Logger, c *exporterConfig) (trace.SpanExporter, error) {
 	ctx := context.Background()
+	st := newOTLPSettings(l, c, tracesKeys)
+	c.record(SignalTraces, st.signalConfig(c))
 
-	splunkEndpoint := otlpRealmTracesEndpoint()
-	if splunkEndpoint != "" {
-		// Direct ingest to Splunk Observabilty Cloud using HTTP/protobuf.
-		return otlptracehttp.New(
-			ctx,
-			otlptracehttp.WithEndpoint(splunkEndpoint),
-			otlptracehttp.WithURLPath(otlpRealmTracesEndpointPath),
-			otlptracehttp.WithHeaders(map[string]string{
-				"X-Sf-Token": c.accessToken,
-			}),
-		)
-	}
-
-	headers := make(map[string]string)
-	if c.accessToken != "" {
-		headers["X-Sf-Token"] = c.accessToken
-	}
-	isLocalCollector := noneEnvVarSet(otelExporterOTLPEndpointKey, otelExporterOTLPTracesEndpointKey, splunkRealmKey)
-	protocol := otlpProtocol(l, otelTracesExporterOTLPProtocolKey)
-
-	if protocol == otlpProtocolHTTPProtobuf {
-		var opts []otlptracehttp.Option
-
-		if len(headers) > 0 {
-			opts = append(opts, otlptracehttp.WithHeaders(headers))
-		}
-
-		if c.TLSConfig != nil {
-			opts = append(opts, otlptracehttp.WithTLSClientConfig(c.TLSConfig))
-		} else if isLocalCollector {
-			// Assume that the default endpoint (local collector) is non-TLS.
-			opts = append(opts, otlptracehttp.WithInsecure())
-		}
-
-		return otlptracehttp.New(ctx, opts...)
+	if st.protocol.Value == otlpProtocolGRPC {
+		return otlptracegrpc.New(ctx, otlpTracesGRPCOptions(l, c, st)...)
 	}
-
-	var opts []otlptracegrpc.Option
-
-	if len(headers) > 0 {
-		opts = append(opts, otlptracegrpc.WithHeaders(headers))
-	}
-
-	if c.TLSConfig != nil {
-		tlsCreds := credentials.NewTLS(c.TLSConfig)
-		opts = append(opts, otlptracegrpc.WithTLSCredentials(tlsCreds))
-	} else if isLocalCollector {
-		// Assume that the default endpoint (local collector) is non-TLS.
-		opts = append(opts, otlptracegrpc.WithTLSCredentials(insecure.NewCredentials()))
+	var wrappers []func(http.RoundTripper) http.RoundTripper
+	if st.realm {
+		// Split the batches too large for Splunk Observability Cloud ingest.
+		wrappers = append(wrappers, newSplitTransport(realmMaxRequestSize(l, c), tracesSplitter, st.protocol.Value))
 	}
-
-	return otlptracegrpc.New(ctx, opts...)
+	return otlptracehttp.New(ctx, otlpTracesHTTPOptions(l, c, st, wrappers...)...)
 }
 
 // otlpRealmTracesEndpoint returns the endpoint to use for the OTLP HTTP/protobuf traces exporter.
@@ -167,11 +131,31 @@ func newJaegerThriftExporter(l logr.Logger, c *exporterConfig) (trace.SpanExport
 
 	var opts []jaeger.CollectorEndpointOption
 
-	if e := jaegerEndpoint(); e != "" {
-		opts = append(opts, jaeger.WithEndpoint(e))
+	endpoint := ConfigValue{Value: jaegerEndpoint(), Source: SourceDefault}
+	switch endpoint.Value {
+	case "":
+		endpoint = ConfigValue{Value: os.Getenv(otelExporterJaegerEndpointKey), Source: SourceEnv}
+	case jaegerDefaultEndpoint:
+	default:
+		// Resolved from SPLUNK_REALM.
+		endpoint.Source = SourceEnv
 	}
-
-	if c.accessToken != "" {
+	if endpoint.Value != "" {
+		opts = append(opts, jaeger.WithEndpoint(endpoint.Value))
+	}
+	c.record(SignalTraces, SignalConfig{
+		Endpoint:    ConfigValue{Value: redactURL(endpoint.Value), Source: endpoint.Source},
+		TLS:         endpointTLS(c, endpoint),
+		AccessToken: describeToken(c),
+	})
+
+	if c.TokenProvider != nil {
+		client := exporterHTTPClient(c, newTokenTransport(l, c.TokenProvider, true))
+		if c.HTTPClient == nil {
+			client.Timeout = exportTimeout(c, SignalTraces)
+		}
+		opts = append(opts, jaeger.WithHTTPClient(client))
+	} else if c.accessToken != "" {
 		opts = append(
 			opts,
 			jaeger.WithUsername("auth"),
@@ -179,7 +163,7 @@ func newJaegerThriftExporter(l logr.Logger, c *exporterConfig) (trace.SpanExport
 		)
 	}
 
-	if c.TLSConfig != nil {
+	if c.TLSConfig != nil && c.TokenProvider == nil {
 		client := &http.Client{
 			Transport: &http.Transport{TLSClientConfig: c.TLSConfig},
 		}
@@ -206,6 +190,44 @@ func jaegerEndpoint() string {
 	return jaegerDefaultEndpoint
 }
 
+func newZipkinExporter(l logr.Logger, c *exporterConfig) (trace.SpanExporter, error) {
+	// The exporter logs each request body, only do so at debug level.
+	opts := []zipkin.Option{zipkin.WithLogr(l.V(2))}
+
+	if c.accessToken != "" && c.TokenProvider == nil {
+		opts = append(opts, zipkin.WithHeaders(map[string]string{
+			accessTokenHeader: c.accessToken,
+		}))
+	}
+
+	var wrappers []func(http.RoundTripper) http.RoundTripper
+	if c.TokenProvider != nil {
+		wrappers = append(wrappers, newTokenTransport(l, c.TokenProvider, false))
+	}
+	if c.TLSConfig != nil || len(wrappers) > 0 || c.customHTTP() {
+		client := exporterHTTPClient(c, wrappers...)
+		if c.HTTPClient == nil {
+			client.Timeout = exportTimeout(c, SignalTraces)
+		}
+		opts = append(opts, zipkin.WithClient(client))
+	}
+
+	// The default is also the endpoint of the Zipkin receiver of a locally
+	// running collector.
+	endpoint := envValue(otelExporterZipkinEndpointKey, zipkinDefaultEndpoint)
+	tlsValue := endpointTLS(c, endpoint)
+	if c.TLSConfig == nil && (c.HTTPClient != nil || c.HTTPTransport != nil) {
+		// The client or transport is used as is, with its TLS configuration.
+		tlsValue = ConfigValue{Value: "custom", Source: SourceOption}
+	}
+	c.record(SignalTraces, SignalConfig{
+		Endpoint:    ConfigValue{Value: redactURL(endpoint.Value), Source: endpoint.Source},
+		TLS:         tlsValue,
+		AccessToken: describeToken(c),
+	})
+	return zipkin.New(endpoint.Value, opts...)
+}
+
 type metricsExporterFunc func(logr.Logger, *exporterConfig) (metric.Exporter, error)
 
 // metricsExporters maps environment variable values to metrics exporter creation
@@ -213,77 +235,40 @@ type metricsExporterFunc func(logr.Logger, *exporterConfig) (metric.Exporter, er
 var metricsExporters = map[string]metricsExporterFunc{
 	// OTLP gRPC exporter.
 	otlpValue: newOTLPMetricsExporter,
+	// OTLP/JSON file exporter.
+	fileValue: newFileMetricsExporter,
 	// None, explicitly do not set an exporter.
 	noneValue: nil,
 }
 
-func metricsExporter(l logr.Logger) metricsExporterFunc {
+// metricsExporter returns the name and creation function of the exporter
+// configured with OTEL_METRICS_EXPORTER.
+func metricsExporter(l logr.Logger) (string, metricsExporterFunc) {
 	key := strings.ToLower(envOr(otelMetricsExporterKey, defaultMetricsExporter))
 	mef, ok := metricsExporters[key]
 	if !ok {
 		err := fmt.Errorf("invalid %s: %q", otelMetricsExporterKey, key)
 		l.Error(err, "using default %s: %q", otelMetricsExporterKey, defaultMetricsExporter)
 
-		return metricsExporters[defaultMetricsExporter]
+		return defaultMetricsExporter, metricsExporters[defaultMetricsExporter]
 	}
-	return mef
+	return key, mef
 }
 
 func newOTLPMetricsExporter(l logr.Logger, c *exporterConfig) (metric.Exporter, error) {
 	ctx := context.Background()
+	st := newOTLPSettings(l, c, metricsKeys)
+	c.record(SignalMetrics, st.signalConfig(c))
 
-	splunkEndpoint := otlpRealmMetricsEndpoint()
-	if splunkEndpoint != "" {
-		// Direct ingest to Splunk Observabilty Cloud using HTTP/protobuf.
-		return otlpmetrichttp.New(
-			ctx,
-			otlpmetrichttp.WithEndpoint(splunkEndpoint),
-			otlpmetrichttp.WithURLPath(otlpRealmMetricsEndpointPath),
-			otlpmetrichttp.WithHeaders(map[string]string{
-				"X-Sf-Token": c.accessToken,
-			}),
-		)
-	}
-
-	headers := make(map[string]string)
-	if c.accessToken != "" {
-		headers["X-Sf-Token"] = c.accessToken
-	}
-	isLocalCollector := noneEnvVarSet(otelExporterOTLPEndpointKey, otelExporterOTLPMetricsEndpointKey, splunkRealmKey)
-	protocol := otlpProtocol(l, otelMetricsExporterOTLPProtocolKey)
-
-	if protocol == otlpProtocolHTTPProtobuf {
-		var opts []otlpmetrichttp.Option
-
-		if len(headers) > 0 {
-			opts = append(opts, otlpmetrichttp.WithHeaders(headers))
-		}
-
-		if c.TLSConfig != nil {
-			opts = append(opts, otlpmetrichttp.WithTLSClientConfig(c.TLSConfig))
-		} else if isLocalCollector {
-			// Assume that the default endpoint (local collector) is non-TLS.
-			opts = append(opts, otlpmetrichttp.WithInsecure())
-		}
-
-		return otlpmetrichttp.New(ctx, opts...)
-	}
-
-	var opts []otlpmetricgrpc.Option
-
-	if len(headers) > 0 {
-		opts = append(opts, otlpmetricgrpc.WithHeaders(headers))
+	if st.protocol.Value == otlpProtocolGRPC {
+		return otlpmetricgrpc.New(ctx, otlpMetricsGRPCOptions(l, c, st)...)
 	}
-
-	if c.TLSConfig != nil {
-		tlsCreds := credentials.NewTLS(c.TLSConfig)
-		opts = append(opts, otlpmetricgrpc.WithTLSCredentials(tlsCreds))
-	} else if isLocalCollector {
-		// Assume that the default endpoint (local collector) is non-TLS.
-		opts = append(opts, otlpmetricgrpc.WithTLSCredentials(insecure.NewCredentials()))
+	var wrappers []func(http.RoundTripper) http.RoundTripper
+	if st.realm {
+		// Split the batches too large for Splunk Observability Cloud ingest.
+		wrappers = append(wrappers, newSplitTransport(realmMaxRequestSize(l, c), metricsSplitter, st.protocol.Value))
 	}
-
-	return otlpmetricgrpc.New(ctx, opts...)
+	return otlpmetrichttp.New(ctx, otlpMetricsHTTPOptions(l, c, st, wrappers...)...)
 }
 
 type logsExporterFunc func(logr.Logger, *exporterConfig) (log.Exporter, error)
@@ -293,65 +278,36 @@ type logsExporterFunc func(logr.Logger, *exporterConfig) (log.Exporter, error)
 var logsExporters = map[string]logsExporterFunc{
 	// OTLP gRPC exporter.
 	otlpValue: newOTLPLogExporter,
+	// OTLP/JSON file exporter.
+	fileValue: newFileLogsExporter,
 	// None, explicitly do not set an exporter.
 	noneValue: nil,
 }
 
-func logsExporter(l logr.Logger) logsExporterFunc {
+// logsExporter returns the name and creation function of the exporter
+// configured with OTEL_LOGS_EXPORTER.
+func logsExporter(l logr.Logger) (string, logsExporterFunc) {
 	key := strings.ToLower(envOr(otelLogsExporterKey, defaultLogsExporter))
 	lef, ok := logsExporters[key]
 	if !ok {
 		err := fmt.Errorf("invalid %s: %q", otelLogsExporterKey, key)
 		l.Error(err, "using default %s: %q", otelLogsExporterKey, defaultLogsExporter)
 
-		return logsExporters[defaultLogsExporter]
+		return defaultLogsExporter, logsExporters[defaultLogsExporter]
 	}
-	return lef
+	return key, lef
 }
 
 func newOTLPLogExporter(l logr.Logger, c *exporterConfig) (log.Exporter, error) {
 	ctx := context.Background()
 	// SPLUNK_REALM is not supported, Splunk Observability ingest does not support OTLP.
+	st := newOTLPSettings(l, c, logsKeys)
+	c.record(SignalLogs, st.signalConfig(c))
 
-	headers := make(map[string]string)
-	if c.accessToken != "" {
-		headers["X-Sf-Token"] = c.accessToken
-	}
-	isLocalCollector := noneEnvVarSet(otelExporterOTLPEndpointKey, otelExporterOTLPLogsEndpointKey)
-	protocol := otlpProtocol(l, otelLogsExporterOTLPProtocolKey)
-
-	if protocol == otlpProtocolHTTPProtobuf {
-		var opts []otlploghttp.Option
-
-		if len(headers) > 0 {
-			opts = append(opts, otlploghttp.WithHeaders(headers))
-		}
-
-		if c.TLSConfig != nil {
-			opts = append(opts, otlploghttp.WithTLSClientConfig(c.TLSConfig))
-		} else if isLocalCollector {
-			// Assume that the default endpoint (local collector) is non-TLS.
-			opts = append(opts, otlploghttp.WithInsecure())
-		}
-
-		return otlploghttp.New(ctx, opts...)
-	}
-
-	var opts []otlploggrpc.Option
-
-	if len(headers) > 0 {
-		opts = append(opts, otlploggrpc.WithHeaders(headers))
-	}
-
-	if c.TLSConfig != nil {
-		tlsCreds := credentials.NewTLS(c.TLSConfig)
-		opts = append(opts, otlploggrpc.WithTLSCredentials(tlsCreds))
-	} else if isLocalCollector {
-		// Assume that the default endpoint (local collector) is non-TLS.
-		opts = append(opts, otlploggrpc.WithTLSCredentials(insecure.NewCredentials()))
+	if st.protocol.Value == otlpProtocolGRPC {
+		return otlploggrpc.New(ctx, otlpLogsGRPCOptions(l, c, st)...)
 	}
-
-	return otlploggrpc.New(ctx, opts...)
+	return otlploghttp.New(ctx, otlpLogsHTTPOptions(l, c, st)...)
 }
 
 // noneEnvVarSet returns true if none of provided env vars is set.
@@ -373,7 +329,7 @@ func otlpProtocol(l logr.Logger, signalKey string) string {
 	// Signal-specific key takes precedence.
 	if v := os.Getenv(signalKey); v != "" {
 		vLower := strings.ToLower(v)
-		if vLower == otlpProtocolGRPC || vLower == otlpProtocolHTTPProtobuf {
+		if validOTLPProtocol(vLower) {
 			return vLower
 		}
 		err := fmt.Errorf("invalid %s: %q", signalKey, v)
@@ -383,7 +339,7 @@ func otlpProtocol(l logr.Logger, signalKey string) string {
 	// Fallback to general OTLP protocol.
 	if v := os.Getenv(otelExporterOTLPProtocolKey); v != "" {
 		vLower := strings.ToLower(v)
-		if vLower == otlpProtocolGRPC || vLower == otlpProtocolHTTPProtobuf {
+		if validOTLPProtocol(vLower) {
 			return vLower
 		}
 		err := fmt.Errorf("invalid %s: %q", otelExporterOTLPProtocolKey, v)
@@ -392,3 +348,65 @@ func otlpProtocol(l logr.Logger, signalKey string) string {
 
 	return defaultOTLPProtocol
 }
+
+func validOTLPProtocol(p string) bool {
+	return p == otlpProtocolGRPC || p == otlpProtocolHTTPProtobuf || p == otlpProtocolHTTPJSON
+}
+
+// otlpHTTPClient returns the HTTP client an OTLP HTTP exporter needs to use
+// for protocol, or nil if the default client of the exporter can be used. The
+// transport of the client is wrapped by outer last.
+//
+// The client replaces the one the exporter would create, so it is configured
+// with the OTLP exporter TLS environment variables of s the exporter would
+// otherwise use.
+func otlpHTTPClient(l logr.Logger, c *exporterConfig, s Signal, protocol string, m otlpMessages, outer ...func(http.RoundTripper) http.RoundTripper) *http.Client {
+	var wrappers []func(http.RoundTripper) http.RoundTripper
+	if c.TokenProvider != nil {
+		wrappers = append(wrappers, newTokenTransport(l, c.TokenProvider, false))
+	}
+	if protocol == otlpProtocolHTTPJSON {
+		wrappers = append(wrappers, newJSONTransport(m))
+	}
+	wrappers = append(wrappers, outer...)
+	if len(wrappers) == 0 && !c.customHTTP() {
+		return nil
+	}
+	if c.TLSConfig == nil {
+		if tlsConfig := otlpEnvTLSConfig(l, s); tlsConfig != nil {
+			envConfig := *c
+			envConfig.TLSConfig = tlsConfig
+			c = &envConfig
+		}
+	}
+	return exporterHTTPClient(c, wrappers...)
+}
+
+// exporterHTTPClient returns an HTTP client using the TLS configuration of c
+// and a transport wrapped by wrappers in order. If c has an HTTP client, a
+// copy of it with its transport wrapped is returned instead.
+func exporterHTTPClient(c *exporterConfig, wrappers ...func(http.RoundTripper) http.RoundTripper) *http.Client {
+	client := &http.Client{Timeout: defaultExportTimeout}
+	switch {
+	case c.HTTPClient != nil:
+		*client = *c.HTTPClient
+		if client.Transport == nil {
+			client.Transport = http.DefaultTransport
+		}
+	case c.HTTPTransport != nil:
+		client.Transport = c.HTTPTransport
+	default:
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		if c.TLSConfig != nil {
+			transport.TLSClientConfig = c.TLSConfig
+		}
+		if c.Proxy != nil {
+			transport.Proxy = c.Proxy
+		}
+		client.Transport = transport
+	}
+	for _, w := range wrappers {
+		client.Transport = w(client.Transport)
+	}
+	return client
+}
@@ -110,6 +110,16 @@ func TestOTLPProtocol(t *testing.T) {
 		assert.Empty(t, buf.String())
 	})
 
+	t.Run("http/json", func(t *testing.T) {
+		t.Setenv(otelTracesExporterOTLPProtocolKey, "HTTP/JSON")
+		buf, logger := newTestLogger()
+
+		got := otlpProtocol(logger, otelTracesExporterOTLPProtocolKey)
+
+		assert.Equal(t, otlpProtocolHTTPJSON, got)
+		assert.Empty(t, buf.String())
+	})
+
 	t.Run("specific overrides general", func(t *testing.T) {
 		t.Setenv(otelExporterOTLPProtocolKey, defaultOTLPProtocol)
 		t.Setenv(otelTracesExporterOTLPProtocolKey, otlpProtocolHTTPProtobuf)
@@ -0,0 +1,442 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distro
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/protobuf/proto"
+)
+
+// fileEndpoint is the endpoint the OTLP HTTP exporters used by the file
+// exporters are configured with. No network connection is made to it.
+const fileEndpoint = "localhost"
+
+// fileConfig is the configuration of a file exporter.
+type fileConfig struct {
+	// Path is the file telemetry is written to.
+	Path string
+	// MaxSize is the size in bytes after which the file is rotated. Zero
+	// disables size based rotation.
+	MaxSize int64
+	// Interval is the duration after which the file is rotated. Zero
+	// disables time based rotation.
+	Interval time.Duration
+	// MaxBackups is the number of rotated files retained. Zero retains all
+	// rotated files.
+	MaxBackups int
+	// Compress rotated files using gzip.
+	Compress bool
+}
+
+// newFileConfig returns the file exporter configuration read from the
+// environment, using defaultPath if no path is configured for the signal.
+func newFileConfig(l logr.Logger, pathKey, defaultPath string) fileConfig {
+	c := fileConfig{
+		Path:       envOr(pathKey, defaultPath),
+		MaxSize:    int64(envInt(l, fileExporterMaxMegabytesKey, defaultFileMaxMegabytes)) * 1024 * 1024,
+		MaxBackups: envInt(l, fileExporterMaxBackupsKey, defaultFileMaxBackups),
+	}
+
+	if v := os.Getenv(fileExporterRotationIntervalKey); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			l.Error(fmt.Errorf("invalid %s: %q", fileExporterRotationIntervalKey, v), "time based rotation disabled")
+		} else {
+			c.Interval = d
+		}
+	}
+
+	switch v := strings.ToLower(envOr(fileExporterCompressionKey, noneValue)); v {
+	case "gzip":
+		c.Compress = true
+	case noneValue:
+	default:
+		l.Error(fmt.Errorf("invalid %s: %q", fileExporterCompressionKey, v), "rotated files will not be compressed")
+	}
+	return c
+}
+
+// envInt returns the non-negative integer value of the environment variable
+// key, or alt if it is not set or invalid.
+func envInt(l logr.Logger, key string, alt int) int {
+	v := os.Getenv(key)
+	if v == "" {
+		return alt
+	}
+	i, err := strconv.Atoi(v)
+	if err != nil || i < 0 {
+		l.Error(fmt.Errorf("invalid %s: %q", key, v), "invalid value, using default", "key", key, "default", alt)
+		return alt
+	}
+	return i
+}
+
+func newFileTracesExporter(l logr.Logger, c *exporterConfig) (trace.SpanExporter, error) {
+	c.record(SignalTraces, SignalConfig{Endpoint: envValue(fileExporterTracesPathKey, defaultFileTracesPath)})
+	f, err := newRotatingFile(l, newFileConfig(l, fileExporterTracesPathKey, defaultFileTracesPath))
+	if err != nil {
+		return nil, err
+	}
+	exp, err := otlptracehttp.New(
+		context.Background(),
+		otlptracehttp.WithEndpoint(fileEndpoint),
+		otlptracehttp.WithRetry(otlptracehttp.RetryConfig{Enabled: false}),
+		otlptracehttp.WithHTTPClient(&http.Client{Transport: newFileTransport(f, tracesMessages)}),
+	)
+	if err != nil {
+		return nil, errors.Join(err, f.Close())
+	}
+	return fileSpanExporter{SpanExporter: exp, file: f}, nil
+}
+
+func newFileMetricsExporter(l logr.Logger, c *exporterConfig) (metric.Exporter, error) {
+	c.record(SignalMetrics, SignalConfig{Endpoint: envValue(fileExporterMetricsPathKey, defaultFileMetricsPath)})
+	f, err := newRotatingFile(l, newFileConfig(l, fileExporterMetricsPathKey, defaultFileMetricsPath))
+	if err != nil {
+		return nil, err
+	}
+	exp, err := otlpmetrichttp.New(
+		context.Background(),
+		otlpmetrichttp.WithEndpoint(fileEndpoint),
+		otlpmetrichttp.WithRetry(otlpmetrichttp.RetryConfig{Enabled: false}),
+		otlpmetrichttp.WithHTTPClient(&http.Client{Transport: newFileTransport(f, metricsMessages)}),
+	)
+	if err != nil {
+		return nil, errors.Join(err, f.Close())
+	}
+	return fileMetricExporter{Exporter: exp, file: f}, nil
+}
+
+func newFileLogsExporter(l logr.Logger, c *exporterConfig) (log.Exporter, error) {
+	c.record(SignalLogs, SignalConfig{Endpoint: envValue(fileExporterLogsPathKey, defaultFileLogsPath)})
+	f, err := newRotatingFile(l, newFileConfig(l, fileExporterLogsPathKey, defaultFileLogsPath))
+	if err != nil {
+		return nil, err
+	}
+	exp, err := otlploghttp.New(
+		context.Background(),
+		otlploghttp.WithEndpoint(fileEndpoint),
+		otlploghttp.WithRetry(otlploghttp.RetryConfig{Enabled: false}),
+		otlploghttp.WithHTTPClient(&http.Client{Transport: newFileTransport(f, logsMessages)}),
+	)
+	if err != nil {
+		return nil, errors.Join(err, f.Close())
+	}
+	return fileLogExporter{Exporter: exp, file: f}, nil
+}
+
+// fileSpanExporter closes the file it writes to when shut down.
+type fileSpanExporter struct {
+	trace.SpanExporter
+	file *rotatingFile
+}
+
+func (e fileSpanExporter) Shutdown(ctx context.Context) error {
+	return errors.Join(e.SpanExporter.Shutdown(ctx), e.file.Close())
+}
+
+// fileMetricExporter closes the file it writes to when shut down.
+type fileMetricExporter struct {
+	metric.Exporter
+	file *rotatingFile
+}
+
+func (e fileMetricExporter) Shutdown(ctx context.Context) error {
+	return errors.Join(e.Exporter.Shutdown(ctx), e.file.Close())
+}
+
+// fileLogExporter closes the file it writes to when shut down.
+type fileLogExporter struct {
+	log.Exporter
+	file *rotatingFile
+}
+
+func (e fileLogExporter) Shutdown(ctx context.Context) error {
+	return errors.Join(e.Exporter.Shutdown(ctx), e.file.Close())
+}
+
+// fileTransport is an http.RoundTripper that writes OTLP/protobuf export
+// requests as OTLP/JSON lines to a file instead of sending them.
+//
+// The OTLP HTTP exporters are used with this transport so the file exporters
+// share their transformation of telemetry to OTLP. The lines written are the
+// format read by the OpenTelemetry Collector otlpjsonfile receiver.
+type fileTransport struct {
+	file     *rotatingFile
+	messages otlpMessages
+}
+
+func newFileTransport(f *rotatingFile, m otlpMessages) *fileTransport {
+	return &fileTransport{file: f, messages: m}
+}
+
+// RoundTrip writes req to the file and returns an empty successful response.
+func (t *fileTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req.Body, req.Header.Get("Content-Encoding") == "gzip")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read OTLP request: %w", err)
+	}
+
+	msg := t.messages.request()
+	if err := proto.Unmarshal(body, msg); err != nil {
+		return nil, fmt.Errorf("failed to decode OTLP request: %w", err)
+	}
+	line, err := marshalOTLPJSON(msg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode OTLP/JSON: %w", err)
+	}
+	if err := t.file.WriteLine(line); err != nil {
+		return nil, err
+	}
+
+	return &http.Response{
+		Status:     http.StatusText(http.StatusOK),
+		StatusCode: http.StatusOK,
+		Proto:      req.Proto,
+		ProtoMajor: req.ProtoMajor,
+		ProtoMinor: req.ProtoMinor,
+		Header:     make(http.Header),
+		Body:       http.NoBody,
+		Request:    req,
+	}, nil
+}
+
+// backupTimeFormat is the format of the rotation time in backup names.
+const backupTimeFormat = "20060102T150405.000000000"
+
+// rotatingFile is a file that is rotated based on its size and age.
+//
+// Rotated files are renamed to include the time of the rotation, e.g.
+// traces.jsonl is rotated to traces-20060102T150405.000000000.jsonl.
+type rotatingFile struct {
+	conf   fileConfig
+	logger logr.Logger
+	now    func() time.Time
+
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+	openedAt time.Time
+	closed   bool
+}
+
+func newRotatingFile(l logr.Logger, c fileConfig) (*rotatingFile, error) {
+	f := &rotatingFile{conf: c, logger: l, now: time.Now}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// open opens the file, appending to it if it exists. It must be called
+// while holding the lock, or before the file is used.
+func (f *rotatingFile) open() error {
+	if dir := filepath.Dir(f.conf.Path); dir != "" {
+		if err := os.MkdirAll(dir, 0o750); err != nil {
+			return err
+		}
+	}
+	file, err := os.OpenFile(f.conf.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return errors.Join(err, file.Close())
+	}
+	f.file, f.size, f.openedAt = file, info.Size(), f.now()
+	return nil
+}
+
+// WriteLine writes b followed by a newline, rotating the file before if
+// needed.
+//
+// A failed rotation is logged and b is written to the file open after it,
+// so telemetry is only lost if no file can be opened.
+func (f *rotatingFile) WriteLine(b []byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return errors.New("file exporter is shut down")
+	}
+
+	n := int64(len(b)) + 1
+	if f.file != nil && f.size > 0 && f.needsRotation(n) {
+		if err := f.rotate(); err != nil {
+			f.logger.Error(err, "failed to rotate file", "path", f.conf.Path)
+		}
+	}
+	if f.file == nil {
+		// A previous rotation could not open the file, try again.
+		if err := f.open(); err != nil {
+			return fmt.Errorf("failed to open %s: %w", f.conf.Path, err)
+		}
+	}
+
+	written, err := f.file.Write(append(b, '\n'))
+	f.size += int64(written)
+	return err
+}
+
+func (f *rotatingFile) needsRotation(n int64) bool {
+	if f.conf.MaxSize > 0 && f.size+n > f.conf.MaxSize {
+		return true
+	}
+	return f.conf.Interval > 0 && f.now().Sub(f.openedAt) >= f.conf.Interval
+}
+
+// rotate moves the current file to a backup and opens a new file.
+//
+// If the file cannot be moved, the current file is reopened so it keeps
+// being written to. The file is nil after rotate returns only if neither the
+// current file nor a new one can be opened.
+func (f *rotatingFile) rotate() error {
+	ext := filepath.Ext(f.conf.Path)
+	prefix := strings.TrimSuffix(f.conf.Path, ext) + "-"
+	backup := prefix + f.now().UTC().Format(backupTimeFormat) + ext
+
+	// The file is closed before being moved for the platforms not able to
+	// rename open files.
+	err := f.file.Close()
+	f.file = nil
+	if err == nil {
+		err = os.Rename(f.conf.Path, backup)
+	}
+	if err != nil {
+		return errors.Join(err, f.open())
+	}
+	if err := f.open(); err != nil {
+		return err
+	}
+
+	if f.conf.Compress {
+		if err := gzipFile(backup); err != nil {
+			return err
+		}
+	}
+	return f.prune()
+}
+
+// backups returns the backups of the file, sorted from the oldest to the
+// newest.
+func (f *rotatingFile) backups() ([]string, error) {
+	dir, base := filepath.Split(f.conf.Path)
+	ext := filepath.Ext(base)
+	prefix := strings.TrimSuffix(base, ext) + "-"
+
+	entries, err := os.ReadDir(filepath.Clean(dir))
+	if err != nil {
+		return nil, err
+	}
+	var backups []string
+	for _, e := range entries {
+		if e.IsDir() || !isBackup(e.Name(), prefix, ext) {
+			continue
+		}
+		backups = append(backups, filepath.Join(dir, e.Name()))
+	}
+	// The timestamp in the backup names sorts chronologically.
+	sort.Strings(backups)
+	return backups, nil
+}
+
+// isBackup returns if name is the name of a backup of a file named with
+// prefix and ext: the prefix, the rotation time, ext, and an optional .gz
+// extension if compressed.
+func isBackup(name, prefix, ext string) bool {
+	ts, ok := strings.CutPrefix(name, prefix)
+	if !ok {
+		return false
+	}
+	ts = strings.TrimSuffix(ts, ".gz")
+	if ts, ok = strings.CutSuffix(ts, ext); !ok {
+		return false
+	}
+	_, err := time.Parse(backupTimeFormat, ts)
+	return err == nil
+}
+
+// prune removes the oldest backups exceeding the configured retention.
+func (f *rotatingFile) prune() error {
+	if f.conf.MaxBackups <= 0 {
+		return nil
+	}
+	backups, err := f.backups()
+	if err != nil {
+		return err
+	}
+	if len(backups) <= f.conf.MaxBackups {
+		return nil
+	}
+	var errs []error
+	for _, b := range backups[:len(backups)-f.conf.MaxBackups] {
+		errs = append(errs, os.Remove(b))
+	}
+	return errors.Join(errs...)
+}
+
+// Close closes the file.
+func (f *rotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.closed = true
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+// gzipFile compresses the file at path to path.gz and removes the original.
+func gzipFile(path string) error {
+	src, err := os.Open(path) //nolint:gosec // path is the configured file exporter path.
+	if err != nil {
+		return err
+	}
+
+	dst, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return errors.Join(err, src.Close())
+	}
+	gz := gzip.NewWriter(dst)
+	_, err = io.Copy(gz, src)
+	if err = errors.Join(err, gz.Close(), dst.Close(), src.Close()); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
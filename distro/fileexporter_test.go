@@ -0,0 +1,213 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distro
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tonglil/buflogr"
+)
+
+func TestNewFileConfig(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		c := newFileConfig(logr.Discard(), fileExporterTracesPathKey, defaultFileTracesPath)
+		assert.Equal(t, fileConfig{
+			Path:       defaultFileTracesPath,
+			MaxSize:    defaultFileMaxMegabytes * 1024 * 1024,
+			MaxBackups: defaultFileMaxBackups,
+		}, c)
+	})
+
+	t.Run("environment", func(t *testing.T) {
+		t.Setenv(fileExporterTracesPathKey, "/tmp/spans.jsonl")
+		t.Setenv(fileExporterMaxMegabytesKey, "1")
+		t.Setenv(fileExporterRotationIntervalKey, "1h")
+		t.Setenv(fileExporterMaxBackupsKey, "3")
+		t.Setenv(fileExporterCompressionKey, "GZIP")
+
+		c := newFileConfig(logr.Discard(), fileExporterTracesPathKey, defaultFileTracesPath)
+		assert.Equal(t, fileConfig{
+			Path:       "/tmp/spans.jsonl",
+			MaxSize:    1024 * 1024,
+			Interval:   time.Hour,
+			MaxBackups: 3,
+			Compress:   true,
+		}, c)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Setenv(fileExporterMaxMegabytesKey, "-1")
+		t.Setenv(fileExporterRotationIntervalKey, "hourly")
+		t.Setenv(fileExporterCompressionKey, "zstd")
+		var buf bytes.Buffer
+
+		c := newFileConfig(buflogr.NewWithBuffer(&buf), fileExporterLogsPathKey, defaultFileLogsPath)
+		assert.Equal(t, int64(defaultFileMaxMegabytes*1024*1024), c.MaxSize)
+		assert.Zero(t, c.Interval)
+		assert.False(t, c.Compress)
+		assert.Contains(t, buf.String(), fileExporterMaxMegabytesKey)
+		assert.Contains(t, buf.String(), "default 100")
+		assert.Contains(t, buf.String(), fileExporterRotationIntervalKey)
+		assert.Contains(t, buf.String(), fileExporterCompressionKey)
+	})
+}
+
+func TestRotatingFileSize(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "traces.jsonl")
+	f, err := newRotatingFile(logr.Discard(), fileConfig{Path: path, MaxSize: 8, MaxBackups: 2})
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, f.Close()) })
+
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	f.now = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+
+	for _, line := range []string{"one", "two", "three", "four", "five"} {
+		require.NoError(t, f.WriteLine([]byte(line)))
+	}
+
+	got, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "five\n", string(got))
+
+	backups, err := filepath.Glob(filepath.Join(dir, "traces-*.jsonl"))
+	require.NoError(t, err)
+	require.Len(t, backups, 2, "retention not applied")
+	got, err = os.ReadFile(backups[0])
+	require.NoError(t, err)
+	assert.Equal(t, "three\n", string(got))
+	got, err = os.ReadFile(backups[1])
+	require.NoError(t, err)
+	assert.Equal(t, "four\n", string(got))
+}
+
+func TestRotatingFileInterval(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "metrics.jsonl")
+	f, err := newRotatingFile(logr.Discard(), fileConfig{Path: path, Interval: time.Minute, Compress: true})
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, f.Close()) })
+
+	now := time.Now()
+	f.now = func() time.Time { return now }
+
+	require.NoError(t, f.WriteLine([]byte("one")))
+	require.NoError(t, f.WriteLine([]byte("two")))
+	now = now.Add(time.Minute)
+	require.NoError(t, f.WriteLine([]byte("three")))
+
+	got, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "three\n", string(got))
+
+	backups, err := filepath.Glob(filepath.Join(dir, "metrics-*.jsonl.gz"))
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+
+	gzFile, err := os.Open(backups[0])
+	require.NoError(t, err)
+	defer gzFile.Close()
+	gz, err := gzip.NewReader(gzFile)
+	require.NoError(t, err)
+	got, err = io.ReadAll(gz)
+	require.NoError(t, err)
+	assert.Equal(t, "one\ntwo\n", string(got))
+}
+
+func TestRotatingFileClosed(t *testing.T) {
+	f, err := newRotatingFile(logr.Discard(), fileConfig{Path: filepath.Join(t.TempDir(), "logs.jsonl")})
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	assert.Error(t, f.WriteLine([]byte("line")))
+}
+
+func TestRotatingFileRotateError(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "traces.jsonl")
+	var buf bytes.Buffer
+	f, err := newRotatingFile(buflogr.NewWithBuffer(&buf), fileConfig{Path: path, MaxSize: 6})
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, f.Close()) })
+
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	f.now = func() time.Time { return now }
+
+	// A non-empty directory at the backup path makes the rename fail.
+	backup := filepath.Join(dir, "traces-"+now.Format(backupTimeFormat)+".jsonl")
+	require.NoError(t, os.MkdirAll(filepath.Join(backup, "dir"), 0o750))
+
+	require.NoError(t, f.WriteLine([]byte("one")))
+	require.NoError(t, f.WriteLine([]byte("two")), "failed rotation")
+	assert.Contains(t, buf.String(), "failed to rotate file")
+
+	got, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "one\ntwo\n", string(got), "current file not written to")
+
+	// Rotation succeeds once the backup path is available.
+	require.NoError(t, os.RemoveAll(backup))
+	require.NoError(t, f.WriteLine([]byte("three")))
+	got, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "three\n", string(got))
+	got, err = os.ReadFile(backup)
+	require.NoError(t, err)
+	assert.Equal(t, "one\ntwo\n", string(got))
+}
+
+func TestRotatingFilePruneOnlyBackups(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "traces.jsonl")
+	unrelated := []string{
+		filepath.Join(dir, "traces-other.jsonl.bak"),
+		filepath.Join(dir, "traces-other.jsonl"),
+		filepath.Join(dir, "traces-20260102T030405.jsonl.gz"),
+	}
+	for _, name := range unrelated {
+		require.NoError(t, os.WriteFile(name, []byte("keep"), 0o600))
+	}
+
+	f, err := newRotatingFile(logr.Discard(), fileConfig{Path: path, MaxSize: 4, MaxBackups: 1})
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, f.Close()) })
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	f.now = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+
+	for _, line := range []string{"one", "two", "three"} {
+		require.NoError(t, f.WriteLine([]byte(line)))
+	}
+
+	backups, err := f.backups()
+	require.NoError(t, err)
+	require.Len(t, backups, 1, "retention not applied")
+	for _, name := range unrelated {
+		assert.FileExists(t, name, "unrelated file removed")
+	}
+}
@@ -17,7 +17,7 @@ package distro
 import (
 	"context"
 	"errors"
-	"os"
+	"fmt"
 	"strings"
 
 	"go.opentelemetry.io/contrib/instrumentation/runtime"
@@ -42,30 +42,85 @@ const splunkVerAttr = "splunk.distro.version"
 
 const distroName = "splunk-otel-go"
 
-const tracesSamplerKey = "OTEL_TRACES_SAMPLER"
+const (
+	tracesSamplerKey    = "OTEL_TRACES_SAMPLER"
+	tracesSamplerArgKey = "OTEL_TRACES_SAMPLER_ARG"
+)
 
 const noServiceWarn = `The service.name resource attribute is not set. Your service is unnamed and will be difficult to identify. Set your service name using the OTEL_SERVICE_NAME or OTEL_RESOURCE_ATTRIBUTES environment variable. For example, OTEL_SERVICE_NAME="<YOUR_SERVICE_NAME_HERE>".`
 
 // SDK is the Splunk distribution of the OpenTelemetry SDK.
 type SDK struct {
-	shutdownFuncs []shutdownFunc
+	pipelines []pipeline
+	config    EffectiveConfig
+	// diagnostics shuts down the diagnostics endpoint, if it is served.
+	diagnostics func(context.Context) error
+}
+
+// pipeline is the telemetry pipeline of a signal run by the SDK.
+type pipeline struct {
+	signal   string
+	flush    func(context.Context) error
+	shutdown func(context.Context) error
 }
 
-type shutdownFunc func(context.Context) error
+// SignalError is an error that occurred for a single telemetry signal.
+type SignalError struct {
+	// Signal is the name of the signal: "traces", "metrics", or "logs".
+	Signal string
+	// Err is the error that occurred.
+	Err error
+}
+
+// Error returns the error message prefixed with the signal name.
+func (e *SignalError) Error() string {
+	return e.Signal + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *SignalError) Unwrap() error {
+	return e.Err
+}
+
+// ForceFlush exports all telemetry that has not yet been exported.
+//
+// The returned error contains a *SignalError for each signal that failed to
+// be flushed.
+func (s SDK) ForceFlush(ctx context.Context) error {
+	var errs []error
+	for _, p := range s.pipelines {
+		if err := p.flush(ctx); err != nil {
+			errs = append(errs, &SignalError{Signal: p.signal, Err: err})
+		}
+	}
+	return errors.Join(errs...)
+}
 
 // Shutdown stops the SDK and releases any used resources.
+//
+// If any signal fails to shut down, the returned error contains a
+// *SignalError for each of them. A failure to shut down the diagnostics
+// endpoint is reported as its own error.
 func (s SDK) Shutdown(ctx context.Context) error {
-	var retErr error
-	// Calling shutdownFuncs sequentially for sake of simplicity.
-	for _, fn := range s.shutdownFuncs {
-		if err := fn(ctx); err != nil {
+	var errs []error
+	// Calling shutdown sequentially for sake of simplicity.
+	for _, p := range s.pipelines {
+		if err := p.shutdown(ctx); err != nil {
 			// Each error can have different cause therefore we are logging them via otel.Handle.
 			otel.Handle(err)
-			// We are returning a sentinel error when any shutdown error happens.
-			retErr = errShutdown
+			errs = append(errs, &SignalError{Signal: p.signal, Err: err})
 		}
 	}
-	return retErr
+	if s.diagnostics != nil {
+		if err := s.diagnostics(ctx); err != nil {
+			otel.Handle(err)
+			errs = append(errs, fmt.Errorf("diagnostics endpoint: %w", err))
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errors.Join(append([]error{errShutdown}, errs...)...)
 }
 
 // Run configures the default OpenTelemetry SDK and installs it globally.
@@ -73,13 +128,30 @@ func (s SDK) Shutdown(ctx context.Context) error {
 // It is the callers responsibility to shut down the returned SDK when
 // complete. This ensures all resources are released and all telemetry
 // flushed.
+//
+// If the OTEL_SDK_DISABLED environment variable is set to "true", a no-op SDK
+// is returned and nothing is installed globally.
 func Run(opts ...Option) (SDK, error) {
+	// Check before anything is configured so a disabled SDK has no overhead.
+	if sdkDisabled() {
+		return SDK{}, nil
+	}
+
 	ctx := context.Background()
 	c := newConfig(opts...)
+	if c.DiagnosticsEndpoint != "" {
+		c.diagnostics = newDiagnostics()
+	}
 
 	// Unify the SDK logging with OTel.
 	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(e error) {
+		if isRateLimitingSamplerError(e) {
+			return
+		}
 		c.Logger.Error(e, "OpenTelemetry error")
+		if c.diagnostics != nil {
+			c.diagnostics.handleError(e)
+		}
 	}))
 	otel.SetLogger(c.Logger)
 
@@ -94,33 +166,36 @@ func Run(opts ...Option) (SDK, error) {
 
 	otel.SetTextMapPropagator(c.Propagator)
 
-	sdk := SDK{}
-
-	shutdownFn, err := runTraces(c, res)
-	if err != nil {
-		sdk.Shutdown(ctx) //nolint:errcheck // the Shutdown errors are logged
-		return SDK{}, err
-	}
-	if shutdownFn != nil {
-		sdk.shutdownFuncs = append(sdk.shutdownFuncs, shutdownFn)
-	}
-
-	shutdownFn, err = runMetrics(c, res)
-	if err != nil {
-		sdk.Shutdown(ctx) //nolint:errcheck // the Shutdown errors are logged
-		return SDK{}, err
-	}
-	if shutdownFn != nil {
-		sdk.shutdownFuncs = append(sdk.shutdownFuncs, shutdownFn)
+	var sdk SDK
+	for _, run := range []func(*config, *resource.Resource) (*pipeline, error){
+		runTraces,
+		runMetrics,
+		runLogs,
+	} {
+		p, err := run(c, res)
+		if err != nil {
+			sdk.Shutdown(ctx) //nolint:errcheck // the Shutdown errors are logged
+			return SDK{}, err
+		}
+		if p != nil {
+			sdk.pipelines = append(sdk.pipelines, *p)
+		}
 	}
 
-	shutdownFn, err = runLogs(c, res)
-	if err != nil {
-		sdk.Shutdown(ctx) //nolint:errcheck // the Shutdown errors are logged
-		return SDK{}, err
-	}
-	if shutdownFn != nil {
-		sdk.shutdownFuncs = append(sdk.shutdownFuncs, shutdownFn)
+	// The exporters record their configuration when created.
+	sdk.config = describe(c)
+	c.Logger.V(2).Info("Effective configuration", sdk.config.keysAndValues()...)
+
+	if c.diagnostics != nil {
+		c.diagnostics.config = sdk.config
+		c.diagnostics.resource = res
+		shutdown, err := c.diagnostics.start(c.Logger, c.DiagnosticsEndpoint)
+		if err != nil {
+			// Diagnostics are a debugging aid, do not fail the application.
+			c.Logger.Error(err, "diagnostics disabled")
+		} else {
+			sdk.diagnostics = shutdown
+		}
 	}
 
 	return sdk, nil
@@ -159,7 +234,7 @@ func newResource(ctx context.Context) (*resource.Resource, error) {
 	return res, nil
 }
 
-func runTraces(c *config, res *resource.Resource) (shutdownFunc, error) {
+func runTraces(c *config, res *resource.Resource) (*pipeline, error) {
 	if c.TracesExporterFunc == nil {
 		c.Logger.V(1).Info("OTEL_TRACES_EXPORTER set to none: Tracing disabled")
 		// "none" exporter configured.
@@ -173,20 +248,45 @@ func runTraces(c *config, res *resource.Resource) (shutdownFunc, error) {
 	o := []trace.TracerProviderOption{
 		trace.WithResource(res),
 		trace.WithRawSpanLimits(*c.SpanLimits),
-		trace.WithSpanProcessor(trace.NewBatchSpanProcessor(exp)),
 		trace.WithIDGenerator(c.IDGenerator),
 	}
-	if _, ok := os.LookupEnv(tracesSamplerKey); !ok {
-		o = append(o, trace.WithSampler(trace.AlwaysSample()))
+	if c.diagnostics != nil {
+		exp = healthSpanExporter{SpanExporter: exp, h: &c.diagnostics.traces}
+		// Count the spans before they are queued.
+		o = append(o, trace.WithSpanProcessor(c.diagnostics.spans))
+	}
+	o = append(o, trace.WithSpanProcessor(trace.NewBatchSpanProcessor(exp)))
+	if conf := baggageAttributesConfig(c); conf != nil {
+		f := newBaggageFilter(c.Logger, *conf)
+		o = append(o, trace.WithSpanProcessor(baggageSpanProcessor{filter: f}))
+	}
+	if len(c.ContextAttributes) > 0 {
+		o = append(o, trace.WithSpanProcessor(contextAttributesSpanProcessor{funcs: c.ContextAttributes}))
+	}
+
+	if conf := redMetricsConfig(c); conf != nil {
+		// The global MeterProvider is resolved when the first span ends, once
+		// the metrics pipeline is running.
+		red := newREDMetricsProcessor(c.Logger, otel.GetMeterProvider, res, *conf)
+		o = append(o, trace.WithSpanProcessor(red))
+		// The dropped spans are recorded for the RED metrics. The batch span
+		// processor only exports the sampled ones.
+		o = append(o, trace.WithSampler(recordingSampler{Sampler: c.Sampler}))
+	} else {
+		o = append(o, trace.WithSampler(c.Sampler))
 	}
 
 	traceProvider := trace.NewTracerProvider(o...)
 	otel.SetTracerProvider(traceProvider)
 
-	return traceProvider.Shutdown, nil
+	return &pipeline{
+		signal:   "traces",
+		flush:    traceProvider.ForceFlush,
+		shutdown: traceProvider.Shutdown,
+	}, nil
 }
 
-func runMetrics(c *config, res *resource.Resource) (shutdownFunc, error) {
+func runMetrics(c *config, res *resource.Resource) (*pipeline, error) {
 	if c.MetricsExporterFunc == nil {
 		c.Logger.V(1).Info("OTEL_METRICS_EXPORTER set to none: Metrics disabled")
 		// "none" exporter configured.
@@ -197,24 +297,38 @@ func runMetrics(c *config, res *resource.Resource) (shutdownFunc, error) {
 	if err != nil {
 		return nil, err
 	}
+	limits := newCardinalityLimits(c)
+	exp = newCardinalityLimitExporter(exp, limits)
+	if c.diagnostics != nil {
+		exp = healthMetricExporter{Exporter: exp, h: &c.diagnostics.metrics}
+	}
 
 	o := []metric.Option{
 		metric.WithResource(res),
 		metric.WithReader(metric.NewPeriodicReader(exp)),
+		limits.sdkOption(),
 	}
 
 	provider := metric.NewMeterProvider(o...)
 	otel.SetMeterProvider(provider)
 
 	// Add runtime metrics instrumentation.
-	if err := runtime.Start(); err != nil {
-		return nil, err
+	if c.RuntimeMetricsEnabled {
+		if err := runtime.Start(); err != nil {
+			return nil, err
+		}
+	} else {
+		c.Logger.V(1).Info("SPLUNK_RUNTIME_METRICS_ENABLED set to false: Runtime metrics disabled")
 	}
 
-	return provider.Shutdown, nil
+	return &pipeline{
+		signal:   "metrics",
+		flush:    provider.ForceFlush,
+		shutdown: provider.Shutdown,
+	}, nil
 }
 
-func runLogs(c *config, res *resource.Resource) (shutdownFunc, error) {
+func runLogs(c *config, res *resource.Resource) (*pipeline, error) {
 	if c.LogsExporterFunc == nil {
 		c.Logger.V(1).Info("OTEL_LOGS_EXPORTER set to none: Logs disabled")
 		// "none" exporter configured.
@@ -226,15 +340,32 @@ func runLogs(c *config, res *resource.Resource) (shutdownFunc, error) {
 		return nil, err
 	}
 
-	o := []log.LoggerProviderOption{
+	var o []log.LoggerProviderOption
+	if c.diagnostics != nil {
+		exp = healthLogExporter{Exporter: exp, h: &c.diagnostics.logsHealth}
+		// Count the records before they are queued.
+		o = append(o, log.WithProcessor(c.diagnostics.logs))
+	}
+	if conf := baggageAttributesConfig(c); conf != nil {
+		f := newBaggageFilter(c.Logger, *conf)
+		o = append(o, log.WithProcessor(baggageLogProcessor{filter: f}))
+	}
+	if len(c.ContextAttributes) > 0 {
+		o = append(o, log.WithProcessor(contextAttributesLogProcessor{funcs: c.ContextAttributes}))
+	}
+	o = append(o,
 		log.WithResource(res),
 		log.WithProcessor(log.NewBatchProcessor(exp)),
-	}
+	)
 
 	provider := log.NewLoggerProvider(o...)
 	global.SetLoggerProvider(provider)
 
-	return provider.Shutdown, nil
+	return &pipeline{
+		signal:   "logs",
+		flush:    provider.ForceFlush,
+		shutdown: provider.Shutdown,
+	}, nil
 }
 
 func serviceNameDefined(r *resource.Resource) bool {
@@ -19,10 +19,14 @@ import (
 	"context"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"io"
 	"net"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"sync"
 	"testing"
 
@@ -170,6 +174,49 @@ func TestRunJaegerExporterDefault(t *testing.T) {
 	assert.Equal(t, "application/x-thrift", got.Header.Get("Content-type"))
 }
 
+func TestRunZipkinExporter(t *testing.T) {
+	type request struct {
+		header http.Header
+		body   []byte
+	}
+	reqCh := make(chan request, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		reqCh <- request{header: r.Header, body: body}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	t.Cleanup(srv.Close)
+
+	t.Setenv("OTEL_TRACES_EXPORTER", "zipkin")
+	t.Setenv("OTEL_EXPORTER_ZIPKIN_ENDPOINT", srv.URL+"/api/v2/spans")
+	t.Setenv("SPLUNK_ACCESS_TOKEN", token)
+
+	emitSpan(t)
+
+	got := <-reqCh
+	assert.Equal(t, "application/json", got.header.Get("Content-Type"))
+	assert.Equal(t, []string{token}, got.header["X-Sf-Token"])
+	assert.Contains(t, string(got.body), spanName)
+}
+
+func TestRunZipkinExporterTLS(t *testing.T) {
+	reqCh, hFunc := reqHander()
+	srv := httptest.NewUnstartedServer(hFunc)
+	t.Cleanup(srv.Close)
+	srv.TLS = serverTLSConfig(t)
+	srv.StartTLS()
+
+	t.Setenv("OTEL_TRACES_EXPORTER", "zipkin")
+	t.Setenv("OTEL_EXPORTER_ZIPKIN_ENDPOINT", srv.URL+"/api/v2/spans")
+
+	emitSpan(t, distro.WithTLSConfig(clientTLSConfig(t)))
+
+	got := <-reqCh
+	assert.Equal(t, "application/json", got.Header.Get("Content-Type"))
+	assert.True(t, got.TLS.HandshakeComplete, "did not perform TLS exchange")
+}
+
 func TestRunOTLPHTTPProtobufExporter(t *testing.T) {
 	assertBase := func(t *testing.T, req *http.Request) {
 		assert.Equal(t, "application/x-protobuf", req.Header.Get("Content-Type"))
@@ -376,6 +423,160 @@ func TestWithIDGenerator(t *testing.T) {
 	assert.Contains(t, string(got.Spans[0].SpanId), "testspan")
 }
 
+func TestRunOTLPHTTPProtobufExporterTokenProvider(t *testing.T) {
+	reqCh, handler := reqHander()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", srv.URL)
+	t.Setenv("OTEL_EXPORTER_OTLP_PROTOCOL", "http/protobuf")
+	t.Setenv("OTEL_TRACES_EXPORTER", "otlp")
+	t.Setenv("SPLUNK_ACCESS_TOKEN", "static token")
+
+	provider := distro.TokenProviderFunc(func(context.Context) (string, error) {
+		return token, nil
+	})
+	emitSpan(t, distro.WithAccessTokenProvider(provider))
+
+	got := <-reqCh
+	assert.Equal(t, []string{token}, got.Header["X-Sf-Token"])
+}
+
+func TestRunOTLPHTTPProtobufExporterTokenProviderCertificateEnv(t *testing.T) {
+	reqCh, handler := reqHander()
+	srv := httptest.NewTLSServer(handler)
+	t.Cleanup(srv.Close)
+
+	// The exporter client replaced to send the token needs to use the
+	// certificate configured for the exporter.
+	certPath := filepath.Join(t.TempDir(), "cert.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	require.NoError(t, os.WriteFile(certPath, certPEM, 0o600))
+
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", srv.URL)
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_CERTIFICATE", certPath)
+	t.Setenv("OTEL_EXPORTER_OTLP_PROTOCOL", "http/protobuf")
+	t.Setenv("OTEL_TRACES_EXPORTER", "otlp")
+
+	provider := distro.TokenProviderFunc(func(context.Context) (string, error) {
+		return token, nil
+	})
+	emitSpan(t, distro.WithAccessTokenProvider(provider))
+
+	got := <-reqCh
+	assert.Equal(t, []string{token}, got.Header["X-Sf-Token"])
+	assert.True(t, got.TLS.HandshakeComplete, "did not perform TLS exchange")
+}
+
+func TestRunOTLPGRPCTracesExporterTokenFile(t *testing.T) {
+	coll := &collector{}
+	coll.Start(t)
+
+	path := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(path, []byte(token+"\n"), 0o600))
+
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://"+coll.Endpoint)
+	t.Setenv("OTEL_TRACES_EXPORTER", "otlp")
+	t.Setenv("SPLUNK_ACCESS_TOKEN_FILE", path)
+
+	emitSpan(t)
+
+	got := coll.ExportedSpans()
+	assertHasSpan(t, got)
+	assert.Equal(t, []string{token}, got.Header.Get("x-sf-token"))
+}
+
+func TestRunJaegerExporterTokenFile(t *testing.T) {
+	reqCh, hFunc := reqHander()
+	srv := httptest.NewServer(hFunc)
+	t.Cleanup(srv.Close)
+
+	path := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(path, []byte(token), 0o600))
+
+	t.Setenv("OTEL_EXPORTER_JAEGER_ENDPOINT", srv.URL)
+	t.Setenv("OTEL_TRACES_EXPORTER", "jaeger-thrift-splunk")
+
+	emitSpan(t, distro.WithAccessTokenFile(path))
+
+	user, pass, ok := (<-reqCh).BasicAuth()
+	require.True(t, ok, "should have Basic Authentication headers")
+	assert.Equal(t, "auth", user)
+	assert.Equal(t, token, pass)
+}
+
+func TestRunOTLPHTTPJSONExporter(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		exporter string
+		emitFn   func(*testing.T, ...distro.Option)
+		want     string
+	}{
+		{desc: "traces", exporter: "OTEL_TRACES_EXPORTER", emitFn: emitSpan, want: spanName},
+		{desc: "metrics", exporter: "OTEL_METRICS_EXPORTER", emitFn: emitMetric, want: metricName},
+		{desc: "logs", exporter: "OTEL_LOGS_EXPORTER", emitFn: emitLogs, want: logBody},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			type request struct {
+				header http.Header
+				body   map[string]any
+			}
+			reqCh := make(chan request, 1)
+			srv := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				var body map[string]any
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+				reqCh <- request{header: r.Header, body: body}
+			}))
+			t.Cleanup(srv.Close)
+
+			t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", srv.URL)
+			t.Setenv("OTEL_EXPORTER_OTLP_PROTOCOL", "http/json")
+			t.Setenv("SPLUNK_ACCESS_TOKEN", token)
+			t.Setenv(tc.exporter, "otlp")
+
+			tc.emitFn(t)
+
+			got := <-reqCh
+			assert.Equal(t, "application/json", got.header.Get("Content-Type"))
+			assert.Equal(t, []string{token}, got.header["X-Sf-Token"])
+			b, err := json.Marshal(got.body)
+			require.NoError(t, err)
+			assert.Contains(t, string(b), tc.want)
+		})
+	}
+}
+
+func TestRunFileExporter(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		exporter string
+		pathKey  string
+		emitFn   func(*testing.T, ...distro.Option)
+		want     string
+	}{
+		{desc: "traces", exporter: "OTEL_TRACES_EXPORTER", pathKey: "SPLUNK_FILE_EXPORTER_TRACES_PATH", emitFn: emitSpan, want: `"resourceSpans"`},
+		{desc: "metrics", exporter: "OTEL_METRICS_EXPORTER", pathKey: "SPLUNK_FILE_EXPORTER_METRICS_PATH", emitFn: emitMetric, want: `"resourceMetrics"`},
+		{desc: "logs", exporter: "OTEL_LOGS_EXPORTER", pathKey: "SPLUNK_FILE_EXPORTER_LOGS_PATH", emitFn: emitLogs, want: `"resourceLogs"`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "telemetry.jsonl")
+			t.Setenv(tc.exporter, "file")
+			t.Setenv(tc.pathKey, path)
+
+			tc.emitFn(t)
+
+			b, err := os.ReadFile(path)
+			require.NoError(t, err)
+			lines := bytes.Split(bytes.TrimSpace(b), []byte("\n"))
+			require.Len(t, lines, 1)
+			assert.True(t, json.Valid(lines[0]), "invalid JSON line: %s", lines[0])
+			assert.Contains(t, string(lines[0]), tc.want)
+		})
+	}
+}
+
 func TestRunOTLPHTTPProtobufMetricsExporter(t *testing.T) {
 	assertBase := func(t *testing.T, req *http.Request) {
 		assert.Equal(t, "application/x-protobuf", req.Header.Get("Content-Type"))
@@ -590,6 +791,51 @@ func TestRuntimeMetrics(t *testing.T) {
 	assertHasMetric(t, got, "go.memory.allocations") // New metric.
 }
 
+func TestRuntimeMetricsDisabled(t *testing.T) {
+	coll := &collector{}
+	coll.Start(t)
+	t.Setenv("OTEL_METRICS_EXPORTER", "otlp")
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://"+coll.Endpoint)
+	t.Setenv("SPLUNK_RUNTIME_METRICS_ENABLED", "false")
+
+	emitMetric(t)
+
+	got := coll.ExportedMetrics()
+	assertHasMetric(t, got, metricName)
+	for _, m := range got.Metrics {
+		assert.NotEqual(t, "go.memory.allocations", m.Name, "runtime metrics not disabled")
+	}
+}
+
+func TestSDKDisabled(t *testing.T) {
+	coll := &collector{}
+	coll.Start(t)
+	t.Setenv("OTEL_SDK_DISABLED", "TRUE")
+	t.Setenv("OTEL_TRACES_EXPORTER", "otlp")
+	t.Setenv("OTEL_METRICS_EXPORTER", "otlp")
+	t.Setenv("OTEL_LOGS_EXPORTER", "otlp")
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://"+coll.Endpoint)
+
+	emitSpan(t)
+	emitMetric(t)
+	emitLogs(t)
+
+	assert.Nil(t, coll.ExportedSpans())
+	assert.Nil(t, coll.ExportedMetrics())
+	assert.Nil(t, coll.ExportedLogs())
+
+	allocs := testing.AllocsPerRun(10, func() {
+		sdk, err := distro.Run()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := sdk.Shutdown(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	})
+	assert.Zero(t, allocs, "disabled SDK allocated")
+}
+
 func TestMetricsResource(t *testing.T) {
 	coll := &collector{}
 	coll.Start(t)
@@ -777,6 +1023,21 @@ func TestJaegerThriftSplunkWarn(t *testing.T) {
 	assert.Contains(t, buf.String(), `INFO OTEL_TRACES_EXPORTER=jaeger-thrift-splunk is deprecated and may be removed in a future release. Use the default OTLP exporter instead, or set the SPLUNK_REALM and SPLUNK_ACCESS_TOKEN environment variables to send telemetry directly to Splunk Observability Cloud.`)
 }
 
+func TestRateLimitingSamplerNoSDKError(t *testing.T) {
+	t.Setenv("OTEL_TRACES_SAMPLER", "ratelimiting")
+	t.Setenv("OTEL_TRACES_EXPORTER", "otlp")
+	t.Setenv("OTEL_METRICS_EXPORTER", "none")
+	t.Setenv("OTEL_LOGS_EXPORTER", "none")
+
+	var buf bytes.Buffer
+	sdk, err := distro.Run(distro.WithLogger(buflogr.NewWithBuffer(&buf)))
+
+	require.NoError(t, err)
+	require.NoError(t, sdk.Shutdown(context.Background()))
+	assert.NotContains(t, buf.String(), "unsupported sampler")
+	assert.NotContains(t, buf.String(), "ERROR")
+}
+
 // setenv sets the value of the environment variable named by the key.
 // It returns a function that rollbacks the setting.
 func setenv(key, val string) func() {
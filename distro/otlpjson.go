@@ -0,0 +1,248 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distro
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	clpb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	cmpb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	ctpb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	contentTypeProtobuf = "application/x-protobuf"
+	contentTypeJSON     = "application/json"
+)
+
+// otlpMessages creates the OTLP export request and response messages of a
+// signal.
+type otlpMessages struct {
+	request  func() proto.Message
+	response func() proto.Message
+}
+
+var (
+	tracesMessages = otlpMessages{
+		request:  func() proto.Message { return &ctpb.ExportTraceServiceRequest{} },
+		response: func() proto.Message { return &ctpb.ExportTraceServiceResponse{} },
+	}
+	metricsMessages = otlpMessages{
+		request:  func() proto.Message { return &cmpb.ExportMetricsServiceRequest{} },
+		response: func() proto.Message { return &cmpb.ExportMetricsServiceResponse{} },
+	}
+	logsMessages = otlpMessages{
+		request:  func() proto.Message { return &clpb.ExportLogsServiceRequest{} },
+		response: func() proto.Message { return &clpb.ExportLogsServiceResponse{} },
+	}
+)
+
+// otlpJSONIDKeys are the JSON keys of the fields the OTLP/JSON encoding
+// requires to be hex strings instead of the base64 protobuf JSON mapping.
+var otlpJSONIDKeys = map[string]bool{
+	"traceId":      true,
+	"spanId":       true,
+	"parentSpanId": true,
+}
+
+// marshalOTLPJSON returns the OTLP/JSON encoding of msg.
+//
+// This is the Protobuf JSON mapping with enum values encoded as integers and
+// trace and span IDs encoded as hex strings.
+func marshalOTLPJSON(msg proto.Message) ([]byte, error) {
+	b, err := protojson.MarshalOptions{UseEnumNumbers: true}.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	// Do not lose the precision of any number.
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if err := hexIDs(v); err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+// hexIDs re-encodes all base64 encoded IDs in v as hex strings.
+func hexIDs(v any) error {
+	switch val := v.(type) {
+	case map[string]any:
+		for k, elem := range val {
+			if s, ok := elem.(string); ok && otlpJSONIDKeys[k] {
+				id, err := base64.StdEncoding.DecodeString(s)
+				if err != nil {
+					return fmt.Errorf("invalid %s: %w", k, err)
+				}
+				val[k] = hex.EncodeToString(id)
+				continue
+			}
+			if err := hexIDs(elem); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for _, elem := range val {
+			if err := hexIDs(elem); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// jsonTransport is an http.RoundTripper that transcodes OTLP/protobuf export
+// requests to OTLP/JSON and their OTLP/JSON responses back to protobuf.
+//
+// This allows the OTLP HTTP exporters, which only support protobuf, to be
+// used for the http/json protocol. Requests already encoded as OTLP/JSON, e.g.
+// by a splitTransport, are sent as is.
+type jsonTransport struct {
+	base     http.RoundTripper
+	messages otlpMessages
+}
+
+func newJSONTransport(m otlpMessages) func(http.RoundTripper) http.RoundTripper {
+	return func(base http.RoundTripper) http.RoundTripper {
+		return &jsonTransport{base: base, messages: m}
+	}
+}
+
+// RoundTrip sends req encoded as OTLP/JSON.
+func (t *jsonTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if hasContentType(req.Header, contentTypeJSON) {
+		return t.send(req)
+	}
+
+	gzipped := req.Header.Get("Content-Encoding") == "gzip"
+	body, err := readBody(req.Body, gzipped)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read OTLP request: %w", err)
+	}
+
+	msg := t.messages.request()
+	if err := proto.Unmarshal(body, msg); err != nil {
+		return nil, fmt.Errorf("failed to decode OTLP request: %w", err)
+	}
+	body, err = marshalOTLPJSON(msg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode OTLP/JSON request: %w", err)
+	}
+	if gzipped {
+		if body, err = gzipBytes(body); err != nil {
+			return nil, err
+		}
+	}
+
+	// RoundTrip must not modify the original request.
+	req = req.Clone(req.Context())
+	req.Header.Set("Content-Type", contentTypeJSON)
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	req.ContentLength = int64(len(body))
+	return t.send(req)
+}
+
+// send sends the OTLP/JSON request req.
+func (t *jsonTransport) send(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp, err
+	}
+	return t.protobufResponse(resp)
+}
+
+// protobufResponse transcodes a successful OTLP/JSON response to protobuf so
+// the exporter can handle partial success responses.
+func (t *jsonTransport) protobufResponse(resp *http.Response) (*http.Response, error) {
+	if !hasContentType(resp.Header, contentTypeJSON) {
+		return resp, nil
+	}
+
+	b, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(b) > 0 {
+		msg := t.messages.response()
+		if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(b, msg); err != nil {
+			return nil, fmt.Errorf("failed to decode OTLP/JSON response: %w", err)
+		}
+		if b, err = proto.Marshal(msg); err != nil {
+			return nil, err
+		}
+	}
+
+	resp.Header.Set("Content-Type", contentTypeProtobuf)
+	resp.Header.Set("Content-Length", strconv.Itoa(len(b)))
+	resp.ContentLength = int64(len(b))
+	resp.Body = io.NopCloser(bytes.NewReader(b))
+	return resp, nil
+}
+
+// hasContentType returns if the media type of the Content-Type header of h is
+// mediaType, ignoring its parameters, e.g. charset=utf-8.
+func hasContentType(h http.Header, mediaType string) bool {
+	got, _, err := mime.ParseMediaType(h.Get("Content-Type"))
+	return err == nil && got == mediaType
+}
+
+func readBody(r io.ReadCloser, gzipped bool) ([]byte, error) {
+	if r == nil {
+		return nil, nil
+	}
+	defer r.Close()
+
+	if !gzipped {
+		return io.ReadAll(r)
+	}
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+	return io.ReadAll(gz)
+}
+
+func gzipBytes(b []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write(b); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,129 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distro
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ctpb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tpb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+func testTraceRequest() *ctpb.ExportTraceServiceRequest {
+	return &ctpb.ExportTraceServiceRequest{
+		ResourceSpans: []*tpb.ResourceSpans{{
+			ScopeSpans: []*tpb.ScopeSpans{{
+				Spans: []*tpb.Span{{
+					TraceId:           []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f, 0x10},
+					SpanId:            []byte{0xa1, 0xa2, 0xa3, 0xa4, 0xa5, 0xa6, 0xa7, 0xa8},
+					ParentSpanId:      []byte{0xb1, 0xb2, 0xb3, 0xb4, 0xb5, 0xb6, 0xb7, 0xb8},
+					Name:              "span",
+					Kind:              tpb.Span_SPAN_KIND_SERVER,
+					StartTimeUnixNano: 1544712660000000000,
+					Status:            &tpb.Status{Code: tpb.Status_STATUS_CODE_ERROR},
+				}},
+			}},
+		}},
+	}
+}
+
+func TestMarshalOTLPJSON(t *testing.T) {
+	got, err := marshalOTLPJSON(testTraceRequest())
+	require.NoError(t, err)
+
+	want := `{"resourceSpans":[{"scopeSpans":[{"spans":[{` +
+		`"kind":2,` +
+		`"name":"span",` +
+		`"parentSpanId":"b1b2b3b4b5b6b7b8",` +
+		`"spanId":"a1a2a3a4a5a6a7a8",` +
+		`"startTimeUnixNano":"1544712660000000000",` +
+		`"status":{"code":2},` +
+		`"traceId":"0102030405060708090a0b0c0d0e0f10"` +
+		`}]}]}]}`
+	assert.JSONEq(t, want, string(got))
+}
+
+func TestJSONTransport(t *testing.T) {
+	for _, gzipped := range []bool{false, true} {
+		t.Run(map[bool]string{false: "identity", true: "gzip"}[gzipped], func(t *testing.T) {
+			var gotHeader http.Header
+			var gotBody []byte
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotHeader = r.Header
+				var err error
+				gotBody, err = readBody(r.Body, r.Header.Get("Content-Encoding") == "gzip")
+				assert.NoError(t, err)
+
+				w.Header().Set("Content-Type", "application/json; charset=utf-8")
+				_, _ = w.Write([]byte(`{"partialSuccess":{"rejectedSpans":"1","errorMessage":"dropped"}}`))
+			}))
+			t.Cleanup(srv.Close)
+
+			body, err := proto.Marshal(testTraceRequest())
+			require.NoError(t, err)
+			if gzipped {
+				body, err = gzipBytes(body)
+				require.NoError(t, err)
+			}
+
+			req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, srv.URL, bytes.NewReader(body))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", contentTypeProtobuf)
+			if gzipped {
+				req.Header.Set("Content-Encoding", "gzip")
+			}
+
+			client := exporterHTTPClient(&exporterConfig{}, newJSONTransport(tracesMessages))
+			resp, err := client.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, contentTypeJSON, gotHeader.Get("Content-Type"))
+			assert.Contains(t, string(gotBody), `"traceId":"0102030405060708090a0b0c0d0e0f10"`)
+
+			// The response is transcoded back to protobuf.
+			assert.Equal(t, contentTypeProtobuf, resp.Header.Get("Content-Type"))
+			b, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			var msg ctpb.ExportTraceServiceResponse
+			require.NoError(t, proto.Unmarshal(b, &msg))
+			assert.Equal(t, int64(1), msg.GetPartialSuccess().GetRejectedSpans())
+			assert.Equal(t, "dropped", msg.GetPartialSuccess().GetErrorMessage())
+		})
+	}
+}
+
+func TestHasContentType(t *testing.T) {
+	tests := map[string]bool{
+		"application/json":                true,
+		"application/json; charset=utf-8": true,
+		"Application/JSON":                true,
+		"application/x-protobuf":          false,
+		"application/jsonl":               false,
+		"":                                false,
+	}
+	for contentType, want := range tests {
+		h := http.Header{"Content-Type": []string{contentType}}
+		assert.Equal(t, want, hasContentType(h, contentTypeJSON), contentType)
+	}
+}
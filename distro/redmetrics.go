@@ -0,0 +1,250 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distro
+
+import (
+	"context"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+const (
+	// redMetricsEnabledKey enables the metrics derived from spans.
+	redMetricsEnabledKey = "SPLUNK_RED_METRICS_ENABLED"
+	// redMetricsDimensionsKey is the comma-separated list of span attributes
+	// added to the metrics derived from spans.
+	redMetricsDimensionsKey = "SPLUNK_RED_METRICS_DIMENSIONS"
+	// redMetricsCardinalityLimitKey is the maximum number of attribute sets
+	// of the metrics derived from spans.
+	redMetricsCardinalityLimitKey = "SPLUNK_RED_METRICS_CARDINALITY_LIMIT"
+
+	defaultREDMetricsCardinalityLimit = 1000
+)
+
+// overflowAttr is the attribute of the series measurements are folded into
+// when a cardinality limit is reached.
+var overflowAttr = attribute.Bool("otel.metric.overflow", true)
+
+// durationBoundaries are the histogram bucket boundaries, in seconds, of the
+// duration metric.
+var durationBoundaries = []float64{
+	0.005, 0.01, 0.025, 0.05, 0.075, 0.1, 0.25, 0.5, 0.75, 1, 2.5, 5, 7.5, 10,
+}
+
+// REDMetricsConfig configures the request rate, error, and duration (RED)
+// metrics derived from spans.
+type REDMetricsConfig struct {
+	// Dimensions are the span attributes added to the metrics when the span
+	// has them, e.g. "http.route" or "db.system".
+	Dimensions []attribute.Key
+	// CardinalityLimit is the maximum number of distinct attribute sets
+	// recorded. Spans with new attribute sets above the limit are recorded
+	// with only the otel.metric.overflow attribute set to true. If zero, a
+	// limit of 1000 is used.
+	CardinalityLimit int
+}
+
+// WithREDMetrics configures the distro to derive request rate, error, and
+// duration metrics from the ended spans. The "calls" counter and "duration"
+// histogram are recorded with the service.name, span.name, span.kind, and
+// status.code attributes, and the configured dimensions. They are exported
+// with the other metrics of the distro.
+//
+// The metrics are derived from all the spans, before sampling: the spans the
+// sampler drops are recorded, without being sampled, so they are counted in
+// "calls" and measured in "duration" but not exported. This has the overhead
+// of recording every span.
+//
+// This option takes precedence over the SPLUNK_RED_METRICS_ENABLED,
+// SPLUNK_RED_METRICS_DIMENSIONS, and SPLUNK_RED_METRICS_CARDINALITY_LIMIT
+// environment variables.
+func WithREDMetrics(conf REDMetricsConfig) Option {
+	return optionFunc(func(c *config) {
+		c.REDMetrics = &conf
+	})
+}
+
+// redMetricsConfig returns the RED metrics configuration of c, or nil if the
+// RED metrics are disabled.
+func redMetricsConfig(c *config) *REDMetricsConfig {
+	if c.REDMetrics != nil {
+		return c.REDMetrics
+	}
+	if !strings.EqualFold(os.Getenv(redMetricsEnabledKey), "true") {
+		return nil
+	}
+
+	conf := &REDMetricsConfig{
+		CardinalityLimit: envInt(c.Logger, redMetricsCardinalityLimitKey, defaultREDMetricsCardinalityLimit),
+	}
+	for _, d := range strings.Split(os.Getenv(redMetricsDimensionsKey), ",") {
+		if d = strings.TrimSpace(d); d != "" {
+			conf.Dimensions = append(conf.Dimensions, attribute.Key(d))
+		}
+	}
+	return conf
+}
+
+// recordingSampler records the spans its Sampler drops, without sampling
+// them, so the RED metrics are derived from all the spans. Only the sampled
+// spans are exported.
+type recordingSampler struct {
+	trace.Sampler
+}
+
+func (s recordingSampler) ShouldSample(p trace.SamplingParameters) trace.SamplingResult {
+	res := s.Sampler.ShouldSample(p)
+	if res.Decision == trace.Drop {
+		res.Decision = trace.RecordOnly
+	}
+	return res
+}
+
+// redMetricsProcessor records RED metrics of ended spans.
+type redMetricsProcessor struct {
+	service    attribute.KeyValue
+	dimensions []attribute.Key
+	limit      int
+
+	log           logr.Logger
+	meterProvider func() metric.MeterProvider
+	initOnce      sync.Once
+	calls         metric.Float64Counter
+	duration      metric.Float64Histogram
+
+	mu   sync.Mutex
+	sets map[attribute.Distinct]struct{}
+}
+
+var _ trace.SpanProcessor = (*redMetricsProcessor)(nil)
+
+// newREDMetricsProcessor returns a processor recording the RED metrics with
+// the MeterProvider returned by mp when the first span ends.
+func newREDMetricsProcessor(l logr.Logger, mp func() metric.MeterProvider, res *resource.Resource, conf REDMetricsConfig) *redMetricsProcessor {
+	p := &redMetricsProcessor{
+		dimensions:    conf.Dimensions,
+		limit:         conf.CardinalityLimit,
+		log:           l,
+		meterProvider: mp,
+		sets:          make(map[attribute.Distinct]struct{}),
+	}
+	if p.limit <= 0 {
+		p.limit = defaultREDMetricsCardinalityLimit
+	}
+	if v, ok := res.Set().Value(semconv.ServiceNameKey); ok {
+		p.service = semconv.ServiceNameKey.String(v.Emit())
+	}
+
+	return p
+}
+
+func (p *redMetricsProcessor) init() {
+	meter := p.meterProvider().Meter(
+		"github.com/signalfx/splunk-otel-go/distro",
+		metric.WithInstrumentationVersion(Version()),
+	)
+
+	var err error
+	p.calls, err = meter.Float64Counter(
+		"calls",
+		metric.WithDescription("Number of spans ended."),
+		metric.WithUnit("{call}"),
+	)
+	if err != nil {
+		p.log.Error(err, "failed to create RED metrics calls counter")
+	}
+	p.duration, err = meter.Float64Histogram(
+		"duration",
+		metric.WithDescription("Duration of the spans ended."),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(durationBoundaries...),
+	)
+	if err != nil {
+		p.log.Error(err, "failed to create RED metrics duration histogram")
+	}
+}
+
+func (*redMetricsProcessor) OnStart(context.Context, trace.ReadWriteSpan) {}
+
+func (p *redMetricsProcessor) OnEnd(s trace.ReadOnlySpan) {
+	p.initOnce.Do(p.init)
+
+	attrs := make([]attribute.KeyValue, 0, 4+len(p.dimensions))
+	if p.service.Valid() {
+		attrs = append(attrs, p.service)
+	}
+	attrs = append(attrs,
+		attribute.String("span.name", s.Name()),
+		attribute.String("span.kind", "SPAN_KIND_"+strings.ToUpper(s.SpanKind().String())),
+		attribute.String("status.code", statusCode(s.Status().Code)),
+	)
+	for _, d := range p.dimensions {
+		for _, kv := range s.Attributes() {
+			if kv.Key == d {
+				attrs = append(attrs, kv)
+				break
+			}
+		}
+	}
+
+	opt := metric.WithAttributeSet(p.limitCardinality(attribute.NewSet(attrs...)))
+	ctx := context.Background()
+	if p.calls != nil {
+		p.calls.Add(ctx, 1, opt)
+	}
+	if p.duration != nil {
+		p.duration.Record(ctx, s.EndTime().Sub(s.StartTime()).Seconds(), opt)
+	}
+}
+
+// limitCardinality returns set if it is already recorded or the cardinality
+// limit is not reached, otherwise it returns the overflow set.
+func (p *redMetricsProcessor) limitCardinality(set attribute.Set) attribute.Set {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	key := set.Equivalent()
+	if _, ok := p.sets[key]; ok {
+		return set
+	}
+	// Reserve one series for the overflow.
+	if len(p.sets) >= p.limit-1 {
+		return attribute.NewSet(overflowAttr)
+	}
+	p.sets[key] = struct{}{}
+	return set
+}
+
+func (*redMetricsProcessor) Shutdown(context.Context) error   { return nil }
+func (*redMetricsProcessor) ForceFlush(context.Context) error { return nil }
+
+func statusCode(c codes.Code) string {
+	switch c {
+	case codes.Error:
+		return "STATUS_CODE_ERROR"
+	case codes.Ok:
+		return "STATUS_CODE_OK"
+	default:
+		return "STATUS_CODE_UNSET"
+	}
+}
@@ -0,0 +1,183 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distro
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	otelmetric "go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+func newREDTest(t *testing.T, sampler trace.Sampler, conf REDMetricsConfig) (oteltrace.Tracer, *tracetest.InMemoryExporter, func() map[string]metricdata.Aggregation) {
+	t.Helper()
+
+	reader := metric.NewManualReader()
+	mp := metric.NewMeterProvider(metric.WithReader(reader))
+	res := resource.NewSchemaless(attribute.String("service.name", "checkout"))
+	red := newREDMetricsProcessor(logr.Discard(), func() otelmetric.MeterProvider { return mp }, res, conf)
+
+	exp := tracetest.NewInMemoryExporter()
+	tp := trace.NewTracerProvider(
+		trace.WithSampler(sampler),
+		trace.WithSpanProcessor(red),
+		trace.WithSyncer(exp),
+	)
+	t.Cleanup(func() {
+		require.NoError(t, tp.Shutdown(context.Background()))
+		require.NoError(t, mp.Shutdown(context.Background()))
+	})
+
+	collect := func() map[string]metricdata.Aggregation {
+		var rm metricdata.ResourceMetrics
+		require.NoError(t, reader.Collect(context.Background(), &rm))
+		got := make(map[string]metricdata.Aggregation)
+		for _, sm := range rm.ScopeMetrics {
+			for _, m := range sm.Metrics {
+				got[m.Name] = m.Data
+			}
+		}
+		return got
+	}
+	return tp.Tracer("test"), exp, collect
+}
+
+func TestREDMetricsProcessor(t *testing.T) {
+	tracer, exp, collect := newREDTest(t, trace.AlwaysSample(), REDMetricsConfig{Dimensions: []attribute.Key{"http.route"}})
+
+	for range 2 {
+		_, span := tracer.Start(context.Background(), "GET /users/{id}",
+			oteltrace.WithSpanKind(oteltrace.SpanKindServer),
+			oteltrace.WithAttributes(attribute.String("http.route", "/users/{id}"), attribute.Int("user.id", 1)),
+		)
+		span.End()
+	}
+	_, span := tracer.Start(context.Background(), "query")
+	span.SetStatus(codes.Error, "timeout")
+	span.End()
+
+	assert.Len(t, exp.GetSpans(), 3)
+
+	got := collect()
+	calls, ok := got["calls"].(metricdata.Sum[float64])
+	require.True(t, ok, "calls counter not recorded")
+	server := attribute.NewSet(
+		attribute.String("service.name", "checkout"),
+		attribute.String("span.name", "GET /users/{id}"),
+		attribute.String("span.kind", "SPAN_KIND_SERVER"),
+		attribute.String("status.code", "STATUS_CODE_UNSET"),
+		attribute.String("http.route", "/users/{id}"),
+	)
+	internal := attribute.NewSet(
+		attribute.String("service.name", "checkout"),
+		attribute.String("span.name", "query"),
+		attribute.String("span.kind", "SPAN_KIND_INTERNAL"),
+		attribute.String("status.code", "STATUS_CODE_ERROR"),
+	)
+	assert.ElementsMatch(t, []metricdata.DataPoint[float64]{
+		{Attributes: server, Value: 2},
+		{Attributes: internal, Value: 1},
+	}, stripTimes(calls.DataPoints))
+
+	duration, ok := got["duration"].(metricdata.Histogram[float64])
+	require.True(t, ok, "duration histogram not recorded")
+	require.Len(t, duration.DataPoints, 2)
+	assert.Equal(t, durationBoundaries, duration.DataPoints[0].Bounds)
+}
+
+func TestREDMetricsProcessorCardinalityLimit(t *testing.T) {
+	tracer, _, collect := newREDTest(t, trace.AlwaysSample(), REDMetricsConfig{CardinalityLimit: 3})
+
+	for _, name := range []string{"a", "b", "c", "d", "a"} {
+		_, span := tracer.Start(context.Background(), name)
+		span.End()
+	}
+
+	calls, ok := collect()["calls"].(metricdata.Sum[float64])
+	require.True(t, ok, "calls counter not recorded")
+	got := make(map[string]float64)
+	for _, dp := range calls.DataPoints {
+		if v, ok := dp.Attributes.Value("span.name"); ok {
+			got[v.AsString()] = dp.Value
+		} else if dp.Attributes.HasValue(overflowAttr.Key) {
+			got["overflow"] = dp.Value
+		}
+	}
+	assert.Equal(t, map[string]float64{"a": 2, "b": 1, "overflow": 2}, got)
+}
+
+func TestREDMetricsProcessorBeforeSampling(t *testing.T) {
+	sampler := recordingSampler{Sampler: trace.TraceIDRatioBased(0.5)}
+	tracer, exp, collect := newREDTest(t, sampler, REDMetricsConfig{})
+
+	const n = 200
+	for range n {
+		_, span := tracer.Start(context.Background(), "span")
+		span.End()
+	}
+
+	exported := exp.GetSpans()
+	assert.NotEmpty(t, exported)
+	assert.Less(t, len(exported), n, "dropped spans exported")
+	for _, s := range exported {
+		assert.True(t, s.SpanContext.IsSampled())
+	}
+
+	got := collect()
+	calls, ok := got["calls"].(metricdata.Sum[float64])
+	require.True(t, ok, "calls counter not recorded")
+	require.Len(t, calls.DataPoints, 1)
+	assert.InDelta(t, n, calls.DataPoints[0].Value, 1e-9, "calls sampled")
+
+	duration, ok := got["duration"].(metricdata.Histogram[float64])
+	require.True(t, ok, "duration histogram not recorded")
+	require.Len(t, duration.DataPoints, 1)
+	assert.Equal(t, uint64(n), duration.DataPoints[0].Count, "duration sampled")
+}
+
+func TestREDMetricsConfig(t *testing.T) {
+	c := newConfig()
+	assert.Nil(t, redMetricsConfig(c), "enabled by default")
+
+	t.Setenv(redMetricsEnabledKey, "true")
+	t.Setenv(redMetricsDimensionsKey, "http.route, db.system,")
+	t.Setenv(redMetricsCardinalityLimitKey, "50")
+	assert.Equal(t, &REDMetricsConfig{
+		Dimensions:       []attribute.Key{"http.route", "db.system"},
+		CardinalityLimit: 50,
+	}, redMetricsConfig(c))
+
+	c = newConfig(WithREDMetrics(REDMetricsConfig{CardinalityLimit: 10}))
+	assert.Equal(t, &REDMetricsConfig{CardinalityLimit: 10}, redMetricsConfig(c))
+}
+
+func stripTimes(dps []metricdata.DataPoint[float64]) []metricdata.DataPoint[float64] {
+	out := make([]metricdata.DataPoint[float64], len(dps))
+	for i, dp := range dps {
+		out[i] = metricdata.DataPoint[float64]{Attributes: dp.Attributes, Value: dp.Value}
+	}
+	return out
+}
@@ -0,0 +1,229 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distro
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel/sdk/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// samplerRateLimiting is the OTEL_TRACES_SAMPLER value of the rate-limiting
+// sampler of the distro.
+const samplerRateLimiting = "ratelimiting"
+
+// defaultSamplerRate is the default number of traces per second sampled by
+// the ratelimiting sampler.
+const defaultSamplerRate = 100
+
+// OTEL_TRACES_SAMPLER values of the samplers of the OpenTelemetry SDK.
+const (
+	samplerAlwaysOn                = "always_on"
+	samplerAlwaysOff               = "always_off"
+	samplerTraceIDRatio            = "traceidratio"
+	samplerParentBasedAlwaysOn     = "parentbased_always_on"
+	samplerParentBasedAlwaysOff    = "parentbased_always_off"
+	samplerParentBasedTraceIDRatio = "parentbased_traceidratio"
+)
+
+// newSampler returns the sampler configured with the OTEL_TRACES_SAMPLER and
+// OTEL_TRACES_SAMPLER_ARG environment variables, and where it is configured.
+//
+// Unlike the OpenTelemetry SDK, the distro samples all spans by default. It
+// also provides the ratelimiting sampler the SDK does not support. The SDK
+// still parses these environment variables and reports their invalid values,
+// so they are not reported again.
+func newSampler(l logr.Logger) (trace.Sampler, ConfigSource) {
+	v, ok := os.LookupEnv(tracesSamplerKey)
+	if !ok {
+		return trace.AlwaysSample(), SourceDefault
+	}
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case samplerRateLimiting:
+		return trace.ParentBased(newRateLimitingSampler(samplerRate(l))), SourceEnv
+	case samplerAlwaysOn:
+		return trace.AlwaysSample(), SourceEnv
+	case samplerAlwaysOff:
+		return trace.NeverSample(), SourceEnv
+	case samplerTraceIDRatio:
+		return traceIDRatioSampler(), SourceEnv
+	case samplerParentBasedAlwaysOn:
+		return trace.ParentBased(trace.AlwaysSample()), SourceEnv
+	case samplerParentBasedAlwaysOff:
+		return trace.ParentBased(trace.NeverSample()), SourceEnv
+	case samplerParentBasedTraceIDRatio:
+		return trace.ParentBased(traceIDRatioSampler()), SourceEnv
+	default:
+		// The default sampler of the SDK for unsupported samplers.
+		return trace.ParentBased(trace.AlwaysSample()), SourceDefault
+	}
+}
+
+// traceIDRatioSampler returns the TraceIDRatioBased sampler of the ratio set
+// with OTEL_TRACES_SAMPLER_ARG. Like the SDK, all traces are sampled if the
+// ratio is not set or invalid.
+func traceIDRatioSampler() trace.Sampler {
+	ratio, err := strconv.ParseFloat(strings.TrimSpace(os.Getenv(tracesSamplerArgKey)), 64)
+	if err != nil || ratio < 0 || ratio > 1 {
+		ratio = 1
+	}
+	return trace.TraceIDRatioBased(ratio)
+}
+
+// isRateLimitingSamplerError returns if err is the error the OpenTelemetry
+// SDK reports when it parses OTEL_TRACES_SAMPLER set to the ratelimiting
+// sampler it does not support. The distro provides this sampler, the error is
+// not reported.
+func isRateLimitingSamplerError(err error) bool {
+	return err != nil && err.Error() == "unsupported sampler: "+samplerRateLimiting
+}
+
+// samplerRate returns the rate set with OTEL_TRACES_SAMPLER_ARG.
+func samplerRate(l logr.Logger) float64 {
+	v := strings.TrimSpace(os.Getenv(tracesSamplerArgKey))
+	if v == "" {
+		return defaultSamplerRate
+	}
+	rate, err := strconv.ParseFloat(v, 64)
+	if err != nil || rate <= 0 || math.IsInf(rate, 0) {
+		err := fmt.Errorf("invalid %s: %q", tracesSamplerArgKey, v)
+		l.Error(err, "invalid value, using default", "key", tracesSamplerArgKey, "default", defaultSamplerRate)
+		return defaultSamplerRate
+	}
+	return rate
+}
+
+// rateLimitingSampler samples up to rate traces per second using a token
+// bucket. The bucket holds at most one second of tokens so bursts are
+// bounded.
+//
+// The sampling probability, estimated from the rate of the traces seen in
+// the previous second, is recorded in the OpenTelemetry tracestate threshold
+// ("ot=th:...") so backends can extrapolate counts.
+type rateLimitingSampler struct {
+	rate float64
+	now  func() time.Time
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+
+	// window is the start of the current second the traces seen are counted
+	// in.
+	window      time.Time
+	seen        float64
+	probability float64
+}
+
+func newRateLimitingSampler(rate float64) *rateLimitingSampler {
+	return &rateLimitingSampler{
+		rate:        rate,
+		now:         time.Now,
+		tokens:      max(rate, 1),
+		probability: 1,
+	}
+}
+
+func (s *rateLimitingSampler) ShouldSample(p trace.SamplingParameters) trace.SamplingResult {
+	ts := oteltrace.SpanContextFromContext(p.ParentContext).TraceState()
+
+	probability, ok := s.take()
+	if !ok {
+		return trace.SamplingResult{Decision: trace.Drop, Tracestate: ts}
+	}
+	if withTh, err := withThreshold(ts, probability); err == nil {
+		ts = withTh
+	}
+	return trace.SamplingResult{Decision: trace.RecordAndSample, Tracestate: ts}
+}
+
+// take returns if a token is available and the current sampling
+// probability.
+func (s *rateLimitingSampler) take() (float64, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if s.last.IsZero() {
+		s.last, s.window = now, now
+	}
+
+	if elapsed := now.Sub(s.window); elapsed >= time.Second {
+		// Estimate the probability from the traces seen in the last window.
+		if observed := s.seen / elapsed.Seconds(); observed > s.rate {
+			s.probability = s.rate / observed
+		} else {
+			s.probability = 1
+		}
+		s.window, s.seen = now, 0
+	}
+	s.seen++
+	probability := s.probability
+	if s.seen > s.rate {
+		// More traces than the rate were already seen in this window.
+		probability = min(probability, s.rate/s.seen)
+	}
+
+	s.tokens = min(s.tokens+now.Sub(s.last).Seconds()*s.rate, max(s.rate, 1))
+	s.last = now
+	if s.tokens < 1 {
+		return probability, false
+	}
+	s.tokens--
+	return probability, true
+}
+
+func (s *rateLimitingSampler) Description() string {
+	return fmt.Sprintf("RateLimitingSampler{%g}", s.rate)
+}
+
+// OpenTelemetry tracestate sampling threshold encoding.
+const (
+	otKey       = "ot"
+	thPrefix    = "th:"
+	maxAdjusted = 1 << 56
+	// thDigits is the number of hex digits of a threshold with its trailing
+	// zeros.
+	thDigits = 14
+)
+
+// withThreshold returns ts with the OpenTelemetry sampling threshold of
+// probability set.
+func withThreshold(ts oteltrace.TraceState, probability float64) (oteltrace.TraceState, error) {
+	// The threshold is the number of the 2^56 possible random values that
+	// are rejected, hex encoded without trailing zeros.
+	th := "0"
+	if rejected := uint64((1 - probability) * maxAdjusted); rejected > 0 {
+		th = strings.TrimRight(fmt.Sprintf("%0*x", thDigits, min(rejected, maxAdjusted-1)), "0")
+	}
+
+	members := []string{thPrefix + th}
+	if v := ts.Get(otKey); v != "" {
+		for _, m := range strings.Split(v, ";") {
+			if !strings.HasPrefix(m, thPrefix) {
+				members = append(members, m)
+			}
+		}
+	}
+	return ts.Insert(otKey, strings.Join(members, ";"))
+}
@@ -0,0 +1,144 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distro
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tonglil/buflogr"
+	"go.opentelemetry.io/otel/sdk/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestNewSampler(t *testing.T) {
+	testCases := []struct {
+		sampler string
+		arg     string
+		want    trace.Sampler
+		source  ConfigSource
+		invalid bool
+	}{
+		{sampler: "always_on", want: trace.AlwaysSample(), source: SourceEnv},
+		{sampler: "always_off", want: trace.NeverSample(), source: SourceEnv},
+		{sampler: "traceidratio", arg: "0.25", want: trace.TraceIDRatioBased(0.25), source: SourceEnv},
+		{sampler: "traceidratio", want: trace.TraceIDRatioBased(1), source: SourceEnv},
+		{sampler: "traceidratio", arg: "2", want: trace.TraceIDRatioBased(1), source: SourceEnv},
+		{sampler: "parentbased_always_on", want: trace.ParentBased(trace.AlwaysSample()), source: SourceEnv},
+		{sampler: "parentbased_always_off", want: trace.ParentBased(trace.NeverSample()), source: SourceEnv},
+		{sampler: "parentbased_traceidratio", arg: "0.5", want: trace.ParentBased(trace.TraceIDRatioBased(0.5)), source: SourceEnv},
+		// The OpenTelemetry SDK reports unsupported samplers.
+		{sampler: "unknown", want: trace.ParentBased(trace.AlwaysSample()), source: SourceDefault},
+		{sampler: "ratelimiting", want: trace.ParentBased(newRateLimitingSampler(100)), source: SourceEnv},
+		{sampler: " RateLimiting", arg: "10", want: trace.ParentBased(newRateLimitingSampler(10)), source: SourceEnv},
+		{sampler: "ratelimiting", arg: "-1", want: trace.ParentBased(newRateLimitingSampler(100)), source: SourceEnv, invalid: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.sampler+"/"+tc.arg, func(t *testing.T) {
+			t.Setenv(tracesSamplerKey, tc.sampler)
+			t.Setenv(tracesSamplerArgKey, tc.arg)
+			var buf bytes.Buffer
+
+			got, source := newSampler(buflogr.NewWithBuffer(&buf))
+			require.NotNil(t, got)
+			assert.Equal(t, tc.want.Description(), got.Description())
+			assert.Equal(t, tc.source, source)
+			if tc.invalid {
+				assert.Contains(t, buf.String(), "invalid")
+				assert.Contains(t, buf.String(), "default 100")
+			} else {
+				assert.Empty(t, buf.String())
+			}
+		})
+	}
+}
+
+func TestNewSamplerDefault(t *testing.T) {
+	got, source := newSampler(buflogr.New())
+	assert.Equal(t, trace.AlwaysSample().Description(), got.Description())
+	assert.Equal(t, SourceDefault, source)
+}
+
+func TestRateLimitingSampler(t *testing.T) {
+	now := time.Now()
+	s := newRateLimitingSampler(2)
+	s.now = func() time.Time { return now }
+
+	sample := func() trace.SamplingResult {
+		return s.ShouldSample(trace.SamplingParameters{ParentContext: context.Background()})
+	}
+
+	// The bucket starts with one second of tokens.
+	assert.Equal(t, trace.RecordAndSample, sample().Decision)
+	assert.Equal(t, "th:0", sample().Tracestate.Get("ot"))
+	assert.Equal(t, trace.Drop, sample().Decision)
+	assert.Equal(t, trace.Drop, sample().Decision)
+
+	// Tokens are refilled at the rate.
+	now = now.Add(500 * time.Millisecond)
+	res := sample()
+	assert.Equal(t, trace.RecordAndSample, res.Decision)
+	// 5 traces seen, 2 per second allowed.
+	assert.Equal(t, "th:99999999999998", res.Tracestate.Get("ot"))
+	assert.Equal(t, trace.Drop, sample().Decision)
+
+	// The probability is estimated from the previous second.
+	now = now.Add(time.Second)
+	res = sample()
+	assert.Equal(t, trace.RecordAndSample, res.Decision)
+	assert.Equal(t, "th:8", res.Tracestate.Get("ot"), "4 traces per second seen, 2 allowed")
+}
+
+func TestRateLimitingSamplerParentBased(t *testing.T) {
+	s := trace.ParentBased(newRateLimitingSampler(1))
+	tp := trace.NewTracerProvider(trace.WithSampler(s))
+	t.Cleanup(func() { require.NoError(t, tp.Shutdown(context.Background())) })
+	tracer := tp.Tracer("test")
+
+	ctx, root := tracer.Start(context.Background(), "root")
+	require.True(t, root.SpanContext().IsSampled())
+	assert.Equal(t, "th:0", root.SpanContext().TraceState().Get("ot"))
+
+	// Children of a sampled root are sampled without tokens.
+	for range 5 {
+		_, child := tracer.Start(ctx, "child")
+		assert.True(t, child.SpanContext().IsSampled())
+		child.End()
+	}
+	root.End()
+
+	_, other := tracer.Start(context.Background(), "root")
+	assert.False(t, other.SpanContext().IsSampled(), "rate not limited")
+	other.End()
+}
+
+func TestWithThreshold(t *testing.T) {
+	ts, err := oteltrace.ParseTraceState("ot=rv:abcdef01234567;th:8,vendor=value")
+	require.NoError(t, err)
+
+	got, err := withThreshold(ts, 0.25)
+	require.NoError(t, err)
+	assert.Equal(t, "th:c;rv:abcdef01234567", got.Get("ot"))
+	assert.Equal(t, "value", got.Get("vendor"))
+
+	got, err = withThreshold(oteltrace.TraceState{}, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "th:0", got.Get("ot"))
+}
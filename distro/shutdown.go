@@ -0,0 +1,107 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distro
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// defaultShutdownTimeout is the default deadline to flush and shut down the
+// SDK within. It is below the default Kubernetes termination grace period.
+const defaultShutdownTimeout = 10 * time.Second
+
+// shutdownConfig is the configuration of ShutdownOnSignal.
+type shutdownConfig struct {
+	Timeout time.Duration
+	Hooks   []func(context.Context) error
+}
+
+// ShutdownOption configures ShutdownOnSignal.
+type ShutdownOption interface {
+	applyShutdown(*shutdownConfig)
+}
+
+type shutdownOptionFunc func(*shutdownConfig)
+
+func (fn shutdownOptionFunc) applyShutdown(c *shutdownConfig) {
+	fn(c)
+}
+
+// WithShutdownTimeout sets the deadline to run the shutdown hooks, flush, and
+// shut down the SDK within.
+//
+// If this option is not provided, a 10 second timeout is used.
+func WithShutdownTimeout(d time.Duration) ShutdownOption {
+	return shutdownOptionFunc(func(c *shutdownConfig) {
+		c.Timeout = d
+	})
+}
+
+// WithShutdownHook adds a cleanup function that is run on shutdown before the
+// SDK is flushed. Hooks are run in the order they are provided, so telemetry
+// they emit, e.g. when closing a server, is exported.
+func WithShutdownHook(hook func(context.Context) error) ShutdownOption {
+	return shutdownOptionFunc(func(c *shutdownConfig) {
+		c.Hooks = append(c.Hooks, hook)
+	})
+}
+
+// ShutdownOnSignal returns a copy of parent that is canceled when the process
+// receives a SIGINT or SIGTERM signal, and a shutdown function.
+//
+// The application is expected to stop its work when the returned context is
+// done and then call the shutdown function, commonly using defer. The
+// shutdown function stops the signal handling, runs all shutdown hooks, calls
+// ForceFlush and then Shutdown on s. All of this is done within the
+// configured timeout. It returns the joined errors of all hooks and signals
+// that failed. Signals that failed are reported as *SignalError. Calling the
+// shutdown function more than once returns the result of the first call.
+func (s SDK) ShutdownOnSignal(parent context.Context, opts ...ShutdownOption) (context.Context, func() error) {
+	c := shutdownConfig{Timeout: defaultShutdownTimeout}
+	for _, o := range opts {
+		o.applyShutdown(&c)
+	}
+
+	ctx, stop := signal.NotifyContext(parent, os.Interrupt, syscall.SIGTERM)
+
+	var (
+		once sync.Once
+		err  error
+	)
+	shutdown := func() error {
+		once.Do(func() {
+			stop()
+
+			// The parent is likely canceled by now, keep only its values.
+			sCtx, cancel := context.WithTimeout(context.WithoutCancel(parent), c.Timeout)
+			defer cancel()
+
+			errs := make([]error, 0, len(c.Hooks)+2)
+			for _, hook := range c.Hooks {
+				errs = append(errs, hook(sCtx))
+			}
+			errs = append(errs, s.ForceFlush(sCtx), s.Shutdown(sCtx))
+			err = errors.Join(errs...)
+		})
+		return err
+	}
+	return ctx, shutdown
+}
@@ -0,0 +1,123 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distro
+
+import (
+	"context"
+	"errors"
+	"os"
+	"runtime"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSDKShutdownSignalError(t *testing.T) {
+	errMetrics := errors.New("metrics failure")
+	noop := func(context.Context) error { return nil }
+	sdk := SDK{pipelines: []pipeline{
+		{signal: "traces", flush: noop, shutdown: noop},
+		{signal: "metrics", flush: noop, shutdown: func(context.Context) error { return errMetrics }},
+	}}
+
+	err := sdk.Shutdown(context.Background())
+	require.ErrorIs(t, err, errShutdown)
+	require.ErrorIs(t, err, errMetrics)
+
+	var sErr *SignalError
+	require.ErrorAs(t, err, &sErr)
+	assert.Equal(t, "metrics", sErr.Signal)
+	assert.Equal(t, "metrics: metrics failure", sErr.Error())
+}
+
+func TestSDKShutdownDiagnosticsError(t *testing.T) {
+	errDiag := errors.New("server failure")
+	sdk := SDK{diagnostics: func(context.Context) error { return errDiag }}
+
+	err := sdk.Shutdown(context.Background())
+	require.ErrorIs(t, err, errShutdown)
+	require.ErrorIs(t, err, errDiag)
+	assert.ErrorContains(t, err, "diagnostics endpoint: server failure")
+
+	var sErr *SignalError
+	assert.False(t, errors.As(err, &sErr), "diagnostics reported as a signal")
+	assert.NoError(t, sdk.ForceFlush(context.Background()))
+}
+
+func TestShutdownOnSignal(t *testing.T) {
+	var calls []string
+	record := func(name string, err error) func(context.Context) error {
+		return func(ctx context.Context) error {
+			_, ok := ctx.Deadline()
+			assert.True(t, ok, "%s: no deadline", name)
+			assert.NoError(t, ctx.Err(), "%s: context done", name)
+			calls = append(calls, name)
+			return err
+		}
+	}
+
+	errFlush := errors.New("flush failure")
+	errHook := errors.New("hook failure")
+	sdk := SDK{pipelines: []pipeline{
+		{signal: "traces", flush: record("flush traces", nil), shutdown: record("shutdown traces", nil)},
+		{signal: "logs", flush: record("flush logs", errFlush), shutdown: record("shutdown logs", nil)},
+	}}
+
+	parent, cancel := context.WithCancel(context.Background())
+	ctx, shutdown := sdk.ShutdownOnSignal(
+		parent,
+		WithShutdownTimeout(time.Minute),
+		WithShutdownHook(record("hook 1", errHook)),
+		WithShutdownHook(record("hook 2", nil)),
+	)
+	cancel()
+	<-ctx.Done()
+
+	err := shutdown()
+	assert.ErrorIs(t, err, errHook)
+	var sErr *SignalError
+	require.ErrorAs(t, err, &sErr)
+	assert.Equal(t, "logs", sErr.Signal)
+	assert.ErrorIs(t, sErr, errFlush)
+
+	want := []string{"hook 1", "hook 2", "flush traces", "flush logs", "shutdown traces", "shutdown logs"}
+	assert.Equal(t, want, calls)
+
+	// Subsequent calls do not shut down again.
+	assert.Equal(t, err, shutdown())
+	assert.Equal(t, want, calls)
+}
+
+func TestShutdownOnSignalSIGTERM(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("sending SIGTERM is not supported on Windows")
+	}
+
+	ctx, shutdown := SDK{}.ShutdownOnSignal(context.Background())
+	t.Cleanup(func() { assert.NoError(t, shutdown()) })
+
+	p, err := os.FindProcess(os.Getpid())
+	require.NoError(t, err)
+	require.NoError(t, p.Signal(syscall.SIGTERM))
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("context not canceled on SIGTERM")
+	}
+}
@@ -0,0 +1,486 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distro
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-logr/logr"
+	cmpb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	ctpb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	mpb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	tpb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// realmMaxRequestSizeKey is the maximum size, in bytes, of the requests
+	// sent to Splunk Observability Cloud ingest, before compression.
+	realmMaxRequestSizeKey = "SPLUNK_REALM_MAX_REQUEST_SIZE"
+
+	defaultRealmMaxRequestSize = 4 << 20 // 4 MiB
+)
+
+// WithRealmMaxRequestSize configures the maximum size, in bytes, of the
+// requests sent by the exporters ingesting directly to Splunk Observability
+// Cloud (when SPLUNK_REALM is set). The size is the one of the request
+// encoded with the configured protocol, protobuf or JSON, before compression.
+//
+// Larger batches are split in multiple requests. If Splunk Observability
+// Cloud ingest rejects a request as too large, it is split in two and each
+// half is sent again. Metrics are split between their data points. The spans
+// or data points that cannot fit in a request are dropped and reported to the
+// OpenTelemetry error handler as rejected. If a request fails after others
+// were accepted, the spans or data points not sent are reported as rejected
+// too.
+//
+// This option takes precedence over the SPLUNK_REALM_MAX_REQUEST_SIZE
+// environment variable. If neither are set, 4 MiB is used.
+func WithRealmMaxRequestSize(size int) Option {
+	return optionFunc(func(c *config) {
+		c.ExportConfig.MaxRequestSize = size
+	})
+}
+
+// realmMaxRequestSize returns the maximum size of the requests sent to Splunk
+// Observability Cloud ingest.
+func realmMaxRequestSize(l logr.Logger, c *exporterConfig) int {
+	if c.MaxRequestSize > 0 {
+		return c.MaxRequestSize
+	}
+	if size := envInt(l, realmMaxRequestSizeKey, defaultRealmMaxRequestSize); size > 0 {
+		return size
+	}
+	return defaultRealmMaxRequestSize
+}
+
+// otlpSplitter splits the OTLP export requests of a signal.
+type otlpSplitter struct {
+	otlpMessages
+
+	// unit is the name of the items reported as rejected.
+	unit string
+
+	// halve returns two requests with half of the items of req each.
+	halve func(req proto.Message) (proto.Message, proto.Message)
+	// count returns the number of items of req, reported as rejected if req
+	// is dropped.
+	count func(req proto.Message) int64
+	// rejected returns the rejected items count and error message of the
+	// partial success response resp.
+	rejected func(resp proto.Message) (int64, string)
+	// partialSuccess returns a partial success response rejecting n items.
+	partialSuccess func(n int64, msg string) proto.Message
+}
+
+var (
+	tracesSplitter = otlpSplitter{
+		otlpMessages: tracesMessages,
+		unit:         "spans",
+		halve:        halveTraces,
+		count:        countSpans,
+		rejected: func(m proto.Message) (int64, string) {
+			ps := m.(*ctpb.ExportTraceServiceResponse).GetPartialSuccess()
+			return ps.GetRejectedSpans(), ps.GetErrorMessage()
+		},
+		partialSuccess: func(n int64, msg string) proto.Message {
+			return &ctpb.ExportTraceServiceResponse{
+				PartialSuccess: &ctpb.ExportTracePartialSuccess{RejectedSpans: n, ErrorMessage: msg},
+			}
+		},
+	}
+	metricsSplitter = otlpSplitter{
+		otlpMessages: metricsMessages,
+		unit:         "data points",
+		halve:        halveMetrics,
+		count:        countDataPoints,
+		rejected: func(m proto.Message) (int64, string) {
+			ps := m.(*cmpb.ExportMetricsServiceResponse).GetPartialSuccess()
+			return ps.GetRejectedDataPoints(), ps.GetErrorMessage()
+		},
+		partialSuccess: func(n int64, msg string) proto.Message {
+			return &cmpb.ExportMetricsServiceResponse{
+				PartialSuccess: &cmpb.ExportMetricsPartialSuccess{RejectedDataPoints: n, ErrorMessage: msg},
+			}
+		},
+	}
+)
+
+// splitTransport is an http.RoundTripper that splits the OTLP/protobuf export
+// requests whose encoding is larger than maxSize, or rejected with a 413
+// (Content Too Large) status, in smaller requests.
+//
+// The requests are sent encoded as OTLP/JSON if json is set, so their size is
+// the one of the OTLP/JSON encoding. The base transport then needs to
+// transcode the OTLP/JSON responses back to protobuf.
+//
+// The items that cannot be sent are reported in a partial success response,
+// so the exporter reports them to the OpenTelemetry error handler. If one of
+// the requests fails before any is accepted, its response is returned so the
+// whole request can be retried. Once a request is accepted, the items of the
+// failed request and of the ones not sent yet are reported as rejected
+// instead.
+type splitTransport struct {
+	base     http.RoundTripper
+	maxSize  int
+	splitter otlpSplitter
+	json     bool
+}
+
+func newSplitTransport(maxSize int, s otlpSplitter, protocol string) func(http.RoundTripper) http.RoundTripper {
+	return func(base http.RoundTripper) http.RoundTripper {
+		return &splitTransport{base: base, maxSize: maxSize, splitter: s, json: protocol == otlpProtocolHTTPJSON}
+	}
+}
+
+// splitResult is the result of the requests a request is split in.
+type splitResult struct {
+	rejected int64
+	messages []string
+
+	// accepted is set once a request is accepted.
+	accepted bool
+	// failure is the error of the request that failed after a request was
+	// accepted. The items of the requests not sent because of it are
+	// counted in unsent.
+	failure string
+	unsent  int64
+}
+
+// RoundTrip sends req split in requests no larger than the maximum size.
+func (t *splitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	gzipped := req.Header.Get("Content-Encoding") == "gzip"
+	body, err := readBody(req.Body, gzipped)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read OTLP request: %w", err)
+	}
+
+	if !t.json && len(body) <= t.maxSize {
+		resp, err := t.send(req, body, gzipped)
+		if err != nil || resp.StatusCode != http.StatusRequestEntityTooLarge {
+			return resp, err
+		}
+		discard(resp)
+	}
+
+	msg := t.splitter.request()
+	if err := proto.Unmarshal(body, msg); err != nil {
+		return nil, fmt.Errorf("failed to decode OTLP request: %w", err)
+	}
+	var r splitResult
+	if resp, err := t.split(req, msg, gzipped, &r); resp != nil || err != nil {
+		return resp, err
+	}
+	return t.response(req, r)
+}
+
+// export sends msg, split as needed, and adds the result to r. If a request
+// fails before any is accepted, its response or error is returned.
+func (t *splitTransport) export(req *http.Request, msg proto.Message, gzipped bool, r *splitResult) (*http.Response, error) {
+	if r.failure != "" {
+		// Do not send more requests after a failure.
+		r.unsent += t.splitter.count(msg)
+		return nil, nil
+	}
+
+	body, err := t.marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	if len(body) <= t.maxSize {
+		resp, err := t.send(req, body, gzipped)
+		switch {
+		case err != nil:
+			return t.fail(msg, r, nil, err)
+		case resp.StatusCode >= 200 && resp.StatusCode <= 299:
+			r.accepted = true
+			t.addResponse(resp, r)
+			return nil, nil
+		case resp.StatusCode != http.StatusRequestEntityTooLarge:
+			return t.fail(msg, r, resp, nil)
+		}
+		discard(resp)
+	}
+	return t.split(req, msg, gzipped, r)
+}
+
+// split sends msg, too large to be sent, split in two requests, or drops it
+// if it cannot be split.
+func (t *splitTransport) split(req *http.Request, msg proto.Message, gzipped bool, r *splitResult) (*http.Response, error) {
+	if t.splitter.count(msg) <= 1 {
+		n := t.splitter.count(msg)
+		r.rejected += n
+		r.messages = append(r.messages, fmt.Sprintf("%d %s larger than the maximum request size (%d bytes) dropped", n, t.splitter.unit, t.maxSize))
+		return nil, nil
+	}
+	a, b := t.splitter.halve(msg)
+	if resp, err := t.export(req, a, gzipped, r); resp != nil || err != nil {
+		return resp, err
+	}
+	return t.export(req, b, gzipped, r)
+}
+
+// fail handles the failed request of msg, with the response resp or the
+// error err. If no request was accepted yet, they are returned. Otherwise, the
+// items of msg are counted as not sent and nil is returned.
+func (t *splitTransport) fail(msg proto.Message, r *splitResult, resp *http.Response, err error) (*http.Response, error) {
+	if !r.accepted {
+		return resp, err
+	}
+	if err != nil {
+		r.failure = err.Error()
+	} else {
+		r.failure = resp.Status
+		discard(resp)
+	}
+	r.unsent += t.splitter.count(msg)
+	return nil, nil
+}
+
+// marshal returns the encoding of msg sent.
+func (t *splitTransport) marshal(msg proto.Message) ([]byte, error) {
+	if t.json {
+		return marshalOTLPJSON(msg)
+	}
+	return proto.Marshal(msg)
+}
+
+// send sends a copy of req with body.
+func (t *splitTransport) send(req *http.Request, body []byte, gzipped bool) (*http.Response, error) {
+	if gzipped {
+		var err error
+		if body, err = gzipBytes(body); err != nil {
+			return nil, err
+		}
+	}
+	// RoundTrip must not modify the original request.
+	req = req.Clone(req.Context())
+	if t.json {
+		req.Header.Set("Content-Type", contentTypeJSON)
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	req.ContentLength = int64(len(body))
+	return t.base.RoundTrip(req)
+}
+
+// addResponse adds the items rejected in the successful response resp to r.
+// The request was accepted, so a response that cannot be read is only
+// reported in the messages of r.
+func (t *splitTransport) addResponse(resp *http.Response, r *splitResult) {
+	b, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		r.messages = append(r.messages, fmt.Sprintf("failed to read OTLP response: %v", err))
+		return
+	}
+	if len(b) == 0 || !hasContentType(resp.Header, contentTypeProtobuf) {
+		return
+	}
+	msg := t.splitter.response()
+	if err := proto.Unmarshal(b, msg); err != nil {
+		r.messages = append(r.messages, fmt.Sprintf("failed to decode OTLP response: %v", err))
+		return
+	}
+	n, m := t.splitter.rejected(msg)
+	r.rejected += n
+	if m != "" {
+		r.messages = append(r.messages, m)
+	}
+}
+
+// response returns the successful response of req reporting the items
+// rejected, or not sent, in r.
+func (t *splitTransport) response(req *http.Request, r splitResult) (*http.Response, error) {
+	if r.unsent > 0 {
+		r.rejected += r.unsent
+		r.messages = append(r.messages, fmt.Sprintf("%d %s not sent after a request failed: %s", r.unsent, t.splitter.unit, r.failure))
+	}
+	var b []byte
+	if r.rejected > 0 || len(r.messages) > 0 {
+		var err error
+		b, err = proto.Marshal(t.splitter.partialSuccess(r.rejected, strings.Join(r.messages, "; ")))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &http.Response{
+		Status:     http.StatusText(http.StatusOK),
+		StatusCode: http.StatusOK,
+		Proto:      req.Proto,
+		ProtoMajor: req.ProtoMajor,
+		ProtoMinor: req.ProtoMinor,
+		Header: http.Header{
+			"Content-Type":   []string{contentTypeProtobuf},
+			"Content-Length": []string{strconv.Itoa(len(b))},
+		},
+		ContentLength: int64(len(b)),
+		Body:          io.NopCloser(bytes.NewReader(b)),
+		Request:       req,
+	}, nil
+}
+
+// discard reads and closes the body of resp so its connection can be reused.
+func discard(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+}
+
+func countSpans(m proto.Message) int64 {
+	var n int64
+	for _, rs := range m.(*ctpb.ExportTraceServiceRequest).GetResourceSpans() {
+		for _, ss := range rs.GetScopeSpans() {
+			n += int64(len(ss.GetSpans()))
+		}
+	}
+	return n
+}
+
+func halveTraces(m proto.Message) (proto.Message, proto.Message) {
+	req := m.(*ctpb.ExportTraceServiceRequest)
+	a, b := &ctpb.ExportTraceServiceRequest{}, &ctpb.ExportTraceServiceRequest{}
+	left := int(countSpans(req) / 2)
+	for _, rs := range req.GetResourceSpans() {
+		ra := &tpb.ResourceSpans{Resource: rs.GetResource(), SchemaUrl: rs.GetSchemaUrl()}
+		rb := &tpb.ResourceSpans{Resource: rs.GetResource(), SchemaUrl: rs.GetSchemaUrl()}
+		for _, ss := range rs.GetScopeSpans() {
+			spans := ss.GetSpans()
+			k := min(left, len(spans))
+			left -= k
+			if k > 0 {
+				ra.ScopeSpans = append(ra.ScopeSpans, &tpb.ScopeSpans{Scope: ss.GetScope(), SchemaUrl: ss.GetSchemaUrl(), Spans: spans[:k]})
+			}
+			if k < len(spans) {
+				rb.ScopeSpans = append(rb.ScopeSpans, &tpb.ScopeSpans{Scope: ss.GetScope(), SchemaUrl: ss.GetSchemaUrl(), Spans: spans[k:]})
+			}
+		}
+		if len(ra.ScopeSpans) > 0 {
+			a.ResourceSpans = append(a.ResourceSpans, ra)
+		}
+		if len(rb.ScopeSpans) > 0 {
+			b.ResourceSpans = append(b.ResourceSpans, rb)
+		}
+	}
+	return a, b
+}
+
+func countDataPoints(m proto.Message) int64 {
+	var n int
+	for _, rm := range m.(*cmpb.ExportMetricsServiceRequest).GetResourceMetrics() {
+		for _, sm := range rm.GetScopeMetrics() {
+			for _, metric := range sm.GetMetrics() {
+				n += metricDataPoints(metric)
+			}
+		}
+	}
+	return int64(n)
+}
+
+// metricDataPoints returns the number of data points of m.
+func metricDataPoints(m *mpb.Metric) int {
+	return len(m.GetGauge().GetDataPoints()) +
+		len(m.GetSum().GetDataPoints()) +
+		len(m.GetHistogram().GetDataPoints()) +
+		len(m.GetExponentialHistogram().GetDataPoints()) +
+		len(m.GetSummary().GetDataPoints())
+}
+
+// halveMetrics splits the data points of m in two requests. A metric is split
+// between its data points if needed.
+func halveMetrics(m proto.Message) (proto.Message, proto.Message) {
+	req := m.(*cmpb.ExportMetricsServiceRequest)
+	a, b := &cmpb.ExportMetricsServiceRequest{}, &cmpb.ExportMetricsServiceRequest{}
+	left := int(countDataPoints(req) / 2)
+	for _, rm := range req.GetResourceMetrics() {
+		ra := &mpb.ResourceMetrics{Resource: rm.GetResource(), SchemaUrl: rm.GetSchemaUrl()}
+		rb := &mpb.ResourceMetrics{Resource: rm.GetResource(), SchemaUrl: rm.GetSchemaUrl()}
+		for _, sm := range rm.GetScopeMetrics() {
+			var ma, mb []*mpb.Metric
+			for _, metric := range sm.GetMetrics() {
+				n := metricDataPoints(metric)
+				switch {
+				case left == 0:
+					mb = append(mb, metric)
+				case n <= left:
+					ma = append(ma, metric)
+				default:
+					first, second := splitMetric(metric, left)
+					ma, mb = append(ma, first), append(mb, second)
+				}
+				left -= min(n, left)
+			}
+			if len(ma) > 0 {
+				ra.ScopeMetrics = append(ra.ScopeMetrics, &mpb.ScopeMetrics{Scope: sm.GetScope(), SchemaUrl: sm.GetSchemaUrl(), Metrics: ma})
+			}
+			if len(mb) > 0 {
+				rb.ScopeMetrics = append(rb.ScopeMetrics, &mpb.ScopeMetrics{Scope: sm.GetScope(), SchemaUrl: sm.GetSchemaUrl(), Metrics: mb})
+			}
+		}
+		if len(ra.ScopeMetrics) > 0 {
+			a.ResourceMetrics = append(a.ResourceMetrics, ra)
+		}
+		if len(rb.ScopeMetrics) > 0 {
+			b.ResourceMetrics = append(b.ResourceMetrics, rb)
+		}
+	}
+	return a, b
+}
+
+// splitMetric returns two copies of m, the first with its k first data points
+// and the second with the others.
+func splitMetric(m *mpb.Metric, k int) (*mpb.Metric, *mpb.Metric) {
+	a := &mpb.Metric{Name: m.GetName(), Description: m.GetDescription(), Unit: m.GetUnit(), Metadata: m.GetMetadata()}
+	b := &mpb.Metric{Name: m.GetName(), Description: m.GetDescription(), Unit: m.GetUnit(), Metadata: m.GetMetadata()}
+	switch data := m.GetData().(type) {
+	case *mpb.Metric_Gauge:
+		dp := data.Gauge.GetDataPoints()
+		a.Data = &mpb.Metric_Gauge{Gauge: &mpb.Gauge{DataPoints: dp[:k]}}
+		b.Data = &mpb.Metric_Gauge{Gauge: &mpb.Gauge{DataPoints: dp[k:]}}
+	case *mpb.Metric_Sum:
+		dp := data.Sum.GetDataPoints()
+		a.Data = &mpb.Metric_Sum{Sum: &mpb.Sum{
+			DataPoints:             dp[:k],
+			AggregationTemporality: data.Sum.GetAggregationTemporality(),
+			IsMonotonic:            data.Sum.GetIsMonotonic(),
+		}}
+		b.Data = &mpb.Metric_Sum{Sum: &mpb.Sum{
+			DataPoints:             dp[k:],
+			AggregationTemporality: data.Sum.GetAggregationTemporality(),
+			IsMonotonic:            data.Sum.GetIsMonotonic(),
+		}}
+	case *mpb.Metric_Histogram:
+		dp := data.Histogram.GetDataPoints()
+		temporality := data.Histogram.GetAggregationTemporality()
+		a.Data = &mpb.Metric_Histogram{Histogram: &mpb.Histogram{DataPoints: dp[:k], AggregationTemporality: temporality}}
+		b.Data = &mpb.Metric_Histogram{Histogram: &mpb.Histogram{DataPoints: dp[k:], AggregationTemporality: temporality}}
+	case *mpb.Metric_ExponentialHistogram:
+		dp := data.ExponentialHistogram.GetDataPoints()
+		temporality := data.ExponentialHistogram.GetAggregationTemporality()
+		a.Data = &mpb.Metric_ExponentialHistogram{ExponentialHistogram: &mpb.ExponentialHistogram{DataPoints: dp[:k], AggregationTemporality: temporality}}
+		b.Data = &mpb.Metric_ExponentialHistogram{ExponentialHistogram: &mpb.ExponentialHistogram{DataPoints: dp[k:], AggregationTemporality: temporality}}
+	case *mpb.Metric_Summary:
+		dp := data.Summary.GetDataPoints()
+		a.Data = &mpb.Metric_Summary{Summary: &mpb.Summary{DataPoints: dp[:k]}}
+		b.Data = &mpb.Metric_Summary{Summary: &mpb.Summary{DataPoints: dp[k:]}}
+	}
+	return a, b
+}
@@ -0,0 +1,321 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distro
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	cmpb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	ctpb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	cpb "go.opentelemetry.io/proto/otlp/common/v1"
+	mpb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	tpb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestRealmMaxRequestSize(t *testing.T) {
+	assert.Equal(t, defaultRealmMaxRequestSize, realmMaxRequestSize(logr.Discard(), &exporterConfig{}))
+
+	t.Setenv(realmMaxRequestSizeKey, "1024")
+	assert.Equal(t, 1024, realmMaxRequestSize(logr.Discard(), &exporterConfig{}))
+
+	c := newConfig(WithRealmMaxRequestSize(2048)).ExportConfig
+	assert.Equal(t, 2048, realmMaxRequestSize(logr.Discard(), c))
+}
+
+// tracesRequest returns a request with a span named after each of names in
+// two scopes.
+func tracesRequest(names ...string) *ctpb.ExportTraceServiceRequest {
+	scope := func(names []string) *tpb.ScopeSpans {
+		ss := &tpb.ScopeSpans{Scope: &cpb.InstrumentationScope{Name: "test"}}
+		for _, n := range names {
+			ss.Spans = append(ss.Spans, &tpb.Span{Name: n})
+		}
+		return ss
+	}
+	half := len(names) / 2
+	return &ctpb.ExportTraceServiceRequest{
+		ResourceSpans: []*tpb.ResourceSpans{{
+			ScopeSpans: []*tpb.ScopeSpans{scope(names[:half]), scope(names[half:])},
+		}},
+	}
+}
+
+func spanNames(req *ctpb.ExportTraceServiceRequest) []string {
+	var names []string
+	for _, rs := range req.GetResourceSpans() {
+		for _, ss := range rs.GetScopeSpans() {
+			for _, s := range ss.GetSpans() {
+				names = append(names, s.GetName())
+			}
+		}
+	}
+	return names
+}
+
+func TestSplitTransport(t *testing.T) {
+	const serverLimit = 300
+
+	var received [][]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := readBody(r.Body, r.Header.Get("Content-Encoding") == "gzip")
+		require.NoError(t, err)
+		if len(body) > serverLimit {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			return
+		}
+		var req ctpb.ExportTraceServiceRequest
+		require.NoError(t, proto.Unmarshal(body, &req))
+		received = append(received, spanNames(&req))
+	}))
+	t.Cleanup(srv.Close)
+
+	var names []string
+	for _, c := range "abcdefgh" {
+		names = append(names, strings.Repeat(string(c), 50))
+	}
+	// Larger than any request accepted.
+	names[5] = strings.Repeat("x", 1000)
+
+	send := func(t *testing.T, maxSize int, gzipped bool) *ctpb.ExportTraceServiceResponse {
+		received = nil
+		client := exporterHTTPClient(&exporterConfig{}, newSplitTransport(maxSize, tracesSplitter, otlpProtocolHTTPProtobuf))
+
+		body, err := proto.Marshal(tracesRequest(names...))
+		require.NoError(t, err)
+		if gzipped {
+			body, err = gzipBytes(body)
+			require.NoError(t, err)
+		}
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, srv.URL, bytes.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", contentTypeProtobuf)
+		if gzipped {
+			req.Header.Set("Content-Encoding", "gzip")
+		}
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		b, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		var msg ctpb.ExportTraceServiceResponse
+		require.NoError(t, proto.Unmarshal(b, &msg))
+		return &msg
+	}
+
+	check := func(t *testing.T, resp *ctpb.ExportTraceServiceResponse) {
+		var got []string
+		for _, r := range received {
+			got = append(got, r...)
+		}
+		want := append(append([]string{}, names[:5]...), names[6:]...)
+		assert.Equal(t, want, got, "spans lost or reordered")
+		assert.Equal(t, int64(1), resp.GetPartialSuccess().GetRejectedSpans())
+		assert.Contains(t, resp.GetPartialSuccess().GetErrorMessage(), "1 spans larger than the maximum request size")
+	}
+
+	t.Run("budget", func(t *testing.T) {
+		check(t, send(t, serverLimit, false))
+		for _, r := range received {
+			assert.LessOrEqual(t, len(r), 4)
+		}
+	})
+
+	t.Run("bisect", func(t *testing.T) {
+		check(t, send(t, 1<<20, true))
+		assert.Greater(t, len(received), 1)
+	})
+}
+
+func TestSplitTransportFailure(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(srv.Close)
+
+	client := exporterHTTPClient(&exporterConfig{}, newSplitTransport(1<<20, tracesSplitter, otlpProtocolHTTPProtobuf))
+	body, err := proto.Marshal(tracesRequest("a", "b", "c", "d"))
+	require.NoError(t, err)
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, srv.URL, bytes.NewReader(body))
+	require.NoError(t, err)
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, 2, calls, "requests sent after a failure")
+}
+
+func TestSplitTransportPartialFailure(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls++
+		switch calls {
+		case 1:
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+		case 2:
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	client := exporterHTTPClient(&exporterConfig{}, newSplitTransport(1<<20, tracesSplitter, otlpProtocolHTTPProtobuf))
+	body, err := proto.Marshal(tracesRequest("a", "b", "c", "d"))
+	require.NoError(t, err)
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, srv.URL, bytes.NewReader(body))
+	require.NoError(t, err)
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	// The first half is accepted, the request must not be retried.
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	b, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	var msg ctpb.ExportTraceServiceResponse
+	require.NoError(t, proto.Unmarshal(b, &msg))
+	assert.Equal(t, int64(2), msg.GetPartialSuccess().GetRejectedSpans())
+	assert.Contains(t, msg.GetPartialSuccess().GetErrorMessage(), "2 spans not sent after a request failed: 503 Service Unavailable")
+	assert.Equal(t, 3, calls)
+}
+
+func TestSplitTransportJSONSize(t *testing.T) {
+	var sizes []int
+	var names []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, contentTypeJSON, r.Header.Get("Content-Type"))
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		sizes = append(sizes, len(body))
+		var req ctpb.ExportTraceServiceRequest
+		assert.NoError(t, protojson.Unmarshal(body, &req))
+		names = append(names, spanNames(&req)...)
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		_, _ = w.Write([]byte("{}"))
+	}))
+	t.Cleanup(srv.Close)
+
+	var want []string
+	for _, c := range "abcd" {
+		want = append(want, strings.Repeat(string(c), 50))
+	}
+	msg := tracesRequest(want...)
+	body, err := proto.Marshal(msg)
+	require.NoError(t, err)
+	jsonBody, err := marshalOTLPJSON(msg)
+	require.NoError(t, err)
+	// The protobuf request fits, not its OTLP/JSON encoding.
+	maxSize := len(body)
+	require.Greater(t, len(jsonBody), maxSize)
+
+	client := exporterHTTPClient(&exporterConfig{}, newJSONTransport(tracesMessages), newSplitTransport(maxSize, tracesSplitter, otlpProtocolHTTPJSON))
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, srv.URL, bytes.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", contentTypeProtobuf)
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	b, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Empty(t, b, "spans rejected")
+	assert.Equal(t, want, names)
+	assert.Greater(t, len(sizes), 1)
+	for _, size := range sizes {
+		assert.LessOrEqual(t, size, maxSize)
+	}
+}
+
+func TestHalveMetrics(t *testing.T) {
+	gauge := func(name string, points int) *mpb.Metric {
+		g := &mpb.Gauge{}
+		for range points {
+			g.DataPoints = append(g.DataPoints, &mpb.NumberDataPoint{})
+		}
+		return &mpb.Metric{Name: name, Data: &mpb.Metric_Gauge{Gauge: g}}
+	}
+	req := &cmpb.ExportMetricsServiceRequest{
+		ResourceMetrics: []*mpb.ResourceMetrics{
+			{ScopeMetrics: []*mpb.ScopeMetrics{{Metrics: []*mpb.Metric{gauge("a", 1), gauge("b", 4)}}}},
+			{ScopeMetrics: []*mpb.ScopeMetrics{{Metrics: []*mpb.Metric{gauge("c", 1)}}}},
+		},
+	}
+	assert.Equal(t, int64(6), countDataPoints(req))
+
+	a, b := halveMetrics(req)
+	assert.Equal(t, int64(3), countDataPoints(a))
+	assert.Equal(t, int64(3), countDataPoints(b))
+	ma := a.(*cmpb.ExportMetricsServiceRequest).GetResourceMetrics()
+	require.Len(t, ma, 1)
+	assert.Equal(t, []string{"a", "b"}, metricNames(ma[0]))
+	mb := b.(*cmpb.ExportMetricsServiceRequest).GetResourceMetrics()
+	require.Len(t, mb, 2)
+	assert.Equal(t, []string{"b"}, metricNames(mb[0]))
+	assert.Equal(t, []string{"c"}, metricNames(mb[1]))
+}
+
+func metricNames(rm *mpb.ResourceMetrics) []string {
+	var names []string
+	for _, sm := range rm.GetScopeMetrics() {
+		for _, m := range sm.GetMetrics() {
+			names = append(names, m.GetName())
+		}
+	}
+	return names
+}
+
+func TestSplitMetric(t *testing.T) {
+	points := []*mpb.NumberDataPoint{{}, {}, {}}
+	m := &mpb.Metric{
+		Name: "requests",
+		Unit: "{request}",
+		Data: &mpb.Metric_Sum{Sum: &mpb.Sum{
+			DataPoints:             points,
+			AggregationTemporality: mpb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+			IsMonotonic:            true,
+		}},
+	}
+
+	a, b := splitMetric(m, 1)
+	for _, got := range []*mpb.Metric{a, b} {
+		assert.Equal(t, "requests", got.GetName())
+		assert.Equal(t, "{request}", got.GetUnit())
+		assert.Equal(t, mpb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE, got.GetSum().GetAggregationTemporality())
+		assert.True(t, got.GetSum().GetIsMonotonic())
+	}
+	assert.Len(t, a.GetSum().GetDataPoints(), 1)
+	assert.Len(t, b.GetSum().GetDataPoints(), 2)
+}
@@ -0,0 +1,175 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distro
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// accessTokenHeader is the header used to send the access token.
+const accessTokenHeader = "X-Sf-Token"
+
+// defaultExportTimeout matches the default timeout of the OTLP exporters.
+const defaultExportTimeout = 10 * time.Second
+
+// errAccessTokenRejected is logged when an export request is rejected due to
+// an invalid or expired access token.
+var errAccessTokenRejected = errors.New("access token rejected")
+
+// TokenProvider provides the access token added to exported telemetry.
+//
+// Token is called for every export request so that rotated tokens are used
+// as soon as they are available. Implementations need to be safe for
+// concurrent use. Any error returned fails the export request it was called
+// for.
+type TokenProvider interface {
+	Token(context.Context) (string, error)
+}
+
+// TokenProviderFunc is a TokenProvider implemented by a function.
+type TokenProviderFunc func(context.Context) (string, error)
+
+// Token returns the access token returned by fn.
+func (fn TokenProviderFunc) Token(ctx context.Context) (string, error) {
+	return fn(ctx)
+}
+
+// fileTokenProvider provides the access token stored in a file. The file is
+// re-read whenever its modification time or size changes.
+type fileTokenProvider struct {
+	path string
+
+	mu      sync.Mutex
+	modTime time.Time
+	size    int64
+	token   string
+}
+
+func newFileTokenProvider(path string) *fileTokenProvider {
+	return &fileTokenProvider{path: path}
+}
+
+// Token returns the current content of the token file.
+func (p *fileTokenProvider) Token(context.Context) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	info, err := os.Stat(p.path)
+	if err != nil {
+		return "", fmt.Errorf("access token file: %w", err)
+	}
+	if p.token != "" && info.ModTime().Equal(p.modTime) && info.Size() == p.size {
+		return p.token, nil
+	}
+
+	b, err := os.ReadFile(p.path)
+	if err != nil {
+		return "", fmt.Errorf("access token file: %w", err)
+	}
+	token := strings.TrimSpace(string(b))
+	if token == "" {
+		return "", fmt.Errorf("access token file: %s is empty", p.path)
+	}
+
+	p.modTime, p.size, p.token = info.ModTime(), info.Size(), token
+	return token, nil
+}
+
+// tokenTransport is an http.RoundTripper that adds the current access token
+// to every request it sends.
+type tokenTransport struct {
+	base     http.RoundTripper
+	provider TokenProvider
+	logger   logr.Logger
+	// basicAuth sends the token as the password of the "auth" user instead
+	// of the X-Sf-Token header.
+	basicAuth bool
+}
+
+func newTokenTransport(l logr.Logger, p TokenProvider, basicAuth bool) func(http.RoundTripper) http.RoundTripper {
+	return func(base http.RoundTripper) http.RoundTripper {
+		return &tokenTransport{base: base, provider: p, logger: l, basicAuth: basicAuth}
+	}
+}
+
+// RoundTrip sends req with the current access token.
+func (t *tokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.provider.Token(req.Context())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get access token: %w", err)
+	}
+
+	// RoundTrip must not modify the original request.
+	req = req.Clone(req.Context())
+	if t.basicAuth {
+		req.SetBasicAuth("auth", token)
+	} else {
+		req.Header.Set(accessTokenHeader, token)
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err == nil && (resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden) {
+		t.logger.Error(errAccessTokenRejected, "export request failed", "url", req.URL.Redacted(), "status", resp.StatusCode)
+	}
+	return resp, err
+}
+
+// tokenCredentials are gRPC per-RPC credentials sending the current access
+// token as request metadata.
+type tokenCredentials struct {
+	provider TokenProvider
+}
+
+// GetRequestMetadata returns the metadata containing the current access token.
+func (c tokenCredentials) GetRequestMetadata(ctx context.Context, _ ...string) (map[string]string, error) {
+	token, err := c.provider.Token(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get access token: %w", err)
+	}
+	return map[string]string{strings.ToLower(accessTokenHeader): token}, nil
+}
+
+// RequireTransportSecurity returns false because the default local collector
+// endpoint does not use TLS.
+func (tokenCredentials) RequireTransportSecurity() bool {
+	return false
+}
+
+// tokenDialOptions returns the gRPC dial options used to send the access
+// token from the TokenProvider of c with every export request.
+func tokenDialOptions(l logr.Logger, c *exporterConfig) []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithPerRPCCredentials(tokenCredentials{provider: c.TokenProvider}),
+		grpc.WithChainUnaryInterceptor(func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			err := invoker(ctx, method, req, reply, cc, opts...)
+			if code := status.Code(err); code == codes.Unauthenticated || code == codes.PermissionDenied {
+				l.Error(errAccessTokenRejected, "export request failed", "method", method, "code", code.String())
+			}
+			return err
+		}),
+	}
+}
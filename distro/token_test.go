@@ -0,0 +1,122 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distro
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tonglil/buflogr"
+)
+
+func TestFileTokenProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	p := newFileTokenProvider(path)
+	ctx := context.Background()
+
+	_, err := p.Token(ctx)
+	assert.ErrorIs(t, err, os.ErrNotExist, "missing file")
+
+	require.NoError(t, os.WriteFile(path, []byte("first\n"), 0o600))
+	got, err := p.Token(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "first", got)
+
+	// Rotate the token.
+	require.NoError(t, os.WriteFile(path, []byte("second-token"), 0o600))
+	future := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(path, future, future))
+	got, err = p.Token(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "second-token", got)
+
+	require.NoError(t, os.WriteFile(path, nil, 0o600))
+	_, err = p.Token(ctx)
+	assert.ErrorContains(t, err, "is empty")
+}
+
+func TestTokenTransport(t *testing.T) {
+	var status int
+	var gotReq *http.Request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotReq = r
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+
+	var token string
+	var tokenErr error
+	provider := TokenProviderFunc(func(context.Context) (string, error) {
+		return token, tokenErr
+	})
+
+	var buf bytes.Buffer
+	l := buflogr.NewWithBuffer(&buf)
+	client := exporterHTTPClient(&exporterConfig{}, newTokenTransport(l, provider, false))
+
+	send := func() error {
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, srv.URL, http.NoBody)
+		require.NoError(t, err)
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		return resp.Body.Close()
+	}
+
+	status, token = http.StatusOK, "one"
+	require.NoError(t, send())
+	assert.Equal(t, "one", gotReq.Header.Get(accessTokenHeader))
+
+	status, token = http.StatusUnauthorized, "two"
+	require.NoError(t, send())
+	assert.Equal(t, "two", gotReq.Header.Get(accessTokenHeader))
+	assert.Contains(t, buf.String(), errAccessTokenRejected.Error())
+
+	tokenErr = errors.New("vault unavailable")
+	assert.ErrorIs(t, send(), tokenErr)
+}
+
+func TestTokenTransportBasicAuth(t *testing.T) {
+	reqCh := make(chan *http.Request, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		reqCh <- r
+	}))
+	t.Cleanup(srv.Close)
+
+	provider := TokenProviderFunc(func(context.Context) (string, error) {
+		return "secret", nil
+	})
+	client := exporterHTTPClient(&exporterConfig{}, newTokenTransport(buflogr.New(), provider, true))
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, srv.URL, http.NoBody)
+	require.NoError(t, err)
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	user, pass, ok := (<-reqCh).BasicAuth()
+	require.True(t, ok)
+	assert.Equal(t, "auth", user)
+	assert.Equal(t, "secret", pass)
+}
@@ -0,0 +1,686 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distro
+
+import (
+	"cmp"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// OTLP exporter timeouts, in milliseconds.
+const (
+	otelExporterOTLPTimeoutKey        = "OTEL_EXPORTER_OTLP_TIMEOUT"
+	otelExporterOTLPTracesTimeoutKey  = "OTEL_EXPORTER_OTLP_TRACES_TIMEOUT"
+	otelExporterOTLPMetricsTimeoutKey = "OTEL_EXPORTER_OTLP_METRICS_TIMEOUT"
+	otelExporterOTLPLogsTimeoutKey    = "OTEL_EXPORTER_OTLP_LOGS_TIMEOUT"
+)
+
+// Signal is a telemetry signal exported by the distro.
+type Signal string
+
+// Signals exported by the distro.
+const (
+	SignalTraces  Signal = "traces"
+	SignalMetrics Signal = "metrics"
+	SignalLogs    Signal = "logs"
+)
+
+// Compression is the compression of the exported data.
+type Compression string
+
+// Compressions supported by the OTLP exporters.
+const (
+	CompressionNone Compression = "none"
+	CompressionGzip Compression = "gzip"
+)
+
+// WithHTTPClient configures the exporters using an HTTP protocol to send
+// their requests with client. The client is used as is: the TLS
+// configuration, proxy, and timeout options do not apply to it.
+//
+// This option applies to the OTLP exporters of all signals and the Zipkin
+// exporter. It takes precedence over WithHTTPTransport.
+func WithHTTPClient(client *http.Client) Option {
+	return optionFunc(func(c *config) {
+		c.ExportConfig.HTTPClient = client
+	})
+}
+
+// WithHTTPTransport configures the exporters using an HTTP protocol to send
+// their requests with rt. The transport is used as is: the TLS configuration
+// and proxy options do not apply to it.
+//
+// This option applies to the OTLP exporters of all signals and the Zipkin
+// exporter.
+func WithHTTPTransport(rt http.RoundTripper) Option {
+	return optionFunc(func(c *config) {
+		c.ExportConfig.HTTPTransport = rt
+	})
+}
+
+// WithProxy configures the exporters using an HTTP protocol to send their
+// requests through the proxy returned by proxy, e.g. http.ProxyURL(u).
+//
+// If this option is not provided, the proxy is configured with the
+// HTTPS_PROXY, HTTP_PROXY, and NO_PROXY environment variables. gRPC exporters
+// only support these environment variables, or a proxy dialer passed with
+// WithGRPCDialOptions.
+func WithProxy(proxy func(*http.Request) (*url.URL, error)) Option {
+	return optionFunc(func(c *config) {
+		c.ExportConfig.Proxy = proxy
+	})
+}
+
+// WithGRPCDialOptions configures the exporters using the gRPC protocol to
+// create their connection with opts, in addition to the dial options the
+// distro sets.
+//
+// This option applies to the OTLP exporters of all signals. It can be used
+// multiple times.
+func WithGRPCDialOptions(opts ...grpc.DialOption) Option {
+	return optionFunc(func(c *config) {
+		c.ExportConfig.DialOptions = append(c.ExportConfig.DialOptions, opts...)
+	})
+}
+
+// WithExportTimeout configures the maximum duration of an export request of
+// signals. If no signal is passed, d applies to all signals. A non-positive d
+// is ignored.
+//
+// This option takes precedence over the OTEL_EXPORTER_OTLP_TIMEOUT and
+// OTEL_EXPORTER_OTLP_<SIGNAL>_TIMEOUT environment variables.
+func WithExportTimeout(d time.Duration, signals ...Signal) Option {
+	return optionFunc(func(c *config) {
+		if d <= 0 {
+			return
+		}
+		if c.ExportConfig.Timeouts == nil {
+			c.ExportConfig.Timeouts = make(map[Signal]time.Duration)
+		}
+		for _, s := range orAllSignals(signals) {
+			c.ExportConfig.Timeouts[s] = d
+		}
+	})
+}
+
+// WithExportCompression configures the compression of the data exported by
+// the OTLP exporters of signals. If no signal is passed, compression applies
+// to all signals.
+//
+// This option takes precedence over the OTEL_EXPORTER_OTLP_COMPRESSION and
+// OTEL_EXPORTER_OTLP_<SIGNAL>_COMPRESSION environment variables. A compression
+// other than CompressionGzip or CompressionNone is logged and ignored.
+func WithExportCompression(compression Compression, signals ...Signal) Option {
+	return optionFunc(func(c *config) {
+		if compression != CompressionGzip && compression != CompressionNone {
+			// Logged once all the options, including WithLogger, are applied.
+			c.optionErrs = append(c.optionErrs, fmt.Errorf("invalid compression: %q (supported: %q, %q)", compression, CompressionGzip, CompressionNone))
+			return
+		}
+		if c.ExportConfig.Compressions == nil {
+			c.ExportConfig.Compressions = make(map[Signal]Compression)
+		}
+		for _, s := range orAllSignals(signals) {
+			c.ExportConfig.Compressions[s] = compression
+		}
+	})
+}
+
+// orAllSignals returns signals, or all the signals if it is empty.
+func orAllSignals(signals []Signal) []Signal {
+	if len(signals) == 0 {
+		return []Signal{SignalTraces, SignalMetrics, SignalLogs}
+	}
+	return signals
+}
+
+// otlpTimeout returns the timeout of the OTLP export requests of s and where
+// it is configured. The timeout set with an option takes precedence over the
+// one of the signal timeout environment variable, timeoutKey, and the general
+// one.
+func otlpTimeout(l logr.Logger, c *exporterConfig, s Signal, timeoutKey string) (time.Duration, ConfigSource) {
+	if d, ok := c.Timeouts[s]; ok {
+		return d, SourceOption
+	}
+	d, src := defaultExportTimeout, SourceDefault
+	for _, key := range []string{otelExporterOTLPTimeoutKey, timeoutKey} {
+		if ms := envInt(l, key, -1); ms >= 0 {
+			d, src = time.Duration(ms)*time.Millisecond, SourceEnv
+		}
+	}
+	return d, src
+}
+
+// otlpCompression returns the compression of the OTLP export requests of s
+// and where it is configured. The compression set with an option takes
+// precedence over the one of the environment variables.
+func otlpCompression(c *exporterConfig, s Signal) (Compression, ConfigSource) {
+	if comp, ok := c.Compressions[s]; ok {
+		return comp, SourceOption
+	}
+	if _, v := otlpEnv(s, "COMPRESSION"); v != "" {
+		if strings.EqualFold(v, string(CompressionGzip)) {
+			return CompressionGzip, SourceEnv
+		}
+		return CompressionNone, SourceEnv
+	}
+	return CompressionNone, SourceDefault
+}
+
+// otlpEnv returns the key and value of the OTLP exporter environment variable
+// name of s, e.g. OTEL_EXPORTER_OTLP_TRACES_CERTIFICATE, or of the general one,
+// e.g. OTEL_EXPORTER_OTLP_CERTIFICATE, if the signal one is not set.
+func otlpEnv(s Signal, name string) (string, string) {
+	key := "OTEL_EXPORTER_OTLP_" + strings.ToUpper(string(s)) + "_" + name
+	if v := os.Getenv(key); v != "" {
+		return key, v
+	}
+	key = "OTEL_EXPORTER_OTLP_" + name
+	return key, os.Getenv(key)
+}
+
+// otlpSettings are the settings of the OTLP exporter of a signal resolved
+// from the options and environment variables. The exporter is configured
+// with them, and they are recorded to describe it.
+type otlpSettings struct {
+	signal   Signal
+	protocol ConfigValue
+	// realm is true if telemetry is sent to the Splunk Observability Cloud
+	// ingest of SPLUNK_REALM.
+	realm bool
+	// local is true if telemetry is sent to the default endpoint, a locally
+	// running collector assumed to be non-TLS.
+	local bool
+	// endpoint is the URL telemetry is sent to. The exporter is configured
+	// with its host and urlPath, unless host is empty and the exporter uses
+	// its default.
+	endpoint ConfigValue
+	host     string
+	urlPath  string
+	// headers are the headers set with the OTLP headers environment
+	// variables.
+	headers           map[string]string
+	timeout           time.Duration
+	timeoutSource     ConfigSource
+	compression       Compression
+	compressionSource ConfigSource
+}
+
+// newOTLPSettings returns the settings of the OTLP exporter of the signal of
+// k.
+func newOTLPSettings(l logr.Logger, c *exporterConfig, k signalKeys) otlpSettings {
+	st := otlpSettings{
+		signal:   k.signal,
+		protocol: ConfigValue{Value: otlpProtocol(l, k.protocolKey), Source: SourceDefault},
+		headers:  otlpEnvHeaders(k.signal),
+	}
+	if os.Getenv(k.protocolKey) != "" || os.Getenv(otelExporterOTLPProtocolKey) != "" {
+		st.protocol.Source = SourceEnv
+	}
+	st.resolveEndpoint(k)
+	st.timeout, st.timeoutSource = otlpTimeout(l, c, k.signal, k.timeoutKey)
+	st.compression, st.compressionSource = otlpCompression(c, k.signal)
+	return st
+}
+
+// resolveEndpoint resolves the endpoint of st. The SPLUNK_REALM ingest is
+// used if no OTLP endpoint environment variable is set.
+func (st *otlpSettings) resolveEndpoint(k signalKeys) {
+	if k.realmEndpoint != nil {
+		if realm := k.realmEndpoint(); realm != "" {
+			st.realm = true
+			if st.protocol.Value == otlpProtocolGRPC {
+				// Direct ingest only supports HTTP/protobuf, or HTTP/JSON if
+				// explicitly configured.
+				st.protocol = ConfigValue{Value: otlpProtocolHTTPProtobuf, Source: SourceEnv}
+			}
+			st.host, st.urlPath = realm, k.realmPath
+			st.endpoint = ConfigValue{Value: "https://" + realm + k.realmPath, Source: SourceEnv}
+			return
+		}
+	}
+
+	envKeys := []string{otelExporterOTLPEndpointKey, k.endpointKey}
+	if k.realmEndpoint != nil {
+		envKeys = append(envKeys, splunkRealmKey)
+	}
+	st.local = noneEnvVarSet(envKeys...)
+
+	grpc := st.protocol.Value == otlpProtocolGRPC
+	// The signal endpoint takes precedence over the general one.
+	for _, key := range []string{k.endpointKey, otelExporterOTLPEndpointKey} {
+		v := os.Getenv(key)
+		if v == "" {
+			continue
+		}
+		st.endpoint = ConfigValue{Value: v, Source: SourceEnv}
+		u, err := url.Parse(v)
+		if err != nil || u.Host == "" {
+			// Let the exporter report the invalid endpoint.
+			return
+		}
+		switch {
+		case grpc:
+			st.host = path.Join(u.Host, u.Path)
+			return
+		case key == k.endpointKey:
+			// The signal endpoint is used as is.
+			st.host, st.urlPath = u.Host, cmp.Or(u.Path, "/")
+		default:
+			// The general endpoint is the base URL of the signal paths.
+			st.host, st.urlPath = u.Host, path.Join(u.Path, k.urlPath)
+		}
+		u.Path, u.RawPath, u.RawQuery, u.Fragment = st.urlPath, "", "", ""
+		st.endpoint.Value = u.String()
+		return
+	}
+
+	scheme := "https://"
+	if st.local {
+		scheme = "http://"
+	}
+	if grpc {
+		st.endpoint = ConfigValue{Value: scheme + otlpGRPCDefaultEndpoint, Source: SourceDefault}
+	} else {
+		st.endpoint = ConfigValue{Value: scheme + otlpHTTPDefaultEndpoint + k.urlPath, Source: SourceDefault}
+	}
+}
+
+// requestHeaders returns the headers of the export requests of st: the
+// environment headers and the static access token of c.
+func (st otlpSettings) requestHeaders(c *exporterConfig) map[string]string {
+	headers := maps.Clone(st.headers)
+	// Splunk Observability Cloud ingest always expects the token header.
+	if c.TokenProvider == nil && (c.accessToken != "" || st.realm) {
+		if headers == nil {
+			headers = make(map[string]string)
+		}
+		headers[accessTokenHeader] = c.accessToken
+	}
+	return headers
+}
+
+// tls returns the transport security the exporter of st is configured with.
+func (st otlpSettings) tls(c *exporterConfig) ConfigValue {
+	switch {
+	case c.TLSConfig != nil:
+		return ConfigValue{Value: "custom", Source: SourceOption}
+	case st.protocol.Value != otlpProtocolGRPC && (c.HTTPClient != nil || c.HTTPTransport != nil):
+		// The client or transport is used as is, with its TLS configuration.
+		return ConfigValue{Value: "custom", Source: SourceOption}
+	case strings.HasPrefix(st.endpoint.Value, "http://"):
+		return ConfigValue{Value: "insecure", Source: st.endpoint.Source}
+	}
+	for _, name := range []string{"CERTIFICATE", "CLIENT_CERTIFICATE"} {
+		if _, v := otlpEnv(st.signal, name); v != "" {
+			return ConfigValue{Value: "custom", Source: SourceEnv}
+		}
+	}
+	return ConfigValue{Value: "system", Source: st.endpoint.Source}
+}
+
+// signalConfig returns the configuration of the exporter of st, with its
+// secrets redacted.
+func (st otlpSettings) signalConfig(c *exporterConfig) SignalConfig {
+	sc := SignalConfig{
+		Endpoint:    ConfigValue{Value: redactURL(st.endpoint.Value), Source: st.endpoint.Source},
+		Protocol:    st.protocol,
+		TLS:         st.tls(c),
+		AccessToken: describeToken(c),
+		Timeout:     ConfigValue{Value: st.timeout.String(), Source: st.timeoutSource},
+		Compression: ConfigValue{Value: string(st.compression), Source: st.compressionSource},
+	}
+	if len(st.headers) > 0 {
+		sc.Headers = ConfigValue{Value: redactHeaders(st.headers), Source: SourceEnv}
+	}
+	return sc
+}
+
+// otlpEnvHeaders returns the headers set with the
+// OTEL_EXPORTER_OTLP_<SIGNAL>_HEADERS environment variable, or with the
+// general OTEL_EXPORTER_OTLP_HEADERS one if it is not set. They are a comma
+// separated list of key=value pairs, the values being URL encoded. Invalid
+// pairs are ignored, they are reported by the exporters.
+func otlpEnvHeaders(s Signal) map[string]string {
+	_, v := otlpEnv(s, "HEADERS")
+	if v == "" {
+		return nil
+	}
+	headers := make(map[string]string)
+	for _, pair := range strings.Split(v, ",") {
+		key, value, ok := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" || strings.ContainsFunc(key, invalidHeaderKeyRune) {
+			continue
+		}
+		value, err := url.PathUnescape(value)
+		if err != nil {
+			continue
+		}
+		headers[key] = strings.TrimSpace(value)
+	}
+	return headers
+}
+
+// invalidHeaderKeyRune returns if r is not allowed in an HTTP header key.
+func invalidHeaderKeyRune(r rune) bool {
+	switch {
+	case r > unicode.MaxASCII:
+		return true
+	case unicode.IsLetter(r), unicode.IsDigit(r):
+		return false
+	default:
+		return !strings.ContainsRune("!#$%&'*+-.^_`|~", r)
+	}
+}
+
+// otlpEnvTLSConfig returns the TLS configuration of the OTLP exporters of s
+// set with the OTEL_EXPORTER_OTLP_CERTIFICATE,
+// OTEL_EXPORTER_OTLP_CLIENT_CERTIFICATE, and OTEL_EXPORTER_OTLP_CLIENT_KEY
+// environment variables, or their signal specific variants. It returns nil if
+// none are set.
+func otlpEnvTLSConfig(l logr.Logger, s Signal) *tls.Config {
+	var conf *tls.Config
+	if key, path := otlpEnv(s, "CERTIFICATE"); path != "" {
+		pool, err := certPool(path)
+		if err != nil {
+			l.Error(err, "invalid certificate, using the system certificates", "key", key)
+		} else {
+			conf = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+		}
+	}
+
+	certKey, certPath := otlpEnv(s, "CLIENT_CERTIFICATE")
+	_, keyPath := otlpEnv(s, "CLIENT_KEY")
+	if certPath != "" && keyPath != "" {
+		cert, err := tls.LoadX509KeyPair(certPath, keyPath)
+		if err != nil {
+			l.Error(err, "invalid client certificate, not using it", "key", certKey)
+			return conf
+		}
+		if conf == nil {
+			conf = &tls.Config{MinVersion: tls.VersionTLS12}
+		}
+		conf.Certificates = []tls.Certificate{cert}
+	}
+	return conf
+}
+
+// certPool returns a certificate pool containing the PEM encoded certificates
+// of the file at path.
+func certPool(path string) (*x509.CertPool, error) {
+	b, err := os.ReadFile(path) //nolint:gosec // The path is configured by the user.
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(b) {
+		return nil, fmt.Errorf("no certificate found in %s", path)
+	}
+	return pool, nil
+}
+
+// exportTimeout returns the timeout of the export requests of s set with an
+// option, or the default timeout.
+func exportTimeout(c *exporterConfig, s Signal) time.Duration {
+	if d, ok := c.Timeouts[s]; ok {
+		return d
+	}
+	return defaultExportTimeout
+}
+
+// customHTTP returns if the HTTP client of the exporters is customized with
+// options.
+func (c *exporterConfig) customHTTP() bool {
+	return c.HTTPClient != nil || c.HTTPTransport != nil || c.Proxy != nil
+}
+
+// grpcDialOptions returns the dial options of the OTLP gRPC exporters. The
+// exporters only keep the last dial options passed to them, so they all need
+// to be passed at once.
+func grpcDialOptions(l logr.Logger, c *exporterConfig) []grpc.DialOption {
+	var opts []grpc.DialOption
+	if c.TokenProvider != nil {
+		opts = append(opts, tokenDialOptions(l, c)...)
+	}
+	return append(opts, c.DialOptions...)
+}
+
+// otlpTracesHTTPOptions returns the options of the OTLP HTTP traces
+// exporter of st. The transport of its HTTP client is wrapped by wrappers
+// last.
+func otlpTracesHTTPOptions(l logr.Logger, c *exporterConfig, st otlpSettings, wrappers ...func(http.RoundTripper) http.RoundTripper) []otlptracehttp.Option {
+	var opts []otlptracehttp.Option
+	if st.host != "" {
+		opts = append(opts, otlptracehttp.WithEndpoint(st.host), otlptracehttp.WithURLPath(st.urlPath))
+	}
+	if headers := st.requestHeaders(c); len(headers) > 0 {
+		opts = append(opts, otlptracehttp.WithHeaders(headers))
+	}
+	if client := otlpHTTPClient(l, c, SignalTraces, st.protocol.Value, tracesMessages, wrappers...); client != nil {
+		if c.HTTPClient == nil {
+			client.Timeout = st.timeout
+		}
+		opts = append(opts, otlptracehttp.WithHTTPClient(client))
+	}
+	if c.TLSConfig != nil {
+		opts = append(opts, otlptracehttp.WithTLSClientConfig(c.TLSConfig))
+	} else if st.local {
+		// Assume that the default endpoint (local collector) is non-TLS.
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+	if st.timeoutSource != SourceDefault {
+		opts = append(opts, otlptracehttp.WithTimeout(st.timeout))
+	}
+	if st.compressionSource != SourceDefault {
+		v := otlptracehttp.NoCompression
+		if st.compression == CompressionGzip {
+			v = otlptracehttp.GzipCompression
+		}
+		opts = append(opts, otlptracehttp.WithCompression(v))
+	}
+	return opts
+}
+
+// grpcCompressor returns the compressor the OTLP gRPC exporter of s needs to
+// be configured with, if any.
+func grpcCompressor(c *exporterConfig, s Signal) (string, bool) {
+	comp, src := otlpCompression(c, s)
+	if comp == CompressionNone {
+		// The exporters report any compressor other than gzip as invalid
+		// before falling back to no compression, which is also their default.
+		// Only set it to override the compression environment variables.
+		_, v := otlpEnv(s, "COMPRESSION")
+		return string(comp), src == SourceOption && v != ""
+	}
+	return string(comp), true
+}
+
+// otlpTracesGRPCOptions returns the options of the OTLP gRPC traces
+// exporter of st.
+func otlpTracesGRPCOptions(l logr.Logger, c *exporterConfig, st otlpSettings) []otlptracegrpc.Option {
+	var opts []otlptracegrpc.Option
+	if st.host != "" {
+		opts = append(opts, otlptracegrpc.WithEndpoint(st.host))
+	}
+	if headers := st.requestHeaders(c); len(headers) > 0 {
+		opts = append(opts, otlptracegrpc.WithHeaders(headers))
+	}
+	if dialOpts := grpcDialOptions(l, c); len(dialOpts) > 0 {
+		opts = append(opts, otlptracegrpc.WithDialOption(dialOpts...))
+	}
+	if c.TLSConfig != nil {
+		opts = append(opts, otlptracegrpc.WithTLSCredentials(credentials.NewTLS(c.TLSConfig)))
+	} else if st.local {
+		// Assume that the default endpoint (local collector) is non-TLS.
+		opts = append(opts, otlptracegrpc.WithTLSCredentials(insecure.NewCredentials()))
+	}
+	if st.timeoutSource != SourceDefault {
+		opts = append(opts, otlptracegrpc.WithTimeout(st.timeout))
+	}
+	if comp, ok := grpcCompressor(c, SignalTraces); ok {
+		opts = append(opts, otlptracegrpc.WithCompressor(comp))
+	}
+	return opts
+}
+
+// otlpMetricsHTTPOptions returns the options of the OTLP HTTP metrics
+// exporter of st. The transport of its HTTP client is wrapped by wrappers
+// last.
+func otlpMetricsHTTPOptions(l logr.Logger, c *exporterConfig, st otlpSettings, wrappers ...func(http.RoundTripper) http.RoundTripper) []otlpmetrichttp.Option {
+	var opts []otlpmetrichttp.Option
+	if st.host != "" {
+		opts = append(opts, otlpmetrichttp.WithEndpoint(st.host), otlpmetrichttp.WithURLPath(st.urlPath))
+	}
+	if headers := st.requestHeaders(c); len(headers) > 0 {
+		opts = append(opts, otlpmetrichttp.WithHeaders(headers))
+	}
+	if client := otlpHTTPClient(l, c, SignalMetrics, st.protocol.Value, metricsMessages, wrappers...); client != nil {
+		if c.HTTPClient == nil {
+			client.Timeout = st.timeout
+		}
+		opts = append(opts, otlpmetrichttp.WithHTTPClient(client))
+	}
+	if c.TLSConfig != nil {
+		opts = append(opts, otlpmetrichttp.WithTLSClientConfig(c.TLSConfig))
+	} else if st.local {
+		// Assume that the default endpoint (local collector) is non-TLS.
+		opts = append(opts, otlpmetrichttp.WithInsecure())
+	}
+	if st.timeoutSource != SourceDefault {
+		opts = append(opts, otlpmetrichttp.WithTimeout(st.timeout))
+	}
+	if st.compressionSource != SourceDefault {
+		v := otlpmetrichttp.NoCompression
+		if st.compression == CompressionGzip {
+			v = otlpmetrichttp.GzipCompression
+		}
+		opts = append(opts, otlpmetrichttp.WithCompression(v))
+	}
+	return opts
+}
+
+// otlpMetricsGRPCOptions returns the options of the OTLP gRPC metrics
+// exporter of st.
+func otlpMetricsGRPCOptions(l logr.Logger, c *exporterConfig, st otlpSettings) []otlpmetricgrpc.Option {
+	var opts []otlpmetricgrpc.Option
+	if st.host != "" {
+		opts = append(opts, otlpmetricgrpc.WithEndpoint(st.host))
+	}
+	if headers := st.requestHeaders(c); len(headers) > 0 {
+		opts = append(opts, otlpmetricgrpc.WithHeaders(headers))
+	}
+	if dialOpts := grpcDialOptions(l, c); len(dialOpts) > 0 {
+		opts = append(opts, otlpmetricgrpc.WithDialOption(dialOpts...))
+	}
+	if c.TLSConfig != nil {
+		opts = append(opts, otlpmetricgrpc.WithTLSCredentials(credentials.NewTLS(c.TLSConfig)))
+	} else if st.local {
+		// Assume that the default endpoint (local collector) is non-TLS.
+		opts = append(opts, otlpmetricgrpc.WithTLSCredentials(insecure.NewCredentials()))
+	}
+	if st.timeoutSource != SourceDefault {
+		opts = append(opts, otlpmetricgrpc.WithTimeout(st.timeout))
+	}
+	if comp, ok := grpcCompressor(c, SignalMetrics); ok {
+		opts = append(opts, otlpmetricgrpc.WithCompressor(comp))
+	}
+	return opts
+}
+
+// otlpLogsHTTPOptions returns the options of the OTLP HTTP logs
+// exporter of st. The transport of its HTTP client is wrapped by wrappers
+// last.
+func otlpLogsHTTPOptions(l logr.Logger, c *exporterConfig, st otlpSettings, wrappers ...func(http.RoundTripper) http.RoundTripper) []otlploghttp.Option {
+	var opts []otlploghttp.Option
+	if st.host != "" {
+		opts = append(opts, otlploghttp.WithEndpoint(st.host), otlploghttp.WithURLPath(st.urlPath))
+	}
+	if headers := st.requestHeaders(c); len(headers) > 0 {
+		opts = append(opts, otlploghttp.WithHeaders(headers))
+	}
+	if client := otlpHTTPClient(l, c, SignalLogs, st.protocol.Value, logsMessages, wrappers...); client != nil {
+		if c.HTTPClient == nil {
+			client.Timeout = st.timeout
+		}
+		opts = append(opts, otlploghttp.WithHTTPClient(client))
+	}
+	if c.TLSConfig != nil {
+		opts = append(opts, otlploghttp.WithTLSClientConfig(c.TLSConfig))
+	} else if st.local {
+		// Assume that the default endpoint (local collector) is non-TLS.
+		opts = append(opts, otlploghttp.WithInsecure())
+	}
+	if st.timeoutSource != SourceDefault {
+		opts = append(opts, otlploghttp.WithTimeout(st.timeout))
+	}
+	if st.compressionSource != SourceDefault {
+		v := otlploghttp.NoCompression
+		if st.compression == CompressionGzip {
+			v = otlploghttp.GzipCompression
+		}
+		opts = append(opts, otlploghttp.WithCompression(v))
+	}
+	return opts
+}
+
+// otlpLogsGRPCOptions returns the options of the OTLP gRPC logs
+// exporter of st.
+func otlpLogsGRPCOptions(l logr.Logger, c *exporterConfig, st otlpSettings) []otlploggrpc.Option {
+	var opts []otlploggrpc.Option
+	if st.host != "" {
+		opts = append(opts, otlploggrpc.WithEndpoint(st.host))
+	}
+	if headers := st.requestHeaders(c); len(headers) > 0 {
+		opts = append(opts, otlploggrpc.WithHeaders(headers))
+	}
+	if dialOpts := grpcDialOptions(l, c); len(dialOpts) > 0 {
+		opts = append(opts, otlploggrpc.WithDialOption(dialOpts...))
+	}
+	if c.TLSConfig != nil {
+		opts = append(opts, otlploggrpc.WithTLSCredentials(credentials.NewTLS(c.TLSConfig)))
+	} else if st.local {
+		// Assume that the default endpoint (local collector) is non-TLS.
+		opts = append(opts, otlploggrpc.WithTLSCredentials(insecure.NewCredentials()))
+	}
+	if st.timeoutSource != SourceDefault {
+		opts = append(opts, otlploggrpc.WithTimeout(st.timeout))
+	}
+	if comp, ok := grpcCompressor(c, SignalLogs); ok {
+		opts = append(opts, otlploggrpc.WithCompressor(comp))
+	}
+	return opts
+}
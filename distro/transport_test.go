@@ -0,0 +1,345 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distro
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tonglil/buflogr"
+	"go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/grpc"
+)
+
+// recordingTransport is an http.RoundTripper recording the requests it
+// receives.
+type recordingTransport struct {
+	reqs chan *http.Request
+}
+
+func (t recordingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	t.reqs <- r
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/x-protobuf"}},
+		Body:       io.NopCloser(strings.NewReader("")),
+		Request:    r,
+	}, nil
+}
+
+func TestExportTransportOptions(t *testing.T) {
+	dial := grpc.WithUserAgent("test")
+	c := newConfig(
+		WithExportTimeout(time.Second),
+		WithExportTimeout(2*time.Second, SignalLogs),
+		WithExportTimeout(0, SignalTraces),
+		WithExportCompression(CompressionGzip, SignalMetrics),
+		WithGRPCDialOptions(dial),
+		WithGRPCDialOptions(dial),
+	)
+	assert.Equal(t, map[Signal]time.Duration{
+		SignalTraces:  time.Second,
+		SignalMetrics: time.Second,
+		SignalLogs:    2 * time.Second,
+	}, c.ExportConfig.Timeouts)
+	assert.Equal(t, map[Signal]Compression{SignalMetrics: CompressionGzip}, c.ExportConfig.Compressions)
+	assert.Len(t, c.ExportConfig.DialOptions, 2)
+}
+
+func TestWithExportCompressionInvalid(t *testing.T) {
+	var buf bytes.Buffer
+	c := newConfig(
+		WithExportCompression(CompressionGzip),
+		WithExportCompression("zstd", SignalTraces),
+		// Logged with the logger set after the option.
+		WithLogger(buflogr.NewWithBuffer(&buf)),
+	)
+	assert.Equal(t, map[Signal]Compression{
+		SignalTraces:  CompressionGzip,
+		SignalMetrics: CompressionGzip,
+		SignalLogs:    CompressionGzip,
+	}, c.ExportConfig.Compressions)
+	assert.Contains(t, buf.String(), `invalid compression: "zstd"`)
+}
+
+func TestGRPCCompressor(t *testing.T) {
+	c := &exporterConfig{Compressions: map[Signal]Compression{
+		SignalTraces:  CompressionGzip,
+		SignalMetrics: CompressionNone,
+	}}
+	comp, ok := grpcCompressor(c, SignalTraces)
+	assert.True(t, ok)
+	assert.Equal(t, "gzip", comp)
+
+	_, ok = grpcCompressor(c, SignalLogs)
+	assert.False(t, ok, "not configured")
+
+	_, ok = grpcCompressor(c, SignalMetrics)
+	assert.False(t, ok, "no compression is the default")
+
+	t.Setenv("OTEL_EXPORTER_OTLP_COMPRESSION", "gzip")
+	comp, ok = grpcCompressor(c, SignalMetrics)
+	assert.True(t, ok, "override the environment")
+	assert.Equal(t, "none", comp)
+}
+
+func TestOTLPTimeout(t *testing.T) {
+	timeout := func(c *exporterConfig, s Signal, key string) time.Duration {
+		d, _ := otlpTimeout(logr.Discard(), c, s, key)
+		return d
+	}
+	c := &exporterConfig{}
+	d, src := otlpTimeout(logr.Discard(), c, SignalTraces, otelExporterOTLPTracesTimeoutKey)
+	assert.Equal(t, defaultExportTimeout, d)
+	assert.Equal(t, SourceDefault, src)
+
+	t.Setenv(otelExporterOTLPTimeoutKey, "3000")
+	assert.Equal(t, 3*time.Second, timeout(c, SignalTraces, otelExporterOTLPTracesTimeoutKey))
+
+	t.Setenv(otelExporterOTLPTracesTimeoutKey, "500")
+	d, src = otlpTimeout(logr.Discard(), c, SignalTraces, otelExporterOTLPTracesTimeoutKey)
+	assert.Equal(t, 500*time.Millisecond, d)
+	assert.Equal(t, SourceEnv, src)
+	assert.Equal(t, 3*time.Second, timeout(c, SignalLogs, otelExporterOTLPLogsTimeoutKey))
+
+	c.Timeouts = map[Signal]time.Duration{SignalTraces: time.Minute}
+	d, src = otlpTimeout(logr.Discard(), c, SignalTraces, otelExporterOTLPTracesTimeoutKey)
+	assert.Equal(t, time.Minute, d)
+	assert.Equal(t, SourceOption, src)
+}
+
+func TestExporterHTTPClient(t *testing.T) {
+	wrapped := func(http.RoundTripper) http.RoundTripper {
+		return recordingTransport{reqs: make(chan *http.Request)}
+	}
+
+	t.Run("client", func(t *testing.T) {
+		orig := &http.Client{Timeout: time.Minute}
+		client := exporterHTTPClient(&exporterConfig{HTTPClient: orig}, wrapped)
+		assert.NotSame(t, orig, client)
+		assert.Nil(t, orig.Transport, "client modified")
+		assert.Equal(t, time.Minute, client.Timeout)
+		assert.IsType(t, recordingTransport{}, client.Transport)
+	})
+
+	t.Run("transport", func(t *testing.T) {
+		rt := recordingTransport{}
+		client := exporterHTTPClient(&exporterConfig{HTTPTransport: rt})
+		assert.Equal(t, rt, client.Transport)
+	})
+
+	t.Run("proxy", func(t *testing.T) {
+		proxyURL := &url.URL{Scheme: "http", Host: "proxy:3128"}
+		client := exporterHTTPClient(&exporterConfig{Proxy: http.ProxyURL(proxyURL)})
+		transport, ok := client.Transport.(*http.Transport)
+		require.True(t, ok)
+		got, err := transport.Proxy(&http.Request{URL: &url.URL{Scheme: "https", Host: "ingest"}})
+		require.NoError(t, err)
+		assert.Equal(t, proxyURL, got)
+	})
+}
+
+func TestOTLPHTTPExportersTransport(t *testing.T) {
+	t.Setenv(otelExporterOTLPProtocolKey, otlpProtocolHTTPProtobuf)
+	t.Setenv(otelExporterOTLPEndpointKey, "http://collector:4318")
+
+	rt := recordingTransport{reqs: make(chan *http.Request, 1)}
+	c := newConfig(
+		WithHTTPTransport(rt),
+		WithExportCompression(CompressionGzip, SignalLogs),
+	).ExportConfig
+	l := logr.Discard()
+	ctx := context.Background()
+
+	traceExp, err := newOTLPTracesExporter(l, c)
+	require.NoError(t, err)
+	stub := tracetest.SpanStub{Name: "span"}
+	require.NoError(t, traceExp.ExportSpans(ctx, []trace.ReadOnlySpan{stub.Snapshot()}))
+	req := <-rt.reqs
+	assert.Equal(t, "/v1/traces", req.URL.Path)
+	assert.Empty(t, req.Header.Get("Content-Encoding"))
+	require.NoError(t, traceExp.Shutdown(ctx))
+
+	metricExp, err := newOTLPMetricsExporter(l, c)
+	require.NoError(t, err)
+	require.NoError(t, metricExp.Export(ctx, &metricdata.ResourceMetrics{}))
+	assert.Equal(t, "/v1/metrics", (<-rt.reqs).URL.Path)
+	require.NoError(t, metricExp.Shutdown(ctx))
+
+	logExp, err := newOTLPLogExporter(l, c)
+	require.NoError(t, err)
+	require.NoError(t, logExp.Export(ctx, []log.Record{{}}))
+	req = <-rt.reqs
+	assert.Equal(t, "/v1/logs", req.URL.Path)
+	assert.Equal(t, "gzip", req.Header.Get("Content-Encoding"))
+	require.NoError(t, logExp.Shutdown(ctx))
+}
+
+func TestOTLPHTTPExporterHeaders(t *testing.T) {
+	t.Setenv(otelExporterOTLPProtocolKey, otlpProtocolHTTPProtobuf)
+	t.Setenv(otelExporterOTLPTracesEndpointKey, "http://collector:4318")
+	t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "X-Scope=tenant%201")
+	t.Setenv(accessTokenKey, "token")
+
+	rt := recordingTransport{reqs: make(chan *http.Request, 1)}
+	c := newConfig(WithHTTPTransport(rt)).ExportConfig
+	ctx := context.Background()
+
+	exp, err := newOTLPTracesExporter(logr.Discard(), c)
+	require.NoError(t, err)
+	stub := tracetest.SpanStub{Name: "span"}
+	require.NoError(t, exp.ExportSpans(ctx, []trace.ReadOnlySpan{stub.Snapshot()}))
+	req := <-rt.reqs
+	assert.Equal(t, "/", req.URL.Path, "signal endpoint used as is")
+	assert.Equal(t, "tenant 1", req.Header.Get("X-Scope"))
+	assert.Equal(t, "token", req.Header.Get(accessTokenHeader))
+	require.NoError(t, exp.Shutdown(ctx))
+
+	assert.Equal(t, ConfigValue{Value: "http://collector:4318/", Source: SourceEnv}, c.resolved[SignalTraces].Endpoint)
+	assert.Equal(t, ConfigValue{Value: "X-Scope=REDACTED", Source: SourceEnv}, c.resolved[SignalTraces].Headers)
+}
+
+func TestOTLPSettingsEndpoint(t *testing.T) {
+	testCases := []struct {
+		name     string
+		env      map[string]string
+		protocol string
+		host     string
+		urlPath  string
+		endpoint ConfigValue
+		local    bool
+	}{
+		{
+			name:     "default gRPC",
+			protocol: otlpProtocolGRPC,
+			endpoint: ConfigValue{Value: "http://localhost:4317", Source: SourceDefault},
+			local:    true,
+		},
+		{
+			name:     "default HTTP",
+			protocol: otlpProtocolHTTPProtobuf,
+			endpoint: ConfigValue{Value: "http://localhost:4318/v1/traces", Source: SourceDefault},
+			local:    true,
+		},
+		{
+			name:     "general gRPC",
+			env:      map[string]string{otelExporterOTLPEndpointKey: "https://collector:4317"},
+			protocol: otlpProtocolGRPC,
+			host:     "collector:4317",
+			endpoint: ConfigValue{Value: "https://collector:4317", Source: SourceEnv},
+		},
+		{
+			name:     "general HTTP",
+			env:      map[string]string{otelExporterOTLPEndpointKey: "https://collector:4318/otlp/"},
+			protocol: otlpProtocolHTTPProtobuf,
+			host:     "collector:4318",
+			urlPath:  "/otlp/v1/traces",
+			endpoint: ConfigValue{Value: "https://collector:4318/otlp/v1/traces", Source: SourceEnv},
+		},
+		{
+			name: "signal HTTP",
+			env: map[string]string{
+				otelExporterOTLPEndpointKey:       "https://general:4318",
+				otelExporterOTLPTracesEndpointKey: "https://traces:4318/custom",
+			},
+			protocol: otlpProtocolHTTPProtobuf,
+			host:     "traces:4318",
+			urlPath:  "/custom",
+			endpoint: ConfigValue{Value: "https://traces:4318/custom", Source: SourceEnv},
+		},
+		{
+			name:     "realm",
+			env:      map[string]string{splunkRealmKey: "us0"},
+			protocol: otlpProtocolHTTPProtobuf,
+			host:     "ingest.us0.observability.splunkcloud.com",
+			urlPath:  otlpRealmTracesEndpointPath,
+			endpoint: ConfigValue{Value: "https://ingest.us0.observability.splunkcloud.com/v2/trace/otlp", Source: SourceEnv},
+		},
+		{
+			name:     "realm none",
+			env:      map[string]string{splunkRealmKey: noneValue},
+			protocol: otlpProtocolGRPC,
+			endpoint: ConfigValue{Value: "https://localhost:4317", Source: SourceDefault},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+			t.Setenv(otelExporterOTLPProtocolKey, tc.protocol)
+
+			st := newOTLPSettings(logr.Discard(), &exporterConfig{}, tracesKeys)
+			assert.Equal(t, tc.host, st.host)
+			assert.Equal(t, tc.urlPath, st.urlPath)
+			assert.Equal(t, tc.endpoint, st.endpoint)
+			assert.Equal(t, tc.local, st.local)
+		})
+	}
+}
+
+func TestOTLPEnvHeaders(t *testing.T) {
+	assert.Nil(t, otlpEnvHeaders(SignalTraces))
+
+	t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", " key1 = value%201 ,key2=a=b,missing,in valid=x,=empty")
+	assert.Equal(t, map[string]string{"key1": "value 1", "key2": "a=b"}, otlpEnvHeaders(SignalTraces))
+
+	// The signal headers replace the general ones.
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_HEADERS", "key3=value3")
+	assert.Equal(t, map[string]string{"key3": "value3"}, otlpEnvHeaders(SignalTraces))
+	assert.Equal(t, map[string]string{"key1": "value 1", "key2": "a=b"}, otlpEnvHeaders(SignalMetrics))
+}
+
+func TestOTLPGRPCExporterDialOptions(t *testing.T) {
+	t.Setenv(otelExporterOTLPEndpointKey, "http://127.0.0.1:4317")
+
+	dialed := make(chan string, 1)
+	dialer := func(_ context.Context, addr string) (net.Conn, error) {
+		select {
+		case dialed <- addr:
+		default:
+		}
+		return nil, errors.New("dial disabled")
+	}
+	c := newConfig(
+		WithGRPCDialOptions(grpc.WithContextDialer(dialer)),
+	).ExportConfig
+
+	exp, err := newOTLPTracesExporter(logr.Discard(), c)
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, exp.Shutdown(context.Background())) })
+
+	// Bound the retries of the failing export.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	stub := tracetest.SpanStub{Name: "span"}
+	assert.Error(t, exp.ExportSpans(ctx, []trace.ReadOnlySpan{stub.Snapshot()}))
+	assert.Equal(t, "127.0.0.1:4317", <-dialed)
+}
@@ -28,8 +28,6 @@ import (
 	"net"
 	"net/http"
 	"net/http/httputil"
-	"os"
-	"os/signal"
 	"time"
 
 	"go.opentelemetry.io/contrib/bridges/otelslog"
@@ -49,17 +47,16 @@ func main() {
 }
 
 func run() (err error) {
-	// handle CTRL+C gracefully
-	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
-	defer stop()
-
 	// initialize Splunk OTel distro
 	sdk, err := distro.Run()
 	if err != nil {
 		return err
 	}
+
+	// handle CTRL+C and SIGTERM gracefully, flushing all telemetry on exit
+	ctx, shutdown := sdk.ShutdownOnSignal(context.Background())
 	defer func() {
-		err = errors.Join(err, sdk.Shutdown(context.Background()))
+		err = errors.Join(err, shutdown())
 	}()
 
 	logger := slog.New(otelslog.NewHandler("github.com/signalfx/splunk-otel-go/example"))
@@ -41,6 +41,11 @@ type config struct {
 
 	DBName           string
 	ConnectionString string
+
+	// conn and dbSystem are the registered connection settings the database
+	// attributes are set from once all options are applied.
+	conn     *ConnectionConfig
+	dbSystem DBSystem
 }
 
 func newConfig(options ...Option) config {
@@ -50,6 +55,8 @@ func newConfig(options ...Option) config {
 			internal.OptionFunc(
 				func(c *internal.Config) {
 					c.Version = Version()
+					c.SpanSemconv = c.DBSemconv
+					c.RequireParentSpan = internal.RequireParentSpanFromEnv()
 					c.DefaultStartOpts = []trace.SpanStartOption{
 						// From the specification: span kind MUST always be CLIENT.
 						trace.WithSpanKind(trace.SpanKindClient),
@@ -64,13 +71,33 @@ func newConfig(options ...Option) config {
 			o.apply(&c)
 		}
 	}
+	// The options may have set the Tracer or Meter of a disabled
+	// instrumentation.
+	c.ApplyEnabled()
+
+	if c.conn != nil {
+		attrs, err := c.conn.attributes(c.PeerServices)
+		if err != nil {
+			otel.Handle(err)
+		}
+		attrs = append(attrs, c.dbSystem.Attribute())
+		c.DefaultStartOpts = append(c.DefaultStartOpts, trace.WithAttributes(c.DBAttributes(attrs...)...))
+	}
 
 	return c
 }
 
-// withSpan wraps the function f with a span.
-func (c config) withSpan(ctx context.Context, m moniker.Span, f func(context.Context) error, opts ...trace.SpanStartOption) error {
-	return c.WithSpan(ctx, c.spanName(m), f, opts...)
+// withSpan wraps the function f with a span. If query is not empty, it is
+// recorded as the statement of the span.
+func (c config) withSpan(ctx context.Context, m moniker.Span, query string, f func(context.Context) error, opts ...trace.SpanStartOption) error {
+	if query != "" {
+		opts = append(opts, trace.WithAttributes(c.DBAttributes(semconv.DBStatementKey.String(query))...))
+	}
+	op := internal.Operation{
+		Name:    c.spanName(m),
+		Details: Operation{Call: m.String(), Statement: query, DBName: c.DBName},
+	}
+	return c.WithOperation(ctx, op, f, opts...)
 }
 
 // spanName returns the OpenTelemetry compliant span name.
@@ -102,6 +129,17 @@ func (c config) spanName(m moniker.Span) string {
 	return m.String()
 }
 
+// Operation describes a database/sql call traced by this instrumentation
+// library.
+type Operation struct {
+	// Call is the name of the traced call (e.g. "Query", "Exec", "Ping").
+	Call string
+	// Statement is the SQL statement of the call, if any.
+	Statement string
+	// DBName is the name of the database being accessed, if known.
+	DBName string
+}
+
 // Option applies options to a tracing configuration.
 type Option interface {
 	apply(*config)
@@ -139,6 +177,40 @@ func WithAttributes(attr []attribute.KeyValue) Option {
 	return optionConv{iOpt: internal.WithAttributes(attr)}
 }
 
+// WithSpanNameFormatter returns an Option that sets f to name the spans
+// created for database calls. If f returns an empty string the default span
+// name, the database name if known or the call name otherwise, is used.
+func WithSpanNameFormatter(f func(Operation) string) Option {
+	return optionConv{iOpt: internal.WithSpanNameFormatter(f)}
+}
+
+// WithFilter returns an Option that adds f to the filters of the traced
+// database calls. Calls f returns false for, e.g. "Ping" or "Reset", are not
+// traced.
+func WithFilter(f func(Operation) bool) Option {
+	return optionConv{iOpt: internal.WithFilter(f)}
+}
+
+// WithRequireParentSpan returns an Option that sets if database calls are only
+// traced when their context contains a valid parent span. Database calls
+// performed outside of any trace, e.g. by background pollers, are then not
+// traced. The default is true if the
+// SPLUNK_INSTRUMENTATION_REQUIRE_PARENT_SPAN environment variable is set to
+// "true".
+func WithRequireParentSpan(require bool) Option {
+	return optionConv{iOpt: internal.WithRequireParentSpan(require)}
+}
+
+// WithPeerServiceMapping returns an Option that maps database hosts to the
+// logical name of the service they are part of, set as the peer.service
+// attribute of the spans. Hosts are identified by their name or IP address,
+// optionally followed by ":<port>". The entries of m take precedence over the
+// ones set with the OTEL_INSTRUMENTATION_COMMON_PEER_SERVICE_MAPPING
+// environment variable.
+func WithPeerServiceMapping(m map[string]string) Option {
+	return optionConv{iOpt: internal.WithPeerServiceMapping(m)}
+}
+
 // withRegistrationConfig returns an Option that sets database attributes
 // required and recommended by the OpenTelemetry semantic conventions based on
 // the information instrumentation registered.
@@ -160,16 +232,11 @@ func withRegistrationConfig(driverName, dataSourceName string) Option {
 		connCfg, _ = urlDSNParse(dataSourceName)
 	}
 
-	attrs, err := connCfg.Attributes()
-	if err != nil {
-		otel.Handle(err)
-	}
-	attrs = append(attrs, regCfg.DBSystem.Attribute())
-
 	return optionFunc(func(c *config) {
 		c.DBName = connCfg.Name
 		c.ConnectionString = connCfg.ConnectionString
-		c.DefaultStartOpts = append(c.DefaultStartOpts, trace.WithAttributes(attrs...))
+		c.conn = &connCfg
+		c.dbSystem = regCfg.DBSystem
 	})
 }
 
@@ -198,7 +265,17 @@ type ConnectionConfig struct {
 // OpenTelemetry semantic coventions. If the settings do not conform to
 // OpenTelemetry requirements an error is returned with a partial list of
 // attributes that do conform.
+//
+// The peer.service attribute is included if the Host is mapped to a service
+// with the OTEL_INSTRUMENTATION_COMMON_PEER_SERVICE_MAPPING environment
+// variable.
 func (c ConnectionConfig) Attributes() ([]attribute.KeyValue, error) { //nolint: gocritic // This is short lived, pass the type.
+	return c.attributes(internal.PeerServiceMappingFromEnv())
+}
+
+// attributes returns the connection settings as attributes, including the
+// peer.service the Host is mapped to by peerServices.
+func (c ConnectionConfig) attributes(peerServices internal.PeerServiceMapping) ([]attribute.KeyValue, error) { //nolint: gocritic // This is short lived, pass the type.
 	var attrs []attribute.KeyValue
 	var errs []string
 	if c.Name != "" {
@@ -222,6 +299,7 @@ func (c ConnectionConfig) Attributes() ([]attribute.KeyValue, error) { //nolint:
 				attrs = append(attrs, semconv.NetPeerPortKey.Int(c.Port))
 			}
 		}
+		attrs = append(attrs, peerServices.Attributes(c.Host, c.Port)...)
 	} else {
 		errs = append(errs, "missing required peer IP or hostname")
 	}
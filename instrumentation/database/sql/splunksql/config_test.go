@@ -20,6 +20,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
 
 	"github.com/signalfx/splunk-otel-go/instrumentation/database/sql/splunksql/internal/moniker"
 )
@@ -115,3 +116,17 @@ func TestSpanName(t *testing.T) {
 	c.DBName = dbname
 	assert.Equal(t, dbname, c.spanName(m))
 }
+
+func TestConnectionConfigPeerService(t *testing.T) {
+	t.Setenv("OTEL_INSTRUMENTATION_COMMON_PEER_SERVICE_MAPPING", "db.local=users")
+
+	attrs, err := ConnectionConfig{Host: "db.local", Port: 5432}.Attributes()
+	assert.NoError(t, err)
+	assert.Contains(t, attrs, semconv.PeerServiceKey.String("users"))
+
+	attrs, err = ConnectionConfig{Host: "other.local"}.Attributes()
+	assert.NoError(t, err)
+	for _, a := range attrs {
+		assert.NotEqual(t, semconv.PeerServiceKey, a.Key)
+	}
+}
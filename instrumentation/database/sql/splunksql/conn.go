@@ -19,9 +19,6 @@ import (
 	"database/sql/driver"
 	"errors"
 
-	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
-	"go.opentelemetry.io/otel/trace"
-
 	"github.com/signalfx/splunk-otel-go/instrumentation/database/sql/splunksql/internal/moniker"
 )
 
@@ -54,7 +51,7 @@ func (c *otelConn) Ping(ctx context.Context) error {
 	if !ok {
 		return driver.ErrSkip
 	}
-	return c.config.withSpan(ctx, moniker.Ping, pinger.Ping)
+	return c.config.withSpan(ctx, moniker.Ping, "", pinger.Ping)
 }
 
 // Exec calls the wrapped Connection Exec method if implemented.
@@ -92,7 +89,7 @@ func (c *otelConn) ExecContext(ctx context.Context, query string, args []driver.
 		}
 	}
 
-	err := c.config.withSpan(ctx, moniker.Exec, f, trace.WithAttributes(semconv.DBStatementKey.String(query)))
+	err := c.config.withSpan(ctx, moniker.Exec, query, f)
 	return res, err
 }
 
@@ -131,7 +128,7 @@ func (c *otelConn) QueryContext(ctx context.Context, query string, args []driver
 		}
 	}
 
-	err := c.config.withSpan(ctx, moniker.Query, f, trace.WithAttributes(semconv.DBStatementKey.String(query)))
+	err := c.config.withSpan(ctx, moniker.Query, query, f)
 	if err != nil {
 		return nil, err
 	}
@@ -159,7 +156,7 @@ func (c *otelConn) PrepareContext(ctx context.Context, query string) (driver.Stm
 		}
 	}
 
-	err := c.config.withSpan(ctx, moniker.Prepare, f, trace.WithAttributes(semconv.DBStatementKey.String(query)))
+	err := c.config.withSpan(ctx, moniker.Prepare, query, f)
 	if err != nil {
 		return nil, err
 	}
@@ -187,7 +184,7 @@ func (c *otelConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.T
 		}
 	}
 
-	err := c.config.withSpan(ctx, moniker.Begin, f)
+	err := c.config.withSpan(ctx, moniker.Begin, "", f)
 	if err != nil {
 		return nil, err
 	}
@@ -201,7 +198,7 @@ func (c *otelConn) ResetSession(ctx context.Context) error {
 		return driver.ErrSkip
 	}
 
-	return c.config.withSpan(ctx, moniker.Reset, resetter.ResetSession)
+	return c.config.withSpan(ctx, moniker.Reset, "", resetter.ResetSession)
 }
 
 // copied from stdlib database/sql package: src/database/sql/ctxutil.go
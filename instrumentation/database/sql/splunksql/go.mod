@@ -14,8 +14,12 @@ require (
 	github.com/cespare/xxhash/v2 v2.3.0 // indirect
 	github.com/go-logr/logr v1.4.4 // indirect
 	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/signalfx/splunk-otel-go v1.34.0 // indirect
 	go.opentelemetry.io/auto/sdk v1.2.1 // indirect
 	go.yaml.in/yaml/v3 v3.0.5 // indirect
 )
 
-replace github.com/signalfx/splunk-otel-go/instrumentation/internal => ../../../internal
+replace (
+	github.com/signalfx/splunk-otel-go => ../../../..
+	github.com/signalfx/splunk-otel-go/instrumentation/internal => ../../../internal
+)
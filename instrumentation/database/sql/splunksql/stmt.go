@@ -18,9 +18,6 @@ import (
 	"context"
 	"database/sql/driver"
 
-	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
-	"go.opentelemetry.io/otel/trace"
-
 	"github.com/signalfx/splunk-otel-go/instrumentation/database/sql/splunksql/internal/moniker"
 )
 
@@ -69,7 +66,7 @@ func (s *otelStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (d
 		}
 	}
 
-	err := s.config.withSpan(ctx, moniker.Exec, f, trace.WithAttributes(semconv.DBStatementKey.String(s.query)))
+	err := s.config.withSpan(ctx, moniker.Exec, s.query, f)
 	return res, err
 }
 
@@ -99,7 +96,7 @@ func (s *otelStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (
 		}
 	}
 
-	err := s.config.withSpan(ctx, moniker.Query, f, trace.WithAttributes(semconv.DBStatementKey.String(s.query)))
+	err := s.config.withSpan(ctx, moniker.Query, s.query, f)
 	if err != nil {
 		return nil, err
 	}
@@ -17,6 +17,7 @@ require (
 	github.com/go-logr/logr v1.4.4 // indirect
 	github.com/go-logr/stdr v1.2.2 // indirect
 	github.com/google/uuid v1.6.0 // indirect
+	github.com/signalfx/splunk-otel-go v1.34.0 // indirect
 	github.com/signalfx/splunk-otel-go/instrumentation/internal v1.34.0 // indirect
 	go.opentelemetry.io/auto/sdk v1.2.1 // indirect
 	go.opentelemetry.io/otel/metric v1.45.0 // indirect
@@ -25,6 +26,7 @@ require (
 )
 
 replace (
+	github.com/signalfx/splunk-otel-go => ../../../../..
 	github.com/signalfx/splunk-otel-go/instrumentation/database/sql/splunksql => ../
 	github.com/signalfx/splunk-otel-go/instrumentation/internal => ../../../../internal
 )
@@ -0,0 +1,246 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package test
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	semconvstable "go.opentelemetry.io/otel/semconv/v1.37.0"
+
+	"github.com/signalfx/splunk-otel-go/instrumentation/database/sql/splunksql"
+)
+
+func TestSpanNameFormatter(t *testing.T) {
+	const driverName = "splunktest-span-name"
+	sql.Register(driverName, newFullMockDriver())
+	splunksql.Register(driverName, splunksql.InstrumentationConfig{
+		DSNParser: func(string) (splunksql.ConnectionConfig, error) {
+			return splunksql.ConnectionConfig{Name: "testDB", Host: mockDBHost}, nil
+		},
+	})
+
+	sr := tracetest.NewSpanRecorder()
+	tp := trace.NewTracerProvider(trace.WithSpanProcessor(sr))
+	db, err := splunksql.Open(
+		driverName,
+		"mockDB",
+		splunksql.WithTracerProvider(tp),
+		splunksql.WithSpanNameFormatter(func(op splunksql.Operation) string {
+			if op.Statement == "" {
+				return ""
+			}
+			return op.Call + " " + op.DBName + " " + strings.Fields(op.Statement)[0]
+		}),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { assert.NoError(t, db.Close()) })
+
+	_, err = db.Exec("INSERT INTO users VALUES (1)")
+	require.NoError(t, err)
+	require.NoError(t, db.Ping())
+
+	spans := sr.Ended()
+	require.GreaterOrEqual(t, len(spans), 2)
+	assert.Equal(t, "Exec testDB INSERT", spans[0].Name())
+	// Calls without a statement use the default name.
+	for _, s := range spans[1:] {
+		assert.Equal(t, "testDB", s.Name())
+	}
+}
+
+func TestFilter(t *testing.T) {
+	const driverName = "splunktest-filter"
+	sql.Register(driverName, newFullMockDriver())
+	splunksql.Register(driverName, splunksql.InstrumentationConfig{
+		DSNParser: func(string) (splunksql.ConnectionConfig, error) {
+			return splunksql.ConnectionConfig{Host: mockDBHost}, nil
+		},
+	})
+
+	sr := tracetest.NewSpanRecorder()
+	tp := trace.NewTracerProvider(trace.WithSpanProcessor(sr))
+	db, err := splunksql.Open(
+		driverName,
+		"mockDB",
+		splunksql.WithTracerProvider(tp),
+		splunksql.WithFilter(func(op splunksql.Operation) bool {
+			return op.Call != "Ping" && op.Call != "Reset"
+		}),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { assert.NoError(t, db.Close()) })
+
+	require.NoError(t, db.Ping())
+	_, err = db.Exec("INSERT INTO users VALUES (1)")
+	require.NoError(t, err)
+
+	spans := sr.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "Exec", spans[0].Name())
+}
+
+func TestRequireParentSpan(t *testing.T) {
+	const driverName = "splunktest-require-parent"
+	sql.Register(driverName, newFullMockDriver())
+	splunksql.Register(driverName, splunksql.InstrumentationConfig{
+		DSNParser: func(string) (splunksql.ConnectionConfig, error) {
+			return splunksql.ConnectionConfig{Host: mockDBHost}, nil
+		},
+	})
+
+	sr := tracetest.NewSpanRecorder()
+	tp := trace.NewTracerProvider(trace.WithSpanProcessor(sr))
+	db, err := splunksql.Open(
+		driverName,
+		"mockDB",
+		splunksql.WithTracerProvider(tp),
+		splunksql.WithRequireParentSpan(true),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { assert.NoError(t, db.Close()) })
+
+	_, err = db.Exec("INSERT INTO users VALUES (1)")
+	require.NoError(t, err)
+	assert.Empty(t, sr.Ended(), "orphan operations traced")
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "parent")
+	_, err = db.ExecContext(ctx, "INSERT INTO users VALUES (2)")
+	require.NoError(t, err)
+	parent.End()
+
+	var names []string
+	for _, s := range sr.Ended() {
+		assert.Equal(t, parent.SpanContext().TraceID(), s.SpanContext().TraceID())
+		names = append(names, s.Name())
+	}
+	assert.Contains(t, names, "Exec")
+}
+
+func TestDisabled(t *testing.T) {
+	t.Setenv("SPLUNK_INSTRUMENTATION_SQL_ENABLED", "false")
+
+	const driverName = "splunktest-disabled"
+	sql.Register(driverName, newFullMockDriver())
+	splunksql.Register(driverName, splunksql.InstrumentationConfig{
+		DSNParser: func(string) (splunksql.ConnectionConfig, error) {
+			return splunksql.ConnectionConfig{Host: mockDBHost}, nil
+		},
+	})
+
+	sr := tracetest.NewSpanRecorder()
+	r := sdkmetric.NewManualReader()
+	db, err := splunksql.Open(
+		driverName,
+		"mockDB",
+		splunksql.WithTracerProvider(trace.NewTracerProvider(trace.WithSpanProcessor(sr))),
+		splunksql.WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(r))),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { assert.NoError(t, db.Close()) })
+
+	_, err = db.Exec("INSERT INTO users VALUES (1)")
+	require.NoError(t, err)
+
+	assert.Empty(t, sr.Ended())
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, r.Collect(context.Background(), &rm))
+	assert.Empty(t, rm.ScopeMetrics)
+}
+
+func TestPeerServiceMapping(t *testing.T) {
+	t.Setenv("OTEL_INSTRUMENTATION_COMMON_PEER_SERVICE_MAPPING", mockDBHost+"=env-service")
+
+	const driverName = "splunktest-peer-service"
+	sql.Register(driverName, newFullMockDriver())
+	splunksql.Register(driverName, splunksql.InstrumentationConfig{
+		DSNParser: func(string) (splunksql.ConnectionConfig, error) {
+			return splunksql.ConnectionConfig{Host: mockDBHost}, nil
+		},
+	})
+
+	tests := map[string]struct {
+		opts []splunksql.Option
+		want string
+	}{
+		"env": {want: "env-service"},
+		"option": {
+			opts: []splunksql.Option{splunksql.WithPeerServiceMapping(map[string]string{
+				mockDBHost: "option-service",
+			})},
+			want: "option-service",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			sr := tracetest.NewSpanRecorder()
+			tp := trace.NewTracerProvider(trace.WithSpanProcessor(sr))
+			opts := append([]splunksql.Option{splunksql.WithTracerProvider(tp)}, test.opts...)
+			db, err := splunksql.Open(driverName, "mockDB", opts...)
+			require.NoError(t, err)
+			t.Cleanup(func() { assert.NoError(t, db.Close()) })
+
+			_, err = db.Exec("INSERT INTO users VALUES (1)")
+			require.NoError(t, err)
+
+			spans := sr.Ended()
+			require.NotEmpty(t, spans)
+			for _, s := range spans {
+				assert.Contains(t, s.Attributes(), semconv.PeerServiceKey.String(test.want), s.Name())
+			}
+		})
+	}
+}
+
+func TestSemconvStability(t *testing.T) {
+	t.Setenv("OTEL_SEMCONV_STABILITY_OPT_IN", "database")
+
+	const driverName = "splunktest-semconv"
+	sql.Register(driverName, newFullMockDriver())
+	splunksql.Register(driverName, splunksql.InstrumentationConfig{
+		DSNParser: func(string) (splunksql.ConnectionConfig, error) {
+			return splunksql.ConnectionConfig{Name: "testDB", Host: mockDBHost}, nil
+		},
+	})
+
+	sr := tracetest.NewSpanRecorder()
+	tp := trace.NewTracerProvider(trace.WithSpanProcessor(sr))
+	db, err := splunksql.Open(driverName, "mockDB", splunksql.WithTracerProvider(tp))
+	require.NoError(t, err)
+	t.Cleanup(func() { assert.NoError(t, db.Close()) })
+
+	const query = "INSERT INTO users VALUES (1)"
+	_, err = db.Exec(query)
+	require.NoError(t, err)
+
+	spans := sr.Ended()
+	require.NotEmpty(t, spans)
+	attrs := spans[0].Attributes()
+	assert.Contains(t, attrs, semconvstable.DBQueryText(query))
+	assert.Contains(t, attrs, semconvstable.DBNamespace("testDB"))
+	assert.Contains(t, attrs, semconvstable.ServerAddress(mockDBHost))
+	assert.NotContains(t, attrs, semconv.DBStatementKey.String(query))
+	assert.NotContains(t, attrs, semconv.DBNameKey.String("testDB"))
+}
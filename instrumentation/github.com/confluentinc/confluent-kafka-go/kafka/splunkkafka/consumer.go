@@ -28,6 +28,7 @@ import (
 	"github.com/confluentinc/confluent-kafka-go/kafka"
 	"go.opentelemetry.io/otel/codes"
 	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	semconvnew "go.opentelemetry.io/otel/semconv/v1.37.0"
 	"go.opentelemetry.io/otel/trace"
 
 	"github.com/signalfx/splunk-otel-go/instrumentation/internal"
@@ -37,6 +38,7 @@ import (
 type Consumer struct {
 	*kafka.Consumer
 	cfg       *internal.Config
+	ctx       context.Context
 	waitGroup sync.WaitGroup
 	events    chan kafka.Event
 	// Use an unsafe.Pointer instead of an atomic.Value to support Go versions
@@ -65,28 +67,41 @@ func NewConsumer(conf *kafka.ConfigMap, opts ...Option) (*Consumer, error) {
 			)
 		}
 	}
-	return wrapConsumer(c, cfg), nil
+	return wrapConsumer(optionContext(opts), c, cfg), nil
 }
 
 // WrapConsumer wraps a kafka.Consumer so that any consumed events are traced.
 func WrapConsumer(c *kafka.Consumer, opts ...Option) *Consumer {
-	return wrapConsumer(c, newConfig(opts...))
+	return wrapConsumer(optionContext(opts), c, newConfig(opts...))
 }
 
 // consumerSpan is a wrapper around an OpenTelemetry Span that can be used as
 // an atomic value.
 type consumerSpan struct {
 	otelSpan trace.Span
+
+	ctx   context.Context
+	cfg   *internal.Config
+	op    internal.Operation
+	start time.Time
 }
 
-// End completes the wrapped OpenTelemetry span if one exists.
+// End completes the wrapped OpenTelemetry span if one exists. The time
+// between the message being received and the end of the span is recorded as
+// the processing duration of the message.
 func (s consumerSpan) End(options ...trace.SpanEndOption) {
 	if s.otelSpan != nil {
+		cfg := trace.NewSpanEndConfig(options...)
+		end := cfg.Timestamp()
+		if end.IsZero() {
+			end = time.Now()
+		}
+		s.cfg.RecordDuration(s.ctx, s.op, end.Sub(s.start))
 		s.otelSpan.End(options...)
 	}
 }
 
-func wrapConsumer(c *kafka.Consumer, cfg *internal.Config) *Consumer {
+func wrapConsumer(ctx context.Context, c *kafka.Consumer, cfg *internal.Config) *Consumer {
 	// Common attributes for all spans this consumer will produce.
 	cfg.DefaultStartOpts = append(
 		cfg.DefaultStartOpts,
@@ -99,6 +114,7 @@ func wrapConsumer(c *kafka.Consumer, cfg *internal.Config) *Consumer {
 	wrapped := &Consumer{
 		Consumer: c,
 		cfg:      cfg,
+		ctx:      ctx,
 		// Set an empty spanHolder to set the activeSpan to empty and ensure that
 		// the unsafe.Pointer is set to the correct type.
 		activeSpan: unsafe.Pointer(&consumerSpan{}),
@@ -140,6 +156,20 @@ func (c *Consumer) traceEventsChannel(in chan kafka.Event) chan kafka.Event {
 }
 
 func (c *Consumer) startSpan(msg *kafka.Message) consumerSpan {
+	op := internal.Operation{
+		Name: fmt.Sprintf("%s receive", *msg.TopicPartition.Topic),
+		Details: Operation{
+			Kind:    trace.SpanKindConsumer,
+			Topic:   *msg.TopicPartition.Topic,
+			Message: msg,
+		},
+		Histogram:  internal.MessagingProcessDuration,
+		Attributes: metricAttributes(msg, semconvnew.MessagingOperationTypeProcess),
+	}
+	if !c.cfg.ShouldTrace(c.ctx, op) {
+		return consumerSpan{}
+	}
+
 	carrier := NewMessageCarrier(msg)
 	psc := c.cfg.Propagator.Extract(context.Background(), carrier)
 
@@ -155,18 +185,24 @@ func (c *Consumer) startSpan(msg *kafka.Message) consumerSpan {
 		trace.WithSpanKind(trace.SpanKindConsumer),
 	)
 
-	name := fmt.Sprintf("%s receive", *msg.TopicPartition.Topic)
-	ctx, otelSpan := c.cfg.Tracer.Start(psc, name, opts...)
+	ctx, otelSpan := c.cfg.Tracer.Start(psc, c.cfg.SpanName(op), opts...)
 	if err := msg.TopicPartition.Error; err != nil {
 		otelSpan.RecordError(err)
 		otelSpan.SetStatus(codes.Error, err.Error())
+		op.Attributes = append(op.Attributes, internal.ErrorType(err))
 	}
 
 	// Inject the current span into the original message so it can be used to
 	// propagate the span.
 	c.cfg.Propagator.Inject(ctx, carrier)
 
-	return consumerSpan{otelSpan: otelSpan}
+	return consumerSpan{
+		otelSpan: otelSpan,
+		ctx:      ctx,
+		cfg:      c.cfg,
+		op:       op,
+		start:    time.Now(),
+	}
 }
 
 // Close calls the underlying Consumer.Close and if polling is enabled, ends
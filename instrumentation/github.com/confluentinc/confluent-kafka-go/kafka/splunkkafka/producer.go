@@ -21,10 +21,13 @@ import (
 	"fmt"
 	"strconv"
 	"sync"
+	"time"
 
 	"github.com/confluentinc/confluent-kafka-go/kafka"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	semconvnew "go.opentelemetry.io/otel/semconv/v1.37.0"
 	"go.opentelemetry.io/otel/trace"
 
 	"github.com/signalfx/splunk-otel-go/instrumentation/internal"
@@ -34,6 +37,7 @@ import (
 type Producer struct {
 	*kafka.Producer
 	cfg            *internal.Config
+	ctx            context.Context
 	stop           chan struct{}
 	waitGroup      sync.WaitGroup
 	produceChannel chan *kafka.Message
@@ -63,6 +67,7 @@ func WrapProducer(p *kafka.Producer, opts ...Option) *Producer {
 	wrapped := &Producer{
 		Producer: p,
 		cfg:      cfg,
+		ctx:      optionContext(opts),
 		stop:     make(chan struct{}),
 	}
 	wrapped.produceChannel = wrapped.traceProduceChannel(p.ProduceChannel())
@@ -81,14 +86,52 @@ func (p *Producer) traceProduceChannel(out chan *kafka.Message) chan *kafka.Mess
 		for msg := range in {
 			span := p.startSpan(msg)
 			out <- msg
-			span.End()
+			span.end(nil)
 		}
 	}()
 
 	return in
 }
 
-func (p *Producer) startSpan(msg *kafka.Message) trace.Span {
+// producerSpan is the span of a message being produced.
+type producerSpan struct {
+	trace.Span
+
+	ctx   context.Context
+	cfg   *internal.Config
+	op    internal.Operation
+	start time.Time
+}
+
+// end ends the span and records the duration of the publish operation. If err
+// is not nil, it is recorded as the error of the operation.
+func (s producerSpan) end(err error) {
+	var attrs []attribute.KeyValue
+	if err != nil {
+		s.RecordError(err)
+		s.SetStatus(codes.Error, err.Error())
+		attrs = append(attrs, internal.ErrorType(err))
+	}
+	s.cfg.RecordDuration(s.ctx, s.op, time.Since(s.start), attrs...)
+	s.End()
+}
+
+func (p *Producer) startSpan(msg *kafka.Message) producerSpan {
+	op := internal.Operation{
+		Name: fmt.Sprintf("%s send", *msg.TopicPartition.Topic),
+		Details: Operation{
+			Kind:    trace.SpanKindProducer,
+			Topic:   *msg.TopicPartition.Topic,
+			Message: msg,
+		},
+		Histogram:  internal.MessagingClientOperationDuration,
+		Attributes: metricAttributes(msg, semconvnew.MessagingOperationTypeSend),
+	}
+	if !p.cfg.ShouldTrace(p.ctx, op) {
+		// Return a non-recording span.
+		return producerSpan{Span: trace.SpanFromContext(context.Background())}
+	}
+
 	carrier := NewMessageCarrier(msg)
 	psc := p.cfg.Propagator.Extract(context.Background(), carrier)
 
@@ -104,13 +147,18 @@ func (p *Producer) startSpan(msg *kafka.Message) trace.Span {
 		trace.WithSpanKind(trace.SpanKindProducer),
 	)
 
-	name := fmt.Sprintf("%s send", *msg.TopicPartition.Topic)
-	ctx, span := p.cfg.Tracer.Start(psc, name, opts...)
+	ctx, span := p.cfg.Tracer.Start(psc, p.cfg.SpanName(op), opts...)
 
 	// Inject the current span into the original message so it can be used to
 	// propagate the span.
 	p.cfg.Propagator.Inject(ctx, carrier)
-	return span
+	return producerSpan{
+		Span:  span,
+		ctx:   ctx,
+		cfg:   p.cfg,
+		op:    op,
+		start: time.Now(),
+	}
 }
 
 // Close calls the wrapped Producer.Close and closes the producer channel.
@@ -139,27 +187,21 @@ func (p *Producer) Produce(msg *kafka.Message, deliveryChan chan kafka.Event) er
 			case <-p.stop:
 				return
 			case evt := <-deliveryChan:
+				var err error
 				if respMsg, ok := evt.(*kafka.Message); ok {
 					// Delivery errors are returned via TopicPartition.Error.
-					if err := respMsg.TopicPartition.Error; err != nil {
-						span.RecordError(err)
-						span.SetStatus(codes.Error, err.Error())
-					}
+					err = respMsg.TopicPartition.Error
 				}
 				orig <- evt
+				span.end(err)
 			}
-			span.End()
 		}()
 	}
 
 	err := p.Producer.Produce(msg, deliveryChan)
 	// No delivery channel, finish immediately.
 	if deliveryChan == nil {
-		if err != nil {
-			span.RecordError(err)
-			span.SetStatus(codes.Error, err.Error())
-		}
-		span.End()
+		span.end(err)
 	}
 
 	return err
@@ -26,8 +26,13 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
 	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	semconvnew "go.opentelemetry.io/otel/semconv/v1.37.0"
 	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	splunkotel "github.com/signalfx/splunk-otel-go"
 )
 
 func TestNewProducerType(t *testing.T) {
@@ -173,3 +178,71 @@ func TestProduceSpan(t *testing.T) {
 		assert.Contains(t, attrs, semconv.MessagingKafkaDestinationPartitionKey.Int64(1))
 	}
 }
+
+func TestProduceDuration(t *testing.T) {
+	r := sdkmetric.NewManualReader()
+	p, err := NewProducer(
+		&kafka.ConfigMap{},
+		WithTracerProvider(noop.NewTracerProvider()),
+		WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(r))),
+	)
+	require.NoError(t, err)
+
+	for _, k := range testMessageKeys {
+		msg := &kafka.Message{
+			TopicPartition: kafka.TopicPartition{Topic: &testTopic, Partition: 1},
+			Key:            []byte(k),
+			Value:          []byte("value"),
+		}
+		assert.NoError(t, p.Produce(msg, nil))
+	}
+
+	h := collectHistogram(t, r, "messaging.client.operation.duration")
+	require.Len(t, h.DataPoints, 1)
+	assert.Equal(t, uint64(len(testMessageKeys)), h.DataPoints[0].Count)
+	assert.Equal(t, attribute.NewSet(
+		semconvnew.MessagingSystemKafka,
+		semconvnew.MessagingDestinationName(testTopic),
+		semconvnew.MessagingOperationName("send"),
+		semconvnew.MessagingOperationTypeSend,
+	), h.DataPoints[0].Attributes)
+}
+
+func TestProduceWithContext(t *testing.T) {
+	sr := make(spanRecorder)
+	tp := &fnTracerProvider{
+		tracer: func(string, ...trace.TracerOption) trace.Tracer {
+			return &fnTracer{start: sr.start}
+		},
+	}
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{0x01},
+		SpanID:  trace.SpanID{0x01},
+	})
+	ctx := trace.ContextWithSpanContext(context.Background(), sc)
+
+	newMsg := func() *kafka.Message {
+		return &kafka.Message{
+			TopicPartition: kafka.TopicPartition{Topic: &testTopic, Partition: 1},
+			Value:          []byte("value"),
+		}
+	}
+
+	p, err := NewProducer(&kafka.ConfigMap{}, WithTracerProvider(tp), WithContext(ctx))
+	require.NoError(t, err)
+	assert.NoError(t, p.Produce(newMsg(), nil))
+	spans := sr.get(fmt.Sprintf("%s send", testTopic))
+	require.Len(t, spans, 1)
+	assert.False(t, spans[0].SpanContext.IsValid(), "message span started from the option context")
+
+	p, err = NewProducer(
+		&kafka.ConfigMap{},
+		WithTracerProvider(tp),
+		WithContext(splunkotel.SuppressTracing(ctx)),
+	)
+	require.NoError(t, err)
+	msg := newMsg()
+	assert.NoError(t, p.Produce(msg, nil))
+	assert.Len(t, sr.get(fmt.Sprintf("%s send", testTopic)), 1, "suppressed message traced")
+	assert.Empty(t, msg.Headers, "suppressed message span context injected")
+}
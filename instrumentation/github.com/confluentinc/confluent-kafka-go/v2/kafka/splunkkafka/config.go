@@ -17,9 +17,14 @@
 package splunkkafka
 
 import (
+	"context"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/propagation"
 	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	semconvnew "go.opentelemetry.io/otel/semconv/v1.37.0"
 	"go.opentelemetry.io/otel/trace"
 
 	"github.com/signalfx/splunk-otel-go/instrumentation/internal"
@@ -31,6 +36,7 @@ const instrumentationName = "github.com/signalfx/splunk-otel-go/instrumentation/
 func newConfig(options ...Option) *internal.Config {
 	o := append([]internal.Option{internal.OptionFunc(func(c *internal.Config) {
 		c.Version = Version()
+		c.MetricSemconv = internal.SemconvStable
 		c.DefaultStartOpts = []trace.SpanStartOption{
 			trace.WithAttributes(semconv.MessagingSystemKey.String("kafka")),
 		}
@@ -39,6 +45,18 @@ func newConfig(options ...Option) *internal.Config {
 	return internal.NewConfig(instrumentationName, o...)
 }
 
+// metricAttributes returns the low cardinality attributes the durations of
+// the operations of type opType on msg are recorded with. The operation is
+// named after its type.
+func metricAttributes(msg *kafka.Message, opType attribute.KeyValue) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		semconvnew.MessagingSystemKafka,
+		semconvnew.MessagingDestinationName(*msg.TopicPartition.Topic),
+		semconvnew.MessagingOperationName(opType.Value.AsString()),
+		opType,
+	}
+}
+
 func localToInternal(opts []Option) []internal.Option {
 	out := make([]internal.Option, len(opts))
 	for i, o := range opts {
@@ -58,14 +76,76 @@ func WithTracerProvider(tp trace.TracerProvider) Option {
 	return Option(internal.WithTracerProvider(tp))
 }
 
+// WithMeterProvider returns an Option that sets the MeterProvider used for
+// a configuration.
+func WithMeterProvider(mp metric.MeterProvider) Option {
+	return Option(internal.WithMeterProvider(mp))
+}
+
 // WithAttributes returns an Option that appends attr to the attributes set
 // for every span created.
 func WithAttributes(attr []attribute.KeyValue) Option {
 	return Option(internal.WithAttributes(attr))
 }
 
+// contextOption is the Option returned by WithContext. It does not modify the
+// internal.Config, the Producer and Consumer look it up instead.
+type contextOption struct {
+	ctx context.Context
+}
+
+// Apply does nothing. The context is not part of the internal.Config.
+func (contextOption) Apply(*internal.Config) {}
+
+// WithContext returns an Option that sets the Context the Producer or
+// Consumer is used in. If tracing is suppressed in ctx with
+// splunkotel.SuppressTracing, none of the messages the Producer or Consumer
+// produces or consumes are traced. The spans of the messages are not started
+// from ctx, a message not carrying a span context starts a new trace.
+func WithContext(ctx context.Context) Option {
+	return contextOption{ctx: ctx}
+}
+
+// optionContext returns the Context set with WithContext in opts, or
+// context.Background if none is set.
+func optionContext(opts []Option) context.Context {
+	ctx := context.Background()
+	for _, o := range opts {
+		if co, ok := o.(contextOption); ok && co.ctx != nil {
+			ctx = co.ctx
+		}
+	}
+	return ctx
+}
+
 // WithPropagator returns an Option that sets p as the TextMapPropagator used
 // when propagating a span context.
 func WithPropagator(p propagation.TextMapPropagator) Option {
 	return Option(internal.WithPropagator(p))
 }
+
+// Operation describes a Kafka message produced or consumed and traced by this
+// instrumentation library.
+type Operation struct {
+	// Kind is trace.SpanKindProducer for a produced message and
+	// trace.SpanKindConsumer for a consumed message.
+	Kind trace.SpanKind
+	// Topic is the topic of the message.
+	Topic string
+	// Message is the message produced or consumed.
+	Message *kafka.Message
+}
+
+// WithSpanNameFormatter returns an Option that sets f to name the spans
+// created for produced and consumed messages. If f returns an empty string
+// the default span name, the topic followed by "send" or "receive", is used.
+func WithSpanNameFormatter(f func(Operation) string) Option {
+	return Option(internal.WithSpanNameFormatter(f))
+}
+
+// WithFilter returns an Option that adds f to the filters of produced and
+// consumed messages. Messages f returns false for, e.g. messages of internal
+// topics, are not traced.
+func WithFilter(f func(Operation) bool) Option {
+	return Option(internal.WithFilter(f))
+}
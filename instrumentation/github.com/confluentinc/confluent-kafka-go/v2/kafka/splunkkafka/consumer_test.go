@@ -27,7 +27,10 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
 	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	semconvnew "go.opentelemetry.io/otel/semconv/v1.37.0"
 	"go.opentelemetry.io/otel/trace"
 	"go.opentelemetry.io/otel/trace/noop"
 )
@@ -61,6 +64,21 @@ func (fn *fnTracer) Start(ctx context.Context, name string, opts ...trace.SpanSt
 	return fn.start(ctx, name, opts...)
 }
 
+func collectHistogram(t *testing.T, r sdkmetric.Reader, name string) metricdata.Histogram[float64] {
+	t.Helper()
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, r.Collect(context.Background(), &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+	require.Len(t, rm.ScopeMetrics[0].Metrics, 1)
+	m := rm.ScopeMetrics[0].Metrics[0]
+	assert.Equal(t, name, m.Name)
+	assert.Equal(t, "s", m.Unit)
+	h, ok := m.Data.(metricdata.Histogram[float64])
+	require.True(t, ok)
+	return h
+}
+
 func TestNewConsumerCapturesGroupID(t *testing.T) {
 	c, err := NewConsumer(&kafka.ConfigMap{groupIDConfigKey: grpID})
 	require.NoError(t, err)
@@ -183,6 +201,39 @@ func TestConsumerSpan(t *testing.T) {
 	}
 }
 
+func TestConsumerDuration(t *testing.T) {
+	r := sdkmetric.NewManualReader()
+	c, err := NewConsumer(&kafka.ConfigMap{
+		// required for the events channel to be turned on
+		eventsChannelEnableConfigKey: true,
+		groupIDConfigKey:             grpID,
+	}, WithTracerProvider(noop.NewTracerProvider()), WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(r))))
+	require.NoError(t, err)
+
+	for _, k := range testMessageKeys {
+		msg := &kafka.Message{
+			TopicPartition: kafka.TopicPartition{Topic: &testTopic, Partition: 1},
+			Key:            []byte(k),
+			Value:          []byte("value"),
+		}
+		c.Consumer.Events() <- msg
+		<-c.Events()
+	}
+	// The processing of a message ends when the next one is received.
+	c.Consumer.Events() <- kafka.PartitionEOF{}
+	<-c.Events()
+
+	h := collectHistogram(t, r, "messaging.process.duration")
+	require.Len(t, h.DataPoints, 1)
+	assert.Equal(t, uint64(len(testMessageKeys)), h.DataPoints[0].Count)
+	assert.Equal(t, attribute.NewSet(
+		semconvnew.MessagingSystemKafka,
+		semconvnew.MessagingDestinationName(testTopic),
+		semconvnew.MessagingOperationName("process"),
+		semconvnew.MessagingOperationTypeProcess,
+	), h.DataPoints[0].Attributes)
+}
+
 func TestConsumerConcurrentConsuming(t *testing.T) {
 	sr := make(spanRecorder)
 	tp := &fnTracerProvider{
@@ -31,6 +31,7 @@ require (
 	github.com/moby/moby/client v0.5.1 // indirect
 	github.com/opencontainers/go-digest v1.0.0 // indirect
 	github.com/opencontainers/image-spec v1.1.1 // indirect
+	github.com/signalfx/splunk-otel-go v1.34.0 // indirect
 	github.com/signalfx/splunk-otel-go/instrumentation/internal v1.34.0 // indirect
 	go.opentelemetry.io/auto/sdk v1.2.1 // indirect
 	go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp v0.70.0 // indirect
@@ -40,6 +41,7 @@ require (
 )
 
 replace (
+	github.com/signalfx/splunk-otel-go => ../../../../../../../..
 	github.com/signalfx/splunk-otel-go/instrumentation/github.com/confluentinc/confluent-kafka-go/v2/kafka/splunkkafka => ../
 	github.com/signalfx/splunk-otel-go/instrumentation/internal => ../../../../../../../internal
 )
@@ -21,12 +21,16 @@ package splunkchi
 
 import (
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/go-chi/chi"
 	"github.com/go-chi/chi/middleware"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/propagation"
 	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
 	"go.opentelemetry.io/otel/semconv/v1.17.0/httpconv"
+	semconvnew "go.opentelemetry.io/otel/semconv/v1.37.0"
 	"go.opentelemetry.io/otel/trace"
 
 	"github.com/signalfx/splunk-otel-go/instrumentation/internal"
@@ -41,6 +45,8 @@ func Middleware(options ...Option) func(http.Handler) http.Handler {
 	o := append([]internal.Option{
 		internal.OptionFunc(func(c *internal.Config) {
 			c.Version = Version()
+			c.SpanSemconv = c.HTTPSemconv
+			c.MetricSemconv = internal.SemconvStable
 			c.DefaultStartOpts = append(c.DefaultStartOpts, trace.WithSpanKind(trace.SpanKindServer))
 		}),
 	}, localToInternal(options)...)
@@ -49,36 +55,95 @@ func Middleware(options ...Option) func(http.Handler) http.Handler {
 
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// The full handler chain needs to be complete before we are sure
+			// what path is being requested. Delay full naming and annotation
+			// until then.
+			name := "HTTP " + r.Method
+			op := internal.Operation{Name: name, Details: Operation{Request: r, Route: matchRoute(r)}}
+			if !cfg.ShouldTrace(r.Context(), op) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
 			// Allows us to track the ultimate status.
 			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
 
 			tracer := cfg.ResolveTracer(r.Context())
 			carrier := propagation.HeaderCarrier(r.Header)
 			ctx := cfg.Propagator.Extract(r.Context(), carrier)
-			// The full handler chain needs to be complete before we are sure
-			// what path is being requested. Delay full naming and annotation
-			// until then.
-			name := "HTTP " + r.Method
-			attr := httpconv.ServerRequest("", r)
+			attr := cfg.HTTPAttributes(httpconv.ServerRequest("", r)...)
 			opt := cfg.DefaultStartOpts
 			opt = append(opt, trace.WithAttributes(attr...))
-			ctx, span := tracer.Start(ctx, name, opt...)
-			defer span.End()
+			ctx, span := tracer.Start(ctx, cfg.SpanName(op), opt...)
 			r = r.WithContext(ctx)
 
+			scheme := "http"
+			if r.TLS != nil {
+				scheme = "https"
+			}
+			op.Histogram = internal.HTTPServerRequestDuration
+			op.Attributes = []attribute.KeyValue{
+				internal.HTTPRequestMethod(r.Method),
+				semconvnew.URLScheme(scheme),
+			}
+
+			start := time.Now()
+			defer func() {
+				if rec := recover(); rec != nil {
+					internal.RecordPanic(span, rec)
+					if path := chi.RouteContext(r.Context()).RoutePattern(); path != "" {
+						op.Attributes = append(op.Attributes, semconvnew.HTTPRoute(path))
+					}
+					cfg.RecordDuration(ctx, op, time.Since(start), internal.PanicErrorType(rec))
+					span.End()
+					panic(rec)
+				}
+				span.End()
+			}()
+
 			next.ServeHTTP(ww, r)
+			elapsed := time.Since(start)
 
 			path := chi.RouteContext(r.Context()).RoutePattern()
 			if path != "" {
 				span.SetAttributes(semconv.HTTPRouteKey.String(path))
-				span.SetName(name + " " + path)
+				span.SetName(cfg.SpanName(internal.Operation{
+					Name:    name + " " + path,
+					Details: Operation{Request: r, Route: path},
+				}))
+				op.Attributes = append(op.Attributes, semconvnew.HTTPRoute(path))
 			}
 
 			status := ww.Status()
 			if status > 0 {
-				span.SetAttributes(semconv.HTTPStatusCodeKey.Int(status))
+				span.SetAttributes(cfg.HTTPAttributes(semconv.HTTPStatusCodeKey.Int(status))...)
+				op.Attributes = append(op.Attributes, semconvnew.HTTPResponseStatusCode(status))
+			}
+			if status >= http.StatusInternalServerError {
+				op.Attributes = append(op.Attributes, semconvnew.ErrorTypeKey.String(strconv.Itoa(status)))
 			}
 			span.SetStatus(httpconv.ServerStatus(status))
+			cfg.RecordDuration(ctx, op, elapsed)
 		})
 	}
 }
+
+// matchRoute returns the route pattern the router serving r routes it to, or
+// an empty string if it matches no route. The routing context of r is not
+// modified.
+func matchRoute(r *http.Request) string {
+	rctx := chi.RouteContext(r.Context())
+	if rctx == nil || rctx.Routes == nil {
+		return ""
+	}
+	path := r.URL.RawPath
+	if path == "" {
+		path = r.URL.Path
+	}
+	// Routes is the top router, it matches the full path.
+	mctx := chi.NewRouteContext()
+	if !rctx.Routes.Match(mctx, r.Method, path) {
+		return ""
+	}
+	return mctx.RoutePattern()
+}
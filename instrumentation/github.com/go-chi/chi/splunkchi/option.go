@@ -15,7 +15,10 @@
 package splunkchi
 
 import (
+	"net/http"
+
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/trace"
 
@@ -49,6 +52,12 @@ func WithTracerProvider(tp trace.TracerProvider) Option {
 	return optionConv{iOpt: internal.WithTracerProvider(tp)}
 }
 
+// WithMeterProvider returns an Option that sets the MeterProvider used for
+// a configuration.
+func WithMeterProvider(mp metric.MeterProvider) Option {
+	return optionConv{iOpt: internal.WithMeterProvider(mp)}
+}
+
 // WithAttributes returns an Option that appends attr to the attributes set
 // for every span created.
 func WithAttributes(attr []attribute.KeyValue) Option {
@@ -60,3 +69,29 @@ func WithAttributes(attr []attribute.KeyValue) Option {
 func WithPropagator(p propagation.TextMapPropagator) Option {
 	return optionConv{iOpt: internal.WithPropagator(p)}
 }
+
+// Operation describes a request served by the traced router.
+type Operation struct {
+	// Request is the served request.
+	Request *http.Request
+	// Route is the route pattern matched by the router, resolved before the
+	// request is routed. It is empty if the request matches no route.
+	Route string
+}
+
+// WithSpanNameFormatter returns an Option that sets f to name the spans
+// created for served requests. The span name is formatted when the span is
+// started and again once the request has been routed. If f returns an empty
+// string the default span name, "HTTP " followed by the method and route of
+// the request, is used.
+func WithSpanNameFormatter(f func(Operation) string) Option {
+	return optionConv{iOpt: internal.WithSpanNameFormatter(f)}
+}
+
+// WithFilter returns an Option that adds f to the filters of the served
+// requests. Requests f returns false for, e.g. health checks, are not
+// traced. Filters are evaluated before the request is routed, the Route of
+// the Operation passed to f is the route the request matches.
+func WithFilter(f func(Operation) bool) Option {
+	return optionConv{iOpt: internal.WithFilter(f)}
+}
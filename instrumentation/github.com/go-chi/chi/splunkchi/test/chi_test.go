@@ -26,6 +26,7 @@ import (
 	"context"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
 	"testing"
 
 	"github.com/go-chi/chi"
@@ -33,11 +34,17 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
 	"go.opentelemetry.io/otel/sdk/trace"
 	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	semconvnew "go.opentelemetry.io/otel/semconv/v1.37.0"
 	traceapi "go.opentelemetry.io/otel/trace"
 
+	splunkotel "github.com/signalfx/splunk-otel-go"
+	"github.com/signalfx/splunk-otel-go/instrumentation/instrumentationtest"
 	//nolint:staticcheck // Deprecated package, but still used here.
 	"github.com/signalfx/splunk-otel-go/instrumentation/github.com/go-chi/chi/splunkchi"
 )
@@ -140,3 +147,271 @@ func assertSpan(path string, otelCode codes.Code, httpCode int) func(*testing.T,
 		}
 	}
 }
+
+func TestMiddlewareSpanNameFormatter(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := trace.NewTracerProvider(trace.WithSpanProcessor(sr))
+	t.Cleanup(func() { assert.NoError(t, tp.Shutdown(context.Background())) })
+
+	r := chi.NewRouter()
+	r.Use(splunkchi.Middleware(
+		splunkchi.WithTracerProvider(tp),
+		splunkchi.WithSpanNameFormatter(func(op splunkchi.Operation) string {
+			if op.Route == "" {
+				return ""
+			}
+			return op.Request.Method + " " + op.Route
+		}),
+	))
+	r.Get("/users/{user}", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/users/bob", http.NoBody)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	require.Len(t, sr.Ended(), 1)
+	assert.Equal(t, "GET /users/{user}", sr.Ended()[0].Name())
+}
+
+func TestMiddlewareFilter(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := trace.NewTracerProvider(trace.WithSpanProcessor(sr))
+	t.Cleanup(func() { assert.NoError(t, tp.Shutdown(context.Background())) })
+
+	r := chi.NewRouter()
+	r.Use(splunkchi.Middleware(
+		splunkchi.WithTracerProvider(tp),
+		splunkchi.WithFilter(func(op splunkchi.Operation) bool {
+			return op.Request.URL.Path != "/healthz"
+		}),
+	))
+	r.Get("/healthz", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	r.Get("/users", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/healthz", http.NoBody))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, sr.Ended())
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users", http.NoBody))
+	require.Len(t, sr.Ended(), 1)
+	assert.Equal(t, "HTTP GET /users", sr.Ended()[0].Name())
+}
+
+func TestMiddlewareFilterRoute(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := trace.NewTracerProvider(trace.WithSpanProcessor(sr))
+	t.Cleanup(func() { assert.NoError(t, tp.Shutdown(context.Background())) })
+
+	var routes []string
+	r := chi.NewRouter()
+	r.Use(splunkchi.Middleware(
+		splunkchi.WithTracerProvider(tp),
+		splunkchi.WithFilter(func(op splunkchi.Operation) bool {
+			routes = append(routes, op.Route)
+			return op.Route != "/users/{user}/internal"
+		}),
+	))
+	r.Route("/users/{user}", func(r chi.Router) {
+		r.Get("/", func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})
+		r.Get("/internal", func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/bob/internal", http.NoBody))
+	assert.Empty(t, sr.Ended())
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/bob/", http.NoBody))
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/unknown", http.NoBody))
+	assert.Len(t, sr.Ended(), 2)
+	assert.Equal(t, []string{"/users/{user}/internal", "/users/{user}/", ""}, routes)
+}
+
+func TestMiddlewareSemconvStability(t *testing.T) {
+	tests := []struct {
+		optIn      string
+		wantOld    bool
+		wantStable bool
+	}{
+		{optIn: "http", wantStable: true},
+		{optIn: "http/dup", wantOld: true, wantStable: true},
+		{optIn: "database", wantOld: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.optIn, func(t *testing.T) {
+			t.Setenv("OTEL_SEMCONV_STABILITY_OPT_IN", test.optIn)
+			sr, r := newFixtures(t)
+			req := httptest.NewRequest(http.MethodGet, "/users/bob", http.NoBody)
+			r.ServeHTTP(httptest.NewRecorder(), req)
+			require.Len(t, sr.Ended(), 1)
+
+			attrs := sr.Ended()[0].Attributes()
+			assert.Contains(t, attrs, semconv.HTTPRouteKey.String("/users/{user}/"))
+
+			old := []attribute.KeyValue{
+				semconv.HTTPMethodKey.String(http.MethodGet),
+				semconv.HTTPSchemeHTTP,
+				semconv.HTTPStatusCodeKey.Int(http.StatusOK),
+				semconv.HTTPFlavorHTTP11,
+			}
+			stable := []attribute.KeyValue{
+				semconvnew.HTTPRequestMethodGet,
+				semconvnew.URLScheme("http"),
+				semconvnew.HTTPResponseStatusCode(http.StatusOK),
+				semconvnew.NetworkProtocolVersion("1.1"),
+			}
+			for _, kv := range old {
+				if test.wantOld {
+					assert.Contains(t, attrs, kv)
+				} else {
+					assert.NotContains(t, attrs, kv)
+				}
+			}
+			for _, kv := range stable {
+				if test.wantStable {
+					assert.Contains(t, attrs, kv)
+				} else {
+					assert.NotContains(t, attrs, kv)
+				}
+			}
+		})
+	}
+}
+
+func TestMiddlewareDuration(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	r := chi.NewRouter()
+	r.Use(splunkchi.Middleware(
+		// Durations are recorded even if the spans are not sampled.
+		splunkchi.WithTracerProvider(trace.NewTracerProvider(trace.WithSampler(trace.NeverSample()))),
+		splunkchi.WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))),
+	))
+	r.Get("/users/{user}", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	r.Get("/error", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	for _, target := range []string{"/users/bob", "/users/alice", "/error"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, http.NoBody))
+	}
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+	require.Len(t, rm.ScopeMetrics[0].Metrics, 1)
+	m := rm.ScopeMetrics[0].Metrics[0]
+	assert.Equal(t, "http.server.request.duration", m.Name)
+	h, ok := m.Data.(metricdata.Histogram[float64])
+	require.True(t, ok)
+
+	got := make(map[attribute.Set]uint64)
+	for _, dp := range h.DataPoints {
+		got[dp.Attributes] = dp.Count
+	}
+	assert.Equal(t, map[attribute.Set]uint64{
+		attribute.NewSet(
+			semconvnew.HTTPRequestMethodGet,
+			semconvnew.URLScheme("http"),
+			semconvnew.HTTPRoute("/users/{user}"),
+			semconvnew.HTTPResponseStatusCode(http.StatusOK),
+		): 2,
+		attribute.NewSet(
+			semconvnew.HTTPRequestMethodGet,
+			semconvnew.URLScheme("http"),
+			semconvnew.HTTPRoute("/error"),
+			semconvnew.HTTPResponseStatusCode(http.StatusInternalServerError),
+			semconvnew.ErrorTypeKey.String("500"),
+		): 1,
+	}, got)
+}
+
+func TestMiddlewareSuppressTracing(t *testing.T) {
+	sr, r := newFixtures(t)
+	req := httptest.NewRequest(http.MethodGet, "/users/bob", http.NoBody)
+	req = req.WithContext(splunkotel.SuppressTracing(req.Context()))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, sr.Ended())
+}
+
+func TestMiddlewarePanic(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := trace.NewTracerProvider(trace.WithSpanProcessor(sr))
+	t.Cleanup(func() { assert.NoError(t, tp.Shutdown(context.Background())) })
+	reader := sdkmetric.NewManualReader()
+
+	r := chi.NewRouter()
+	r.Use(splunkchi.Middleware(
+		splunkchi.WithTracerProvider(tp),
+		splunkchi.WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))),
+	))
+	r.Get("/panic", func(http.ResponseWriter, *http.Request) {
+		panic("handler panic")
+	})
+
+	assert.PanicsWithValue(t, "handler panic", func() {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/panic", http.NoBody))
+	})
+
+	spans := sr.Ended()
+	require.Len(t, spans, 1)
+	span := spans[0]
+	assert.Equal(t, codes.Error, span.Status().Code)
+	require.Len(t, span.Events(), 1)
+	event := span.Events()[0]
+	assert.Equal(t, semconv.ExceptionEventName, event.Name)
+	assert.Contains(t, event.Attributes, semconv.ExceptionMessageKey.String("handler panic"))
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+	require.Len(t, rm.ScopeMetrics[0].Metrics, 1)
+	h, ok := rm.ScopeMetrics[0].Metrics[0].Data.(metricdata.Histogram[float64])
+	require.True(t, ok)
+	require.Len(t, h.DataPoints, 1)
+	assert.Equal(t, uint64(1), h.DataPoints[0].Count)
+	assert.Equal(t, attribute.NewSet(
+		semconvnew.HTTPRequestMethodGet,
+		semconvnew.URLScheme("http"),
+		semconvnew.HTTPRoute("/panic"),
+		semconvnew.ErrorTypeKey.String("string"),
+	), h.DataPoints[0].Attributes)
+}
+
+func TestConformance(t *testing.T) {
+	h := instrumentationtest.New(t)
+
+	r := chi.NewRouter()
+	r.Use(splunkchi.Middleware(
+		splunkchi.WithTracerProvider(h.TracerProvider),
+		splunkchi.WithMeterProvider(h.MeterProvider),
+		splunkchi.WithPropagator(h.Propagator),
+	))
+	r.Get("/users/{user}", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/users/bob", http.NoBody)
+	parent := h.InjectRemoteParent(propagation.HeaderCarrier(req.Header))
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := h.Spans()
+	require.Len(t, spans, 1)
+	instrumentationtest.AssertConventions(t, spans)
+	instrumentationtest.AssertParent(t, spans[0], parent)
+	instrumentationtest.AssertGolden(t, filepath.Join("testdata", "conformance.json"), spans)
+
+	_, ok := h.Metric("http.server.request.duration")
+	assert.True(t, ok, "request duration not recorded")
+}
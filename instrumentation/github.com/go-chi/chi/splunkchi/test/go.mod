@@ -4,10 +4,13 @@ go 1.25.0
 
 require (
 	github.com/go-chi/chi v1.5.5
+	github.com/signalfx/splunk-otel-go v1.34.0
 	github.com/signalfx/splunk-otel-go/instrumentation/github.com/go-chi/chi/splunkchi v1.34.0
+	github.com/signalfx/splunk-otel-go/instrumentation/instrumentationtest v1.34.0
 	github.com/stretchr/testify v1.12.1
 	go.opentelemetry.io/otel v1.45.0
 	go.opentelemetry.io/otel/sdk v1.45.0
+	go.opentelemetry.io/otel/sdk/metric v1.45.0
 	go.opentelemetry.io/otel/trace v1.45.0
 )
 
@@ -18,12 +21,16 @@ require (
 	github.com/google/uuid v1.6.0 // indirect
 	github.com/signalfx/splunk-otel-go/instrumentation/internal v1.34.0 // indirect
 	go.opentelemetry.io/auto/sdk v1.2.1 // indirect
+	go.opentelemetry.io/otel/log v0.21.0 // indirect
 	go.opentelemetry.io/otel/metric v1.45.0 // indirect
+	go.opentelemetry.io/otel/sdk/log v0.21.0 // indirect
 	go.yaml.in/yaml/v3 v3.0.5 // indirect
 	golang.org/x/sys v0.47.0 // indirect
 )
 
 replace (
+	github.com/signalfx/splunk-otel-go => ../../../../../..
 	github.com/signalfx/splunk-otel-go/instrumentation/github.com/go-chi/chi/splunkchi => ../
+	github.com/signalfx/splunk-otel-go/instrumentation/instrumentationtest => ../../../../../instrumentationtest
 	github.com/signalfx/splunk-otel-go/instrumentation/internal => ../../../../../internal
 )
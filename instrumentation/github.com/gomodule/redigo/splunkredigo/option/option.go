@@ -21,6 +21,7 @@ package option
 
 import (
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/trace"
 
 	"github.com/signalfx/splunk-otel-go/instrumentation/internal"
@@ -37,8 +38,55 @@ func WithTracerProvider(tp trace.TracerProvider) Option {
 	return Option(internal.WithTracerProvider(tp))
 }
 
+// WithMeterProvider returns an Option that sets the MeterProvider used with
+// this instrumentation library.
+func WithMeterProvider(mp metric.MeterProvider) Option {
+	return Option(internal.WithMeterProvider(mp))
+}
+
 // WithAttributes returns an Option that appends attr to the attributes set
 // for every span created with this instrumentation library.
 func WithAttributes(attr []attribute.KeyValue) Option {
 	return Option(internal.WithAttributes(attr))
 }
+
+// Operation describes a Redis command traced by this instrumentation library.
+type Operation struct {
+	// Command is the name of the command. It is empty when the output buffer
+	// of a connection is flushed.
+	Command string
+	// Args are the arguments of the command.
+	Args []interface{}
+}
+
+// WithSpanNameFormatter returns an Option that sets f to name the spans
+// created for commands. If f returns an empty string the default span name,
+// the command name, is used.
+func WithSpanNameFormatter(f func(Operation) string) Option {
+	return Option(internal.WithSpanNameFormatter(f))
+}
+
+// WithFilter returns an Option that adds f to the filters of the traced
+// commands. Commands f returns false for, e.g. "PING", are not traced.
+func WithFilter(f func(Operation) bool) Option {
+	return Option(internal.WithFilter(f))
+}
+
+// WithRequireParentSpan returns an Option that sets if commands are only
+// traced when their context contains a valid parent span. Commands performed
+// outside of any trace, e.g. by background pollers, are then not traced. The
+// default is true if the SPLUNK_INSTRUMENTATION_REQUIRE_PARENT_SPAN
+// environment variable is set to "true".
+func WithRequireParentSpan(require bool) Option {
+	return Option(internal.WithRequireParentSpan(require))
+}
+
+// WithPeerServiceMapping returns an Option that maps Redis hosts to the
+// logical name of the service they are part of, set as the peer.service
+// attribute of the spans. Hosts are identified by their name or IP address,
+// optionally followed by ":<port>". The entries of m take precedence over the
+// ones set with the OTEL_INSTRUMENTATION_COMMON_PEER_SERVICE_MAPPING
+// environment variable.
+func WithPeerServiceMapping(m map[string]string) Option {
+	return Option(internal.WithPeerServiceMapping(m))
+}
@@ -27,7 +27,9 @@ import (
 	"time"
 
 	"github.com/gomodule/redigo/redis"
+	"go.opentelemetry.io/otel/attribute"
 	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	semconvstable "go.opentelemetry.io/otel/semconv/v1.37.0"
 	"go.opentelemetry.io/otel/trace"
 
 	//nolint:staticcheck // Deprecated package, but still used here.
@@ -65,6 +67,9 @@ func newConn(conn redis.Conn, opts ...option.Option) redis.Conn {
 	o := append([]internal.Option{
 		internal.OptionFunc(func(c *internal.Config) {
 			c.Version = splunkredigo.Version()
+			c.SpanSemconv = c.DBSemconv
+			c.MetricSemconv = internal.SemconvStable
+			c.RequireParentSpan = internal.RequireParentSpanFromEnv()
 		}),
 	}, localToInternal(opts)...)
 
@@ -83,7 +88,7 @@ func newConn(conn redis.Conn, opts ...option.Option) redis.Conn {
 	return struct{ redis.Conn }{&otelConn{conn, *cfg}}
 }
 
-func (c *otelConn) params(commandName string, args ...interface{}) (string, []trace.SpanStartOption) {
+func (c *otelConn) params(commandName string, args ...interface{}) (internal.Operation, []trace.SpanStartOption) {
 	name := commandName
 	if name == "" {
 		// When the command argument to the Do method is "", then the Do
@@ -92,7 +97,16 @@ func (c *otelConn) params(commandName string, args ...interface{}) (string, []tr
 		name = "redigo.Conn.Flush"
 	}
 
-	return name, []trace.SpanStartOption{
+	op := internal.Operation{
+		Name:       name,
+		Details:    option.Operation{Command: commandName, Args: args},
+		Histogram:  internal.DBClientOperationDuration,
+		Attributes: []attribute.KeyValue{semconvstable.DBSystemNameRedis},
+	}
+	if commandName != "" {
+		op.Attributes = append(op.Attributes, semconvstable.DBOperationName(commandName))
+	}
+	return op, []trace.SpanStartOption{
 		c.attrsOpt(commandName, args...),
 		trace.WithSpanKind(trace.SpanKindClient),
 	}
@@ -100,7 +114,7 @@ func (c *otelConn) params(commandName string, args ...interface{}) (string, []tr
 
 func (c *otelConn) attrsOpt(commandName string, args ...interface{}) trace.SpanStartOption {
 	if commandName == "" {
-		return trace.WithAttributes(semconv.DBSystemRedis)
+		return trace.WithAttributes(c.cfg.DBAttributes(semconv.DBSystemRedis)...)
 	}
 
 	const base10 = 10
@@ -129,10 +143,20 @@ func (c *otelConn) attrsOpt(commandName string, args ...interface{}) trace.SpanS
 		b.WriteString(" " + s)
 	}
 
-	return trace.WithAttributes(
-		semconv.DBSystemRedis,
-		semconv.DBOperationKey.String(b.String()),
-	)
+	// The v1.17.0 operation is the whole command while the stable one is
+	// only its name, the whole command being the query text.
+	attrs := c.cfg.DBAttributes(semconv.DBSystemRedis)
+	if c.cfg.DBSemconv.Old() {
+		attrs = append(attrs, semconv.DBOperationKey.String(b.String()))
+	}
+	if c.cfg.DBSemconv.Stable() {
+		attrs = append(
+			attrs,
+			semconvstable.DBOperationName(commandName),
+			semconvstable.DBQueryText(b.String()),
+		)
+	}
+	return trace.WithAttributes(attrs...)
 }
 
 // Do sends a command to the server and returns the received reply.
@@ -147,8 +171,8 @@ func (c *otelConn) Do(commandName string, args ...interface{}) (reply interface{
 		}
 	}
 
-	name, sso := c.params(commandName, args...)
-	err = c.cfg.WithSpan(ctx, name, func(context.Context) error {
+	op, sso := c.params(commandName, args...)
+	err = c.cfg.WithOperation(ctx, op, func(context.Context) error {
 		var e error
 		reply, e = c.Conn.Do(commandName, args...)
 		return e
@@ -168,8 +192,8 @@ func (c *otelConn) DoWithTimeout(timeout time.Duration, commandName string, args
 		}
 	}
 
-	name, sso := c.params(commandName, args...)
-	err = c.cfg.WithSpan(ctx, name, func(context.Context) error {
+	op, sso := c.params(commandName, args...)
+	err = c.cfg.WithOperation(ctx, op, func(context.Context) error {
 		var e error
 		// This should not panic given the guard in WrapConn.
 		reply, e = c.Conn.(redis.ConnWithTimeout).DoWithTimeout(timeout, commandName, args...)
@@ -192,8 +216,8 @@ func (c *otelConn) ReceiveWithTimeout(timeout time.Duration) (reply interface{},
 // ctx timeout return err context.DeadlineExceeded.
 // ctx canceled return err context.Canceled.
 func (c *otelConn) DoContext(ctx context.Context, commandName string, args ...interface{}) (reply interface{}, err error) {
-	name, sso := c.params(commandName, args...)
-	err = c.cfg.WithSpan(ctx, name, func(ctx context.Context) error {
+	op, sso := c.params(commandName, args...)
+	err = c.cfg.WithOperation(ctx, op, func(ctx context.Context) error {
 		var e error
 		// This should not panic given the guard in WrapConn.
 		reply, e = c.Conn.(redis.ConnWithContext).DoContext(ctx, commandName, args...)
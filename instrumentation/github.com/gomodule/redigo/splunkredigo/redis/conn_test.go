@@ -23,8 +23,15 @@ import (
 	"github.com/gomodule/redigo/redis"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
 	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	semconvstable "go.opentelemetry.io/otel/semconv/v1.37.0"
 	"go.opentelemetry.io/otel/trace"
+
+	//nolint:staticcheck // Deprecated package, but still used here.
+	"github.com/signalfx/splunk-otel-go/instrumentation/github.com/gomodule/redigo/splunkredigo/option"
 )
 
 type myStr bool
@@ -87,12 +94,105 @@ func TestParams(t *testing.T) {
 	}
 
 	for _, test := range tests {
-		gotName, gotOpts := new(otelConn).params(test.cmd, test.args...)
-		assert.Equal(t, test.wantName, gotName)
+		gotOp, gotOpts := new(otelConn).params(test.cmd, test.args...)
+		assert.Equal(t, test.wantName, gotOp.Name)
+		assert.Equal(t, option.Operation{Command: test.cmd, Args: test.args}, gotOp.Details)
 		assert.Equal(t, test.wantConfig, trace.NewSpanStartConfig(gotOpts...))
 	}
 }
 
+func TestParamsSemconvStability(t *testing.T) {
+	t.Setenv("OTEL_SEMCONV_STABILITY_OPT_IN", "database")
+	conn := newConn(new(combined))
+	c, ok := conn.(*otelConn)
+	require.True(t, ok)
+
+	_, opts := c.params(redisSetCommand, "key", 1)
+	want := trace.NewSpanStartConfig(
+		trace.WithAttributes(
+			semconvstable.DBSystemNameRedis,
+			semconvstable.DBOperationName(redisSetCommand),
+			semconvstable.DBQueryText("SET key 1"),
+		),
+		trace.WithSpanKind(trace.SpanKindClient),
+	)
+	assert.Equal(t, want, trace.NewSpanStartConfig(opts...))
+
+	t.Setenv("OTEL_SEMCONV_STABILITY_OPT_IN", "database/dup")
+	conn = newConn(new(combined))
+	c, ok = conn.(*otelConn)
+	require.True(t, ok)
+
+	_, opts = c.params(redisSetCommand, "key", 1)
+	want = trace.NewSpanStartConfig(
+		trace.WithAttributes(
+			semconv.DBSystemRedis,
+			semconvstable.DBSystemNameRedis,
+			semconv.DBOperationKey.String("SET key 1"),
+			semconvstable.DBOperationName(redisSetCommand),
+			semconvstable.DBQueryText("SET key 1"),
+		),
+		trace.WithSpanKind(trace.SpanKindClient),
+	)
+	assert.Equal(t, want, trace.NewSpanStartConfig(opts...))
+}
+
+func TestSpanNameFormatter(t *testing.T) {
+	conn := newConn(new(combined), option.WithSpanNameFormatter(func(op option.Operation) string {
+		if op.Command == "" {
+			return ""
+		}
+		return "redis " + op.Command
+	}))
+	c, ok := conn.(*otelConn)
+	require.True(t, ok)
+
+	op, _ := c.params(redisSetCommand, "key", "value")
+	assert.Equal(t, "redis SET", c.cfg.SpanName(op))
+	op, _ = c.params("")
+	assert.Equal(t, "redigo.Conn.Flush", c.cfg.SpanName(op))
+}
+
+func TestFilter(t *testing.T) {
+	conn := newConn(new(combined), option.WithFilter(func(op option.Operation) bool {
+		return op.Command != "PING"
+	}))
+	c, ok := conn.(*otelConn)
+	require.True(t, ok)
+
+	op, _ := c.params("PING")
+	assert.False(t, c.cfg.ShouldTrace(context.Background(), op))
+	op, _ = c.params(redisSetCommand, "key", "value")
+	assert.True(t, c.cfg.ShouldTrace(context.Background(), op))
+}
+
+func TestDuration(t *testing.T) {
+	r := sdkmetric.NewManualReader()
+	conn := newConn(new(combined), option.WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(r))))
+
+	_, err := conn.Do(redisSetCommand, "key", "value")
+	require.NoError(t, err)
+	_, err = conn.Do("")
+	require.NoError(t, err)
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, r.Collect(context.Background(), &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+	require.Len(t, rm.ScopeMetrics[0].Metrics, 1)
+	m := rm.ScopeMetrics[0].Metrics[0]
+	assert.Equal(t, "db.client.operation.duration", m.Name)
+	h, ok := m.Data.(metricdata.Histogram[float64])
+	require.True(t, ok)
+	got := make([]attribute.Set, 0, len(h.DataPoints))
+	for _, dp := range h.DataPoints {
+		got = append(got, dp.Attributes)
+	}
+	assert.ElementsMatch(t, []attribute.Set{
+		attribute.NewSet(semconvstable.DBSystemNameRedis, semconvstable.DBOperationName(redisSetCommand)),
+		attribute.NewSet(semconvstable.DBSystemNameRedis),
+	}, got)
+}
+
 type combined struct {
 	redis.ConnWithTimeout
 	redis.ConnWithContext
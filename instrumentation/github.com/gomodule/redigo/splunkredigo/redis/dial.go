@@ -26,9 +26,11 @@ import (
 	"go.opentelemetry.io/otel/attribute"
 	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
 	"go.opentelemetry.io/otel/semconv/v1.17.0/netconv"
+	"go.opentelemetry.io/otel/trace"
 
 	//nolint:staticcheck // Deprecated package, but still used here.
 	"github.com/signalfx/splunk-otel-go/instrumentation/github.com/gomodule/redigo/splunkredigo/option"
+	"github.com/signalfx/splunk-otel-go/instrumentation/internal"
 )
 
 // Dial dials into the network address and returns a traced redis.Conn. The
@@ -49,14 +51,8 @@ func DialContext(ctx context.Context, network, address string, options ...interf
 		return nil, err
 	}
 
-	const parsedOpts = 1
-	o := make([]option.Option, len(localOpts)+parsedOpts)
-	o[0] = option.WithAttributes(netAttributes(network, address))
-	if len(localOpts) > 0 {
-		copy(o[1:], localOpts)
-	}
-
-	return newConn(c, o...), nil
+	localOpts = append(localOpts, withNetAttributes(network, address))
+	return newConn(c, localOpts...), nil
 }
 
 var pathDBRegexp = regexp.MustCompile(`/(\d*)\z`)
@@ -101,24 +97,14 @@ func DialURLContext(ctx context.Context, rawurl string, options ...interface{})
 
 	dialOpts, localOpts := parseOptions(options...)
 
-	const parsedOpts = 1
-	o := make([]option.Option, len(localOpts)+parsedOpts)
+	var attrs []attribute.KeyValue
 	if db > 0 {
-		o[0] = option.WithAttributes(append(
-			netAttributes("tcp", net.JoinHostPort(host, port)),
-			semconv.DBRedisDBIndexKey.Int(db),
-		))
-	} else {
-		o[0] = option.WithAttributes(
-			netAttributes("tcp", net.JoinHostPort(host, port)),
-		)
-	}
-	if len(localOpts) > 0 {
-		copy(o[1:], localOpts)
+		attrs = append(attrs, semconv.DBRedisDBIndexKey.Int(db))
 	}
+	localOpts = append(localOpts, withNetAttributes("tcp", net.JoinHostPort(host, port), attrs...))
 
 	c, err := redis.DialURLContext(ctx, rawurl, dialOpts...)
-	return newConn(c, o...), err
+	return newConn(c, localOpts...), err
 }
 
 // parseOptions parses a set of arbitrary options (which can be of type
@@ -138,11 +124,33 @@ func parseOptions(options ...interface{}) ([]redis.DialOption, []option.Option)
 	return dialOpts, localOpts
 }
 
-func netAttributes(network, address string) []attribute.KeyValue {
+// withNetAttributes returns an Option that sets the network attributes of
+// the peer at address, see netAttributes, and attrs before the attributes set
+// for every span created. The v1.17.0 attributes are translated to the
+// database semantic conventions opted into. It needs to be applied after the
+// user options for the peer service mapping they set to be used.
+func withNetAttributes(network, address string, attrs ...attribute.KeyValue) option.Option {
+	return internal.OptionFunc(func(c *internal.Config) {
+		attrs = append(netAttributes(network, address, c.PeerServices), attrs...)
+		c.DefaultStartOpts = append(
+			[]trace.SpanStartOption{trace.WithAttributes(c.DBAttributes(attrs...)...)},
+			c.DefaultStartOpts...,
+		)
+	})
+}
+
+// netAttributes returns the network attributes of the peer at address,
+// including the peer.service it is mapped to by peerServices.
+func netAttributes(network, address string, peerServices internal.PeerServiceMapping) []attribute.KeyValue {
 	ip, hostname, port := splitAddress(address)
+	peer := hostname
+	if peer == "" {
+		peer = ip
+	}
+	peerService := peerServices.Attributes(peer, port)
 
 	// Guaranteed to at least return transport attribute.
-	n := 1
+	n := 1 + len(peerService)
 	if ip != "" {
 		n++
 	}
@@ -166,6 +174,7 @@ func netAttributes(network, address string) []attribute.KeyValue {
 			attrs = append(attrs, semconv.NetSockPeerPortKey.Int(port))
 		}
 	}
+	attrs = append(attrs, peerService...)
 
 	return attrs
 }
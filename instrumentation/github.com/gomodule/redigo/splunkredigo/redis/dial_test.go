@@ -24,6 +24,10 @@ import (
 	"go.opentelemetry.io/otel/attribute"
 	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
 	"go.opentelemetry.io/otel/trace"
+
+	//nolint:staticcheck // Deprecated package, but still used here.
+	"github.com/signalfx/splunk-otel-go/instrumentation/github.com/gomodule/redigo/splunkredigo/option"
+	"github.com/signalfx/splunk-otel-go/instrumentation/internal"
 )
 
 func TestNetAttributes(t *testing.T) {
@@ -66,12 +70,38 @@ func TestNetAttributes(t *testing.T) {
 	for net, netAttr := range networks {
 		for addr, addrAttrs := range addresses {
 			want := append([]attribute.KeyValue{netAttr}, addrAttrs...)
-			got := netAttributes(net, addr)
+			got := netAttributes(net, addr, nil)
 			assert.ElementsMatch(t, want, got)
 		}
 	}
 }
 
+func TestNetAttributesPeerService(t *testing.T) {
+	peerServices := internal.PeerServiceMapping{
+		"redis.local":      "cache",
+		"127.0.0.1:6380":   "sessions",
+		"other.local:6379": "other",
+	}
+
+	tests := map[string][]attribute.KeyValue{
+		"redis.local:6379": {semconv.PeerServiceKey.String("cache")},
+		"127.0.0.1:6380":   {semconv.PeerServiceKey.String("sessions")},
+		"127.0.0.1:6379":   nil,
+		"other.local:6380": nil,
+	}
+
+	for addr, want := range tests {
+		attrs := netAttributes("tcp", addr, peerServices)
+		var got []attribute.KeyValue
+		for _, a := range attrs {
+			if a.Key == semconv.PeerServiceKey {
+				got = append(got, a)
+			}
+		}
+		assert.Equal(t, want, got, addr)
+	}
+}
+
 func TestDialContextForwardsError(t *testing.T) {
 	// This should fail because it is not going to be able to connect to a
 	// Redis server and the lookup of DB 15 will fail.
@@ -155,3 +185,36 @@ func TestDialURLContextAttrs(t *testing.T) {
 		})
 	}
 }
+
+func TestDialURLContextPeerService(t *testing.T) {
+	t.Setenv("OTEL_INSTRUMENTATION_COMMON_PEER_SERVICE_MAPPING", "fake.localhost=env-cache")
+
+	tests := []struct {
+		name string
+		opts []interface{}
+		want attribute.KeyValue
+	}{
+		{
+			name: "env",
+			want: semconv.PeerServiceKey.String("env-cache"),
+		},
+		{
+			name: "option",
+			opts: []interface{}{option.WithPeerServiceMapping(map[string]string{
+				"fake.localhost:6379": "option-cache",
+			})},
+			want: semconv.PeerServiceKey.String("option-cache"),
+		},
+	}
+
+	ctx := context.Background()
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Do not check error as we only care about conn.
+			conn, _ := DialURLContext(ctx, "redis://fake.localhost:6379", test.opts...)
+			oConn := conn.(struct{ redis.Conn }).Conn.(*otelConn)
+			sConf := trace.NewSpanStartConfig(oConn.cfg.DefaultStartOpts...)
+			assert.Contains(t, sConf.Attributes(), test.want)
+		})
+	}
+}
@@ -8,6 +8,7 @@ require (
 	github.com/graph-gophers/graphql-go v1.10.2
 	github.com/signalfx/splunk-otel-go/instrumentation/internal v1.34.0
 	go.opentelemetry.io/otel v1.45.0
+	go.opentelemetry.io/otel/metric v1.45.0
 	go.opentelemetry.io/otel/trace v1.45.0
 )
 
@@ -15,8 +16,11 @@ require (
 	github.com/cespare/xxhash/v2 v2.3.0 // indirect
 	github.com/go-logr/logr v1.4.4 // indirect
 	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/signalfx/splunk-otel-go v1.34.0 // indirect
 	go.opentelemetry.io/auto/sdk v1.2.1 // indirect
-	go.opentelemetry.io/otel/metric v1.45.0 // indirect
 )
 
-replace github.com/signalfx/splunk-otel-go/instrumentation/internal => ../../../../internal
+replace (
+	github.com/signalfx/splunk-otel-go => ../../../../..
+	github.com/signalfx/splunk-otel-go/instrumentation/internal => ../../../../internal
+)
@@ -22,11 +22,14 @@ package splunkgraphql
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/graph-gophers/graphql-go/errors"
 	"github.com/graph-gophers/graphql-go/introspection"
 	"github.com/graph-gophers/graphql-go/trace/tracer"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
 	oteltrace "go.opentelemetry.io/otel/trace"
 
 	gql "github.com/signalfx/splunk-otel-go/instrumentation/github.com/graph-gophers/graphql-go/splunkgraphql/internal"
@@ -35,6 +38,15 @@ import (
 
 const instrumentationName = "github.com/signalfx/splunk-otel-go/instrumentation/github.com/graph-gophers/graphql-go/splunkgraphql"
 
+// requestDuration is the histogram the duration of GraphQL requests is
+// recorded in. The semantic conventions do not define GraphQL metrics, its
+// name and boundaries follow the ones of HTTP server requests.
+var requestDuration = &internal.DurationHistogram{
+	Name:        "graphql.server.request.duration",
+	Description: "Duration of GraphQL server requests.",
+	Boundaries:  []float64{0.005, 0.01, 0.025, 0.05, 0.075, 0.1, 0.25, 0.5, 0.75, 1, 2.5, 5, 7.5, 10},
+}
+
 // otelTracer implements the graphql-go/trace.Tracer interface using
 // OpenTelemetry.
 type otelTracer struct {
@@ -51,6 +63,8 @@ func NewTracer(opts ...Option) tracer.Tracer {
 	o := append([]internal.Option{
 		internal.OptionFunc(func(c *internal.Config) {
 			c.Version = Version()
+			c.SpanSemconv = internal.SemconvStable
+			c.MetricSemconv = internal.SemconvStable
 		}),
 	}, localToInternal(opts)...)
 
@@ -77,26 +91,62 @@ func traceQueryFinishFunc(span oteltrace.Span) tracer.ValidationFinishFunc {
 }
 
 // TraceQuery traces a GraphQL query.
-func (t *otelTracer) TraceQuery(ctx context.Context, queryString, _ string, _ map[string]interface{}, _ map[string]*introspection.Type) (context.Context, tracer.QueryFinishFunc) { //nolint: gocritic  // un-named returned values.
+func (t *otelTracer) TraceQuery(ctx context.Context, queryString, operationName string, _ map[string]interface{}, _ map[string]*introspection.Type) (context.Context, tracer.QueryFinishFunc) { //nolint: gocritic  // un-named returned values.
+	op := internal.Operation{
+		Name: "GraphQL request",
+		Details: Operation{
+			Type:          OperationRequest,
+			Query:         queryString,
+			OperationName: operationName,
+		},
+		Histogram: requestDuration,
+	}
+	if !t.cfg.ShouldTrace(ctx, op) {
+		return ctx, func([]*errors.QueryError) {}
+	}
+
+	// The operation name is set by clients, it is unbounded and only added
+	// to the span, not to the duration.
+	attrs := []attribute.KeyValue{gql.GraphQLQueryKey.String(queryString)}
+	if operationName != "" {
+		attrs = append(attrs, semconv.GraphQLOperationName(operationName))
+	}
 	spanCtx, span := t.cfg.ResolveTracer(ctx).Start(
 		ctx,
-		"GraphQL request",
+		t.cfg.SpanName(op),
 		oteltrace.WithSpanKind(oteltrace.SpanKindServer),
-		oteltrace.WithAttributes(gql.GraphQLQueryKey.String(queryString)),
+		oteltrace.WithAttributes(attrs...),
 	)
 
-	return spanCtx, traceQueryFinishFunc(span)
+	start := time.Now()
+	finish := traceQueryFinishFunc(span)
+	return spanCtx, func(errs []*errors.QueryError) {
+		var attrs []attribute.KeyValue
+		if len(errs) > 0 {
+			attrs = append(attrs, internal.ErrorType(errs[0]))
+		}
+		t.cfg.RecordDuration(spanCtx, op, time.Since(start), attrs...)
+		finish(errs)
+	}
 }
 
 // TraceField traces a GraphQL field access.
 func (t *otelTracer) TraceField(ctx context.Context, _, typeName, fieldName string, trivial bool, _ map[string]interface{}) (context.Context, tracer.FieldFinishFunc) { //nolint: gocritic  // un-named returned values.
-	if trivial {
+	op := internal.Operation{
+		Name: "GraphQL field",
+		Details: Operation{
+			Type:      OperationField,
+			TypeName:  typeName,
+			FieldName: fieldName,
+		},
+	}
+	if trivial || !t.cfg.ShouldTrace(ctx, op) {
 		return ctx, func(*errors.QueryError) {}
 	}
 
 	spanCtx, span := t.cfg.ResolveTracer(ctx).Start(
 		ctx,
-		"GraphQL field",
+		t.cfg.SpanName(op),
 		oteltrace.WithSpanKind(oteltrace.SpanKindServer),
 		oteltrace.WithAttributes(
 			gql.GraphQLFieldKey.String(fieldName),
@@ -115,9 +165,17 @@ func (t *otelTracer) TraceField(ctx context.Context, _, typeName, fieldName stri
 
 // TraceValidation traces the schema validation step preceding an operation.
 func (t *otelTracer) TraceValidation(ctx context.Context) tracer.ValidationFinishFunc {
+	op := internal.Operation{
+		Name:    "GraphQL validation",
+		Details: Operation{Type: OperationValidation},
+	}
+	if !t.cfg.ShouldTrace(ctx, op) {
+		return func([]*errors.QueryError) {}
+	}
+
 	_, span := t.cfg.ResolveTracer(ctx).Start(
 		ctx,
-		"GraphQL validation",
+		t.cfg.SpanName(op),
 		oteltrace.WithSpanKind(oteltrace.SpanKindInternal),
 	)
 	return traceQueryFinishFunc(span)
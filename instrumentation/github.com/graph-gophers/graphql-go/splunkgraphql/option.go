@@ -16,6 +16,7 @@ package splunkgraphql
 
 import (
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/trace"
 
 	"github.com/signalfx/splunk-otel-go/instrumentation/internal"
@@ -48,8 +49,58 @@ func WithTracerProvider(tp trace.TracerProvider) Option {
 	return optionConv{iOpt: internal.WithTracerProvider(tp)}
 }
 
+// WithMeterProvider returns an Option that sets the MeterProvider used for
+// a configuration.
+func WithMeterProvider(mp metric.MeterProvider) Option {
+	return optionConv{iOpt: internal.WithMeterProvider(mp)}
+}
+
 // WithAttributes returns an Option that appends attr to the attributes set
 // for every span created.
 func WithAttributes(attr []attribute.KeyValue) Option {
 	return optionConv{iOpt: internal.WithAttributes(attr)}
 }
+
+// OperationType is the type of a traced GraphQL operation.
+type OperationType string
+
+const (
+	// OperationRequest is the processing of a GraphQL request.
+	OperationRequest OperationType = "request"
+	// OperationField is the resolution of a GraphQL field.
+	OperationField OperationType = "field"
+	// OperationValidation is the validation of a GraphQL request.
+	OperationValidation OperationType = "validation"
+)
+
+// Operation describes a GraphQL operation traced by this instrumentation
+// library.
+type Operation struct {
+	// Type is the type of the operation.
+	Type OperationType
+	// Query is the query of the request. It is only set for OperationRequest.
+	Query string
+	// OperationName is the name of the requested GraphQL operation, if any.
+	// It is only set for OperationRequest.
+	OperationName string
+	// TypeName is the name of the type the resolved field belongs to. It is
+	// only set for OperationField.
+	TypeName string
+	// FieldName is the name of the resolved field. It is only set for
+	// OperationField.
+	FieldName string
+}
+
+// WithSpanNameFormatter returns an Option that sets f to name the spans
+// created for operations. If f returns an empty string the default span
+// name, e.g. "GraphQL request", is used.
+func WithSpanNameFormatter(f func(Operation) string) Option {
+	return optionConv{iOpt: internal.WithSpanNameFormatter(f)}
+}
+
+// WithFilter returns an Option that adds f to the filters of the traced
+// operations. Operations f returns false for, e.g. the validation of
+// requests, are not traced.
+func WithFilter(f func(Operation) bool) Option {
+	return optionConv{iOpt: internal.WithFilter(f)}
+}
@@ -34,8 +34,12 @@ import (
 	"github.com/graph-gophers/graphql-go/relay"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
 	"go.opentelemetry.io/otel/sdk/trace"
 	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
 	traceapi "go.opentelemetry.io/otel/trace"
 
 	//nolint:staticcheck // Deprecated package, but still used here.
@@ -60,12 +64,13 @@ type testResolver struct{}
 func (*testResolver) Hello() string                    { return helloWorld }
 func (*testResolver) HelloNonTrivial() (string, error) { return helloWorld, nil }
 
-func fixtures(t *testing.T) (*tracetest.SpanRecorder, *httptest.Server) {
+func fixtures(t *testing.T, opts ...splunkgraphql.Option) (*tracetest.SpanRecorder, *httptest.Server) {
 	sr := tracetest.NewSpanRecorder()
 	tp := trace.NewTracerProvider(trace.WithSpanProcessor(sr))
 	t.Cleanup(func() { assert.NoError(t, tp.Shutdown(context.Background())) })
 
-	tracer := graphql.Tracer(splunkgraphql.NewTracer(splunkgraphql.WithTracerProvider(tp)))
+	opts = append([]splunkgraphql.Option{splunkgraphql.WithTracerProvider(tp)}, opts...)
+	tracer := graphql.Tracer(splunkgraphql.NewTracer(opts...))
 	schema := graphql.MustParseSchema(testSchema, new(testResolver), tracer)
 	srv := httptest.NewServer(&relay.Handler{Schema: schema})
 	t.Cleanup(srv.Close)
@@ -134,3 +139,86 @@ func TestTracerTrivial(t *testing.T) {
 	assert.Equal(t, "GraphQL request", s1.Name())
 	assert.Contains(t, s1.Attributes(), internal.GraphQLQueryKey.String("{ hello }"))
 }
+
+func TestTracerSpanNameFormatter(t *testing.T) {
+	sr, srv := fixtures(t, splunkgraphql.WithSpanNameFormatter(func(op splunkgraphql.Operation) string {
+		switch op.Type {
+		case splunkgraphql.OperationRequest:
+			return "GraphQL " + op.OperationName
+		case splunkgraphql.OperationField:
+			return "GraphQL " + op.TypeName + "." + op.FieldName
+		default:
+			return ""
+		}
+	}))
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, srv.URL, strings.NewReader(`{
+		"query": "query TestQuery() { helloNonTrivial }",
+		"operationName": "TestQuery"
+	}`))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { assert.NoError(t, resp.Body.Close()) })
+	_, err = io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	spans := sr.Ended()
+	require.Len(t, spans, 3)
+	assert.Equal(t, "GraphQL validation", spans[0].Name())
+	assert.Equal(t, "GraphQL Query.helloNonTrivial", spans[1].Name())
+	assert.Equal(t, "GraphQL TestQuery", spans[2].Name())
+}
+
+func TestTracerFilter(t *testing.T) {
+	sr, srv := fixtures(t, splunkgraphql.WithFilter(func(op splunkgraphql.Operation) bool {
+		return op.Type != splunkgraphql.OperationValidation
+	}))
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, srv.URL, strings.NewReader(`{
+		"query": "{ helloNonTrivial }"
+	}`))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { assert.NoError(t, resp.Body.Close()) })
+	_, err = io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	spans := sr.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "GraphQL field", spans[0].Name())
+	assert.Equal(t, "GraphQL request", spans[1].Name())
+}
+
+func TestTracerDuration(t *testing.T) {
+	r := sdkmetric.NewManualReader()
+	sr, srv := fixtures(t, splunkgraphql.WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(r))))
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, srv.URL, strings.NewReader(`{
+		"query": "query TestQuery() { helloNonTrivial }",
+		"operationName": "TestQuery"
+	}`))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { assert.NoError(t, resp.Body.Close()) })
+	_, err = io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, r.Collect(context.Background(), &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+	require.Len(t, rm.ScopeMetrics[0].Metrics, 1)
+	m := rm.ScopeMetrics[0].Metrics[0]
+	assert.Equal(t, "graphql.server.request.duration", m.Name)
+	h, ok := m.Data.(metricdata.Histogram[float64])
+	require.True(t, ok)
+	require.Len(t, h.DataPoints, 1)
+	assert.Equal(t, uint64(1), h.DataPoints[0].Count)
+	assert.Equal(t, attribute.NewSet(), h.DataPoints[0].Attributes, "unbounded operation name recorded")
+
+	spans := sr.Ended()
+	require.NotEmpty(t, spans)
+	assert.Contains(t, spans[len(spans)-1].Attributes(), semconv.GraphQLOperationName("TestQuery"))
+}
@@ -2,12 +2,6 @@ module github.com/signalfx/splunk-otel-go/instrumentation/github.com/lib/pq/splu
 
 go 1.25.5
 
-replace (
-	github.com/signalfx/splunk-otel-go/instrumentation/database/sql/splunksql => ../../../../../database/sql/splunksql
-	github.com/signalfx/splunk-otel-go/instrumentation/github.com/lib/pq/splunkpq => ../
-	github.com/signalfx/splunk-otel-go/instrumentation/internal => ../../../../../internal
-)
-
 require (
 	github.com/moby/moby/api v1.55.0
 	github.com/ory/dockertest/v4 v4.0.0
@@ -36,6 +30,7 @@ require (
 	github.com/moby/moby/client v0.5.1 // indirect
 	github.com/opencontainers/go-digest v1.0.0 // indirect
 	github.com/opencontainers/image-spec v1.1.1 // indirect
+	github.com/signalfx/splunk-otel-go v1.34.0 // indirect
 	github.com/signalfx/splunk-otel-go/instrumentation/internal v1.34.0 // indirect
 	go.opentelemetry.io/auto/sdk v1.2.1 // indirect
 	go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp v0.70.0 // indirect
@@ -44,3 +39,10 @@ require (
 	go.yaml.in/yaml/v3 v3.0.5 // indirect
 	golang.org/x/sys v0.47.0 // indirect
 )
+
+replace (
+	github.com/signalfx/splunk-otel-go => ../../../../../..
+	github.com/signalfx/splunk-otel-go/instrumentation/database/sql/splunksql => ../../../../../database/sql/splunksql
+	github.com/signalfx/splunk-otel-go/instrumentation/github.com/lib/pq/splunkpq => ../
+	github.com/signalfx/splunk-otel-go/instrumentation/internal => ../../../../../internal
+)
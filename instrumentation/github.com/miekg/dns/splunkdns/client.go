@@ -36,6 +36,9 @@ func WrapClient(client *dns.Client, opts ...Option) *Client {
 	o := append([]internal.Option{
 		internal.OptionFunc(func(c *internal.Config) {
 			c.Version = Version()
+			c.SpanSemconv = internal.SemconvStable
+			c.MetricSemconv = internal.SemconvStable
+			c.RequireParentSpan = internal.RequireParentSpanFromEnv()
 		}),
 	}, localToInternal(opts)...)
 
@@ -56,7 +59,7 @@ func (c *Client) Exchange(m *dns.Msg, addr string) (*dns.Msg, time.Duration, err
 // ExchangeContext calls the underlying Client.ExchangeContext and traces the
 // request.
 func (c *Client) ExchangeContext(ctx context.Context, m *dns.Msg, addr string) (resp *dns.Msg, rtt time.Duration, err error) {
-	err = c.cfg.WithSpan(ctx, name(m), func(ctx context.Context) error {
+	err = c.cfg.WithOperation(ctx, operation(trace.SpanKindClient, m, addr), func(ctx context.Context) error {
 		var sErr error
 		resp, rtt, sErr = c.Client.ExchangeContext(ctx, m, addr)
 		return sErr
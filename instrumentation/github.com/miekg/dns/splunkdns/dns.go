@@ -54,11 +54,14 @@ func ExchangeContext(ctx context.Context, m *dns.Msg, addr string, opts ...Optio
 	o := append([]internal.Option{
 		internal.OptionFunc(func(c *internal.Config) {
 			c.Version = Version()
+			c.SpanSemconv = internal.SemconvStable
+			c.MetricSemconv = internal.SemconvStable
+			c.RequireParentSpan = internal.RequireParentSpanFromEnv()
 		}),
 	}, localToInternal(opts)...)
 
 	cfg := internal.NewConfig(instrumentationName, o...)
-	err = cfg.WithSpan(ctx, name(m), func(c context.Context) error {
+	err = cfg.WithOperation(ctx, operation(trace.SpanKindClient, m, addr), func(c context.Context) error {
 		var sErr error
 		r, sErr = dns.ExchangeContext(c, m, addr)
 		return sErr
@@ -36,19 +36,24 @@ func WrapHandler(handler dns.Handler, opts ...Option) *Handler {
 	o := append([]internal.Option{
 		internal.OptionFunc(func(c *internal.Config) {
 			c.Version = Version()
+			c.SpanSemconv = internal.SemconvStable
+			c.MetricSemconv = internal.SemconvStable
 		}),
 	}, localToInternal(opts)...)
 
+	cfg := internal.NewConfig(instrumentationName, o...)
+	// Served requests are the root of the traces of the queries they handle.
+	cfg.RequireParentSpan = false
 	return &Handler{
 		Handler: handler,
-		cfg:     internal.NewConfig(instrumentationName, o...),
+		cfg:     cfg,
 	}
 }
 
 // ServeDNS dispatches requests to the underlying Handler. All requests will
 // be traced.
 func (h *Handler) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
-	_ = h.cfg.WithSpan(context.Background(), name(r), func(context.Context) error {
+	_ = h.cfg.WithOperation(context.Background(), operation(trace.SpanKindServer, r, ""), func(context.Context) error {
 		rw := &responseWriter{ResponseWriter: w}
 		h.Handler.ServeDNS(rw, r)
 		return rw.err
@@ -16,6 +16,8 @@ package splunkdns
 
 import (
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
 	"go.opentelemetry.io/otel/trace"
 
 	"github.com/miekg/dns"
@@ -39,8 +41,32 @@ func localToInternal(opts []Option) []internal.Option {
 	return out
 }
 
-func name(m *dns.Msg) string {
-	return "DNS " + dns.OpcodeToString[m.Opcode]
+// Operation describes a DNS operation traced by this instrumentation
+// library.
+type Operation struct {
+	// Kind is trace.SpanKindClient for exchanged queries and
+	// trace.SpanKindServer for served requests.
+	Kind trace.SpanKind
+	// Msg is the DNS message exchanged or served.
+	Msg *dns.Msg
+	// Addr is the address of the server a query is sent to. It is empty for
+	// served requests.
+	Addr string
+}
+
+func operation(kind trace.SpanKind, m *dns.Msg, addr string) internal.Operation {
+	op := internal.Operation{
+		Name:    "DNS " + dns.OpcodeToString[m.Opcode],
+		Details: Operation{Kind: kind, Msg: m, Addr: addr},
+	}
+	// Only the lookups performed by clients are measured.
+	if kind == trace.SpanKindClient && len(m.Question) > 0 {
+		op.Histogram = internal.DNSLookupDuration
+		op.Attributes = []attribute.KeyValue{
+			semconv.DNSQuestionName(m.Question[0].Name),
+		}
+	}
+	return op
 }
 
 // WithTracerProvider returns an Option that sets the TracerProvider used with
@@ -49,8 +75,39 @@ func WithTracerProvider(tp trace.TracerProvider) Option {
 	return Option(internal.WithTracerProvider(tp))
 }
 
+// WithMeterProvider returns an Option that sets the MeterProvider used with
+// this instrumentation library.
+func WithMeterProvider(mp metric.MeterProvider) Option {
+	return Option(internal.WithMeterProvider(mp))
+}
+
 // WithAttributes returns an Option that appends attr to the attributes set
 // for every span created with this instrumentation library.
 func WithAttributes(attr []attribute.KeyValue) Option {
 	return Option(internal.WithAttributes(attr))
 }
+
+// WithSpanNameFormatter returns an Option that sets f to name the spans
+// created for operations. If f returns an empty string the default span name,
+// "DNS " followed by the opcode of the message, is used.
+func WithSpanNameFormatter(f func(Operation) string) Option {
+	return Option(internal.WithSpanNameFormatter(f))
+}
+
+// WithFilter returns an Option that adds f to the filters of the traced
+// operations. Operations f returns false for, e.g. queries for a specific
+// zone, are not traced.
+func WithFilter(f func(Operation) bool) Option {
+	return Option(internal.WithFilter(f))
+}
+
+// WithRequireParentSpan returns an Option that sets if queries are only traced
+// when their context contains a valid parent span. Queries performed outside
+// of any trace, e.g. by background pollers, are then not traced. It only
+// applies to the queries exchanged by clients, served requests are always
+// traced. The default is true if the
+// SPLUNK_INSTRUMENTATION_REQUIRE_PARENT_SPAN environment variable is set to
+// "true".
+func WithRequireParentSpan(require bool) Option {
+	return Option(internal.WithRequireParentSpan(require))
+}
@@ -25,8 +25,11 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
 	"go.opentelemetry.io/otel/sdk/trace"
 	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
 	traceapi "go.opentelemetry.io/otel/trace"
 
 	//nolint:staticcheck // Deprecated package, but still used here.
@@ -164,3 +167,102 @@ func TestClientExchangeContext(t *testing.T) {
 	require.Len(t, spans, 1)
 	assertClientSpan(t, spans[0])
 }
+
+func TestClientSpanNameFormatter(t *testing.T) {
+	server, sr, opts, msg := newFixtures(t)
+
+	var got splunkdns.Operation
+	opts = append(opts, splunkdns.WithSpanNameFormatter(func(op splunkdns.Operation) string {
+		got = op
+		return "DNS " + op.Msg.Question[0].Name
+	}))
+	client := splunkdns.WrapClient(&dns.Client{Net: "udp"}, opts...)
+	_, _, err := client.Exchange(msg, server.Addr)
+	assert.NoError(t, err)
+
+	spans := sr.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "DNS miek.nl.", spans[0].Name())
+	assert.Equal(t, traceapi.SpanKindClient, got.Kind)
+	assert.Equal(t, server.Addr, got.Addr)
+	assert.Same(t, msg, got.Msg)
+}
+
+func TestClientFilter(t *testing.T) {
+	server, sr, opts, msg := newFixtures(t)
+
+	opts = append(opts, splunkdns.WithFilter(func(op splunkdns.Operation) bool {
+		return !dns.IsSubDomain("miek.nl.", op.Msg.Question[0].Name)
+	}))
+	client := splunkdns.WrapClient(&dns.Client{Net: "udp"}, opts...)
+	_, _, err := client.Exchange(msg, server.Addr)
+	assert.NoError(t, err)
+	assert.Empty(t, sr.Ended())
+
+	other := new(dns.Msg)
+	other.SetQuestion("example.com.", dns.TypeA)
+	_, _, err = client.Exchange(other, server.Addr)
+	assert.NoError(t, err)
+	assert.Len(t, sr.Ended(), 1)
+}
+
+func TestClientDuration(t *testing.T) {
+	server, _, opts, msg := newFixtures(t)
+
+	r := sdkmetric.NewManualReader()
+	opts = append(opts, splunkdns.WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(r))))
+	client := splunkdns.WrapClient(&dns.Client{Net: "udp"}, opts...)
+	_, _, err := client.Exchange(msg, server.Addr)
+	assert.NoError(t, err)
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, r.Collect(context.Background(), &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+	require.Len(t, rm.ScopeMetrics[0].Metrics, 1)
+	m := rm.ScopeMetrics[0].Metrics[0]
+	assert.Equal(t, "dns.lookup.duration", m.Name)
+	h, ok := m.Data.(metricdata.Histogram[float64])
+	require.True(t, ok)
+	require.Len(t, h.DataPoints, 1)
+	assert.Equal(t, uint64(1), h.DataPoints[0].Count)
+	assert.Equal(t, attribute.NewSet(semconv.DNSQuestionName("miek.nl.")), h.DataPoints[0].Attributes)
+}
+
+func TestClientRequireParentSpan(t *testing.T) {
+	server, _, _, msg := newFixtures(t)
+
+	sr := tracetest.NewSpanRecorder()
+	tp := trace.NewTracerProvider(trace.WithSpanProcessor(sr))
+	client := splunkdns.WrapClient(
+		&dns.Client{Net: "udp"},
+		splunkdns.WithTracerProvider(tp),
+		splunkdns.WithRequireParentSpan(true),
+	)
+
+	_, _, err := client.ExchangeContext(context.Background(), msg, server.Addr)
+	assert.NoError(t, err)
+	assert.Empty(t, sr.Ended(), "orphan query traced")
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "parent")
+	_, _, err = client.ExchangeContext(ctx, msg, server.Addr)
+	assert.NoError(t, err)
+	parent.End()
+
+	spans := sr.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, parent.SpanContext(), spans[0].Parent())
+}
+
+func TestClientRequireParentSpanEnv(t *testing.T) {
+	t.Setenv("SPLUNK_INSTRUMENTATION_REQUIRE_PARENT_SPAN", "true")
+	server, sr, opts, msg := newFixtures(t)
+
+	_, err := splunkdns.Exchange(msg, server.Addr, opts...)
+	assert.NoError(t, err)
+	assert.Empty(t, sr.Ended(), "orphan query traced")
+
+	opts = append(opts, splunkdns.WithRequireParentSpan(false))
+	_, err = splunkdns.Exchange(msg, server.Addr, opts...)
+	assert.NoError(t, err)
+	assert.Len(t, sr.Ended(), 1)
+}
@@ -26,6 +26,7 @@ import (
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/sdk/trace"
 	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
 	traceapi "go.opentelemetry.io/otel/trace"
 
 	//nolint:staticcheck // Deprecated package, but still used here.
@@ -99,3 +100,26 @@ func TestHandler(t *testing.T) {
 	assert.Equal(t, codes.Error, errSpan.Status().Code)
 	assert.Equal(t, dns.RcodeToString[errCode], errSpan.Status().Description)
 }
+
+func TestHandlerPanic(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := trace.NewTracerProvider(trace.WithSpanProcessor(sr))
+
+	handler := splunkdns.WrapHandler(dns.HandlerFunc(func(dns.ResponseWriter, *dns.Msg) {
+		panic("handler panic")
+	}), splunkdns.WithTracerProvider(tp))
+
+	msg := new(dns.Msg)
+	msg.SetQuestion("miek.nl.", dns.TypeMX)
+	assert.PanicsWithValue(t, "handler panic", func() {
+		handler.ServeDNS(nil, msg)
+	})
+
+	spans := sr.Ended()
+	require.Len(t, spans, 1)
+	span := spans[0]
+	assert.Equal(t, codes.Error, span.Status().Code)
+	require.Len(t, span.Events(), 1)
+	assert.Equal(t, semconv.ExceptionEventName, span.Events()[0].Name)
+	assert.Contains(t, span.Events()[0].Attributes, semconv.ExceptionMessageKey.String("handler panic"))
+}
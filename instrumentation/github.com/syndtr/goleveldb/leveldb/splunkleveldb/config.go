@@ -18,7 +18,9 @@ import (
 	"context"
 
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
 	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	semconvnew "go.opentelemetry.io/otel/semconv/v1.37.0"
 	"go.opentelemetry.io/otel/trace"
 
 	"github.com/signalfx/splunk-otel-go/instrumentation/internal"
@@ -40,11 +42,14 @@ func newConfig(options ...Option) *config {
 			instrumentationName, internal.OptionFunc(
 				func(c *internal.Config) {
 					c.Version = Version()
+					c.SpanSemconv = c.DBSemconv
+					c.MetricSemconv = internal.SemconvStable
+					c.RequireParentSpan = internal.RequireParentSpanFromEnv()
 					c.DefaultStartOpts = []trace.SpanStartOption{
-						trace.WithAttributes(
+						trace.WithAttributes(c.DBAttributes(
 							semconv.DBSystemKey.String("leveldb"),
 							semconv.NetTransportInProc,
-						),
+						)...),
 						// From the specification: span kind MUST always be CLIENT.
 						trace.WithSpanKind(trace.SpanKindClient),
 					}
@@ -59,10 +64,43 @@ func newConfig(options ...Option) *config {
 			o.apply(&c)
 		}
 	}
+	// The options may have set the Tracer or Meter of a disabled
+	// instrumentation.
+	c.ApplyEnabled()
 
 	return &c
 }
 
+// withSpan wraps the function f with a span for the method.
+func (c *config) withSpan(method string, f func(context.Context) error) error {
+	return c.WithOperation(
+		c.ctx,
+		operation(method),
+		f,
+		trace.WithAttributes(c.DBAttributes(semconv.DBOperationKey.String(method))...),
+	)
+}
+
+// Operation describes a leveldb operation traced by this instrumentation
+// library.
+type Operation struct {
+	// Method is the name of the traced method (e.g. "Get", "Put",
+	// "Iterator").
+	Method string
+}
+
+func operation(method string) internal.Operation {
+	return internal.Operation{
+		Name:      method,
+		Details:   Operation{Method: method},
+		Histogram: internal.DBClientOperationDuration,
+		Attributes: []attribute.KeyValue{
+			semconvnew.DBSystemNameKey.String("leveldb"),
+			semconvnew.DBOperationName(method),
+		},
+	}
+}
+
 // Option applies options to a configuration.
 type Option interface {
 	apply(*config)
@@ -82,12 +120,40 @@ func WithTracerProvider(tp trace.TracerProvider) Option {
 	return optConv{iOpt: internal.WithTracerProvider(tp)}
 }
 
+// WithMeterProvider returns an Option that sets the MeterProvider used with
+// this instrumentation library.
+func WithMeterProvider(mp metric.MeterProvider) Option {
+	return optConv{iOpt: internal.WithMeterProvider(mp)}
+}
+
 // WithAttributes returns an Option that appends attr to the attributes set
 // for every span created with this instrumentation library.
 func WithAttributes(attr []attribute.KeyValue) Option {
 	return optConv{iOpt: internal.WithAttributes(attr)}
 }
 
+// WithSpanNameFormatter returns an Option that sets f to name the spans
+// created for operations. If f returns an empty string the default span
+// name, the method name, is used.
+func WithSpanNameFormatter(f func(Operation) string) Option {
+	return optConv{iOpt: internal.WithSpanNameFormatter(f)}
+}
+
+// WithFilter returns an Option that adds f to the filters of the traced
+// operations. Operations f returns false for are not traced.
+func WithFilter(f func(Operation) bool) Option {
+	return optConv{iOpt: internal.WithFilter(f)}
+}
+
+// WithRequireParentSpan returns an Option that sets if operations are only
+// traced when their context contains a valid parent span. Operations performed
+// outside of any trace, e.g. by background pollers, are then not traced. The
+// default is true if the SPLUNK_INSTRUMENTATION_REQUIRE_PARENT_SPAN
+// environment variable is set to "true".
+func WithRequireParentSpan(require bool) Option {
+	return optConv{iOpt: internal.WithRequireParentSpan(require)}
+}
+
 type optionFunc func(*config)
 
 func (o optionFunc) apply(c *config) {
@@ -27,8 +27,6 @@ import (
 	"github.com/syndtr/goleveldb/leveldb/opt"
 	"github.com/syndtr/goleveldb/leveldb/storage"
 	"github.com/syndtr/goleveldb/leveldb/util"
-	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
-	"go.opentelemetry.io/otel/trace"
 )
 
 // DB wraps a *leveldb.DB, tracing all operations performed.
@@ -141,11 +139,9 @@ func (db *DB) WithContext(ctx context.Context) *DB {
 // And a nil Range.Limit is treated as a key after all keys in the DB.
 // Therefore if both is nil then it will compact entire DB.
 func (db *DB) CompactRange(r util.Range) error {
-	return db.cfg.WithSpan(
-		db.cfg.ctx,
+	return db.cfg.withSpan(
 		"CompactRange",
 		func(context.Context) error { return db.DB.CompactRange(r) },
-		trace.WithAttributes(semconv.DBOperationKey.String("CompactRange")),
 	)
 }
 
@@ -155,11 +151,9 @@ func (db *DB) CompactRange(r util.Range) error {
 // It is safe to modify the contents of the arguments after Delete returns but
 // not before.
 func (db *DB) Delete(key []byte, wo *opt.WriteOptions) error {
-	return db.cfg.WithSpan(
-		db.cfg.ctx,
+	return db.cfg.withSpan(
 		"Delete",
 		func(context.Context) error { return db.DB.Delete(key, wo) },
-		trace.WithAttributes(semconv.DBOperationKey.String("Delete")),
 	)
 }
 
@@ -170,15 +164,13 @@ func (db *DB) Delete(key []byte, wo *opt.WriteOptions) error {
 // of the returned slice.
 // It is safe to modify the contents of the argument after Get returns.
 func (db *DB) Get(key []byte, ro *opt.ReadOptions) (value []byte, err error) {
-	err = db.cfg.WithSpan(
-		db.cfg.ctx,
+	err = db.cfg.withSpan(
 		"Get",
 		func(context.Context) error {
 			var e error
 			value, e = db.DB.Get(key, ro)
 			return e
 		},
-		trace.WithAttributes(semconv.DBOperationKey.String("Get")),
 	)
 	return value, err
 }
@@ -202,15 +194,13 @@ func (db *DB) GetSnapshot() (*Snapshot, error) {
 //
 // It is safe to modify the contents of the argument after Has returns.
 func (db *DB) Has(key []byte, ro *opt.ReadOptions) (ret bool, err error) {
-	err = db.cfg.WithSpan(
-		db.cfg.ctx,
+	err = db.cfg.withSpan(
 		"Has",
 		func(context.Context) error {
 			var e error
 			ret, e = db.DB.Has(key, ro)
 			return e
 		},
-		trace.WithAttributes(semconv.DBOperationKey.String("Has")),
 	)
 	return ret, err
 }
@@ -269,11 +259,9 @@ func (db *DB) OpenTransaction() (*Transaction, error) {
 // It is safe to modify the contents of the arguments after Put returns but not
 // before.
 func (db *DB) Put(key, value []byte, wo *opt.WriteOptions) error {
-	return db.cfg.WithSpan(
-		db.cfg.ctx,
+	return db.cfg.withSpan(
 		"Put",
 		func(context.Context) error { return db.DB.Put(key, value, wo) },
-		trace.WithAttributes(semconv.DBOperationKey.String("Put")),
 	)
 }
 
@@ -285,10 +273,8 @@ func (db *DB) Put(key, value []byte, wo *opt.WriteOptions) error {
 // It is safe to modify the contents of the arguments after Write returns but
 // not before. Write will not modify content of the batch.
 func (db *DB) Write(batch *leveldb.Batch, wo *opt.WriteOptions) error {
-	return db.cfg.WithSpan(
-		db.cfg.ctx,
+	return db.cfg.withSpan(
 		"Write",
 		func(context.Context) error { return db.DB.Write(batch, wo) },
-		trace.WithAttributes(semconv.DBOperationKey.String("Write")),
 	)
 }
@@ -15,16 +15,25 @@
 package splunkleveldb
 
 import (
+	"time"
+
 	"github.com/syndtr/goleveldb/leveldb/iterator"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
 	"go.opentelemetry.io/otel/trace"
+
+	"github.com/signalfx/splunk-otel-go/instrumentation/internal"
 )
 
 // iter wraps a leveldb.Iterator, tracing all operations performed.
 type iter struct {
 	iterator.Iterator
 	span trace.Span
+
+	cfg   *config
+	op    internal.Operation
+	start time.Time
 }
 
 // WrapIterator returns a traced Iterator that wraps a leveldb
@@ -32,22 +41,33 @@ type iter struct {
 // called.
 func WrapIterator(it iterator.Iterator, opts ...Option) iterator.Iterator {
 	c := newConfig(opts...)
+	op := operation("Iterator")
+	if !c.ShouldTrace(c.ctx, op) {
+		return it
+	}
+
 	sso := c.MergedSpanStartOptions(
-		trace.WithAttributes(semconv.DBOperationKey.String("Iterator")),
+		trace.WithAttributes(c.DBAttributes(semconv.DBOperationKey.String("Iterator"))...),
 	)
-	_, span := c.ResolveTracer(c.ctx).Start(c.ctx, "Iterator", sso...)
+	_, span := c.ResolveTracer(c.ctx).Start(c.ctx, c.SpanName(op), sso...)
 	return &iter{
 		Iterator: it,
 		span:     span,
+		cfg:      c,
+		op:       op,
+		start:    time.Now(),
 	}
 }
 
 // Release releases associated resources and ends any active span.
 func (it *iter) Release() {
+	var attrs []attribute.KeyValue
 	if err := it.Error(); err != nil {
 		it.span.RecordError(err)
 		it.span.SetStatus(codes.Error, err.Error())
+		attrs = append(attrs, internal.ErrorType(err))
 	}
 	it.Iterator.Release()
+	it.cfg.RecordDuration(it.cfg.ctx, it.op, time.Since(it.start), attrs...)
 	it.span.End()
 }
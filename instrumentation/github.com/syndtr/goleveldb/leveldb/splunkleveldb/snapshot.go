@@ -21,8 +21,6 @@ import (
 	"github.com/syndtr/goleveldb/leveldb/iterator"
 	"github.com/syndtr/goleveldb/leveldb/opt"
 	"github.com/syndtr/goleveldb/leveldb/util"
-	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
-	"go.opentelemetry.io/otel/trace"
 )
 
 // Snapshot wraps a leveldb.Snapshot, tracing all operations performed.
@@ -57,15 +55,13 @@ func (snap *Snapshot) WithContext(ctx context.Context) *Snapshot {
 // The caller should not modify the contents of the returned slice, but
 // it is safe to modify the contents of the argument after Get returns.
 func (snap *Snapshot) Get(key []byte, ro *opt.ReadOptions) (value []byte, err error) {
-	err = snap.cfg.WithSpan(
-		snap.cfg.ctx,
+	err = snap.cfg.withSpan(
 		"Get",
 		func(context.Context) error {
 			var e error
 			value, e = snap.Snapshot.Get(key, ro)
 			return e
 		},
-		trace.WithAttributes(semconv.DBOperationKey.String("Get")),
 	)
 	return value, err
 }
@@ -74,15 +70,13 @@ func (snap *Snapshot) Get(key []byte, ro *opt.ReadOptions) (value []byte, err er
 //
 // It is safe to modify the contents of the argument after Get returns.
 func (snap *Snapshot) Has(key []byte, ro *opt.ReadOptions) (ret bool, err error) {
-	err = snap.cfg.WithSpan(
-		snap.cfg.ctx,
+	err = snap.cfg.withSpan(
 		"Has",
 		func(context.Context) error {
 			var e error
 			ret, e = snap.Snapshot.Has(key, ro)
 			return e
 		},
-		trace.WithAttributes(semconv.DBOperationKey.String("Has")),
 	)
 	return ret, err
 }
@@ -27,10 +27,14 @@ import (
 	"github.com/syndtr/goleveldb/leveldb/opt"
 	"github.com/syndtr/goleveldb/leveldb/storage"
 	"github.com/syndtr/goleveldb/leveldb/util"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
 	"go.opentelemetry.io/otel/sdk/trace"
 	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	semconvnew "go.opentelemetry.io/otel/semconv/v1.37.0"
 	traceapi "go.opentelemetry.io/otel/trace"
 
 	//nolint:staticcheck // Deprecated package, but still used here.
@@ -292,3 +296,117 @@ func TestIteratorErrors(t *testing.T) {
 	assert.Equal(t, codes.Error, span.Status().Code)
 	assert.Equal(t, errExpected.Error(), span.Status().Description)
 }
+
+func TestSpanNameFormatter(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := trace.NewTracerProvider(trace.WithSpanProcessor(sr))
+
+	db, err := splunkleveldb.Open(
+		storage.NewMemStorage(),
+		nil,
+		splunkleveldb.WithTracerProvider(tp),
+		splunkleveldb.WithSpanNameFormatter(func(op splunkleveldb.Operation) string {
+			return "leveldb." + op.Method
+		}),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { assert.NoError(t, db.Close()) })
+
+	require.NoError(t, db.Put([]byte("hello"), expectedValue, nil))
+	db.NewIterator(nil, nil).Release()
+
+	spans := sr.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "leveldb.Put", spans[0].Name())
+	assert.Equal(t, "leveldb.Iterator", spans[1].Name())
+}
+
+func TestFilter(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := trace.NewTracerProvider(trace.WithSpanProcessor(sr))
+
+	db, err := splunkleveldb.Open(
+		storage.NewMemStorage(),
+		nil,
+		splunkleveldb.WithTracerProvider(tp),
+		splunkleveldb.WithFilter(func(op splunkleveldb.Operation) bool {
+			return op.Method != "Iterator" && op.Method != "Has"
+		}),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { assert.NoError(t, db.Close()) })
+
+	require.NoError(t, db.Put([]byte("hello"), expectedValue, nil))
+	ok, err := db.Has([]byte("hello"), nil)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	it := db.NewIterator(nil, nil)
+	assert.True(t, it.Next())
+	it.Release()
+
+	spans := sr.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "Put", spans[0].Name())
+}
+
+func TestDuration(t *testing.T) {
+	r := sdkmetric.NewManualReader()
+	db, err := splunkleveldb.Open(
+		storage.NewMemStorage(),
+		nil,
+		// Durations are recorded even if the spans are not sampled.
+		splunkleveldb.WithTracerProvider(trace.NewTracerProvider(trace.WithSampler(trace.NeverSample()))),
+		splunkleveldb.WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(r))),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { assert.NoError(t, db.Close()) })
+
+	require.NoError(t, db.Put([]byte("hello"), expectedValue, nil))
+	_, err = db.Get([]byte("missing"), nil)
+	require.ErrorIs(t, err, leveldb.ErrNotFound)
+	db.NewIterator(nil, nil).Release()
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, r.Collect(context.Background(), &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+	require.Len(t, rm.ScopeMetrics[0].Metrics, 1)
+	m := rm.ScopeMetrics[0].Metrics[0]
+	assert.Equal(t, "db.client.operation.duration", m.Name)
+	assert.Equal(t, "s", m.Unit)
+	h, ok := m.Data.(metricdata.Histogram[float64])
+	require.True(t, ok)
+
+	system := semconvnew.DBSystemNameKey.String("leveldb")
+	got := make([]attribute.Set, 0, len(h.DataPoints))
+	for _, dp := range h.DataPoints {
+		assert.Equal(t, uint64(1), dp.Count)
+		got = append(got, dp.Attributes)
+	}
+	assert.ElementsMatch(t, []attribute.Set{
+		attribute.NewSet(system, semconvnew.DBOperationName("Put")),
+		attribute.NewSet(system, semconvnew.DBOperationName("Get"), semconvnew.ErrorTypeKey.String("*errors.errorString")),
+		attribute.NewSet(system, semconvnew.DBOperationName("Iterator")),
+	}, got)
+}
+
+func TestDisabled(t *testing.T) {
+	t.Setenv("SPLUNK_INSTRUMENTATION_LEVELDB_ENABLED", "false")
+
+	sr := tracetest.NewSpanRecorder()
+	r := sdkmetric.NewManualReader()
+	db, err := splunkleveldb.Open(
+		storage.NewMemStorage(),
+		nil,
+		splunkleveldb.WithTracerProvider(trace.NewTracerProvider(trace.WithSpanProcessor(sr))),
+		splunkleveldb.WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(r))),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { assert.NoError(t, db.Close()) })
+
+	require.NoError(t, db.Put([]byte("hello"), expectedValue, nil))
+
+	assert.Empty(t, sr.Ended())
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, r.Collect(context.Background(), &rm))
+	assert.Empty(t, rm.ScopeMetrics)
+}
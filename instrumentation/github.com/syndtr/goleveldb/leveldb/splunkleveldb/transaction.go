@@ -21,8 +21,6 @@ import (
 	"github.com/syndtr/goleveldb/leveldb/iterator"
 	"github.com/syndtr/goleveldb/leveldb/opt"
 	"github.com/syndtr/goleveldb/leveldb/util"
-	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
-	"go.opentelemetry.io/otel/trace"
 )
 
 // Transaction wraps a *leveldb.Transaction, tracing all operations performed.
@@ -57,11 +55,9 @@ func (tr *Transaction) WithContext(ctx context.Context) *Transaction {
 //
 // Other methods should not be called after transaction has been committed.
 func (tr *Transaction) Commit() error {
-	return tr.cfg.WithSpan(
-		tr.cfg.ctx,
+	return tr.cfg.withSpan(
 		"Commit",
 		func(context.Context) error { return tr.Transaction.Commit() },
-		trace.WithAttributes(semconv.DBOperationKey.String("Commit")),
 	)
 }
 
@@ -72,15 +68,13 @@ func (tr *Transaction) Commit() error {
 // of the returned slice.
 // It is safe to modify the contents of the argument after Get returns.
 func (tr *Transaction) Get(key []byte, ro *opt.ReadOptions) (value []byte, err error) {
-	err = tr.cfg.WithSpan(
-		tr.cfg.ctx,
+	err = tr.cfg.withSpan(
 		"Get",
 		func(context.Context) error {
 			var e error
 			value, e = tr.Transaction.Get(key, ro)
 			return e
 		},
-		trace.WithAttributes(semconv.DBOperationKey.String("Get")),
 	)
 	return value, err
 }
@@ -89,15 +83,13 @@ func (tr *Transaction) Get(key []byte, ro *opt.ReadOptions) (value []byte, err e
 //
 // It is safe to modify the contents of the argument after Has returns.
 func (tr *Transaction) Has(key []byte, ro *opt.ReadOptions) (ret bool, err error) {
-	err = tr.cfg.WithSpan(
-		tr.cfg.ctx,
+	err = tr.cfg.withSpan(
 		"Has",
 		func(context.Context) error {
 			var e error
 			ret, e = tr.Transaction.Has(key, ro)
 			return e
 		},
-		trace.WithAttributes(semconv.DBOperationKey.String("Has")),
 	)
 	return ret, err
 }
@@ -133,11 +125,9 @@ func (tr *Transaction) NewIterator(slice *util.Range, ro *opt.ReadOptions) itera
 //
 // It is safe to modify the contents of the arguments after Delete returns.
 func (tr *Transaction) Delete(key []byte, wo *opt.WriteOptions) error {
-	return tr.cfg.WithSpan(
-		tr.cfg.ctx,
+	return tr.cfg.withSpan(
 		"Delete",
 		func(context.Context) error { return tr.Transaction.Delete(key, wo) },
-		trace.WithAttributes(semconv.DBOperationKey.String("Delete")),
 	)
 }
 
@@ -145,14 +135,12 @@ func (tr *Transaction) Delete(key []byte, wo *opt.WriteOptions) error {
 //
 // Other methods should not be called after transaction has been discarded.
 func (tr *Transaction) Discard() {
-	_ = tr.cfg.WithSpan(
-		tr.cfg.ctx,
+	_ = tr.cfg.withSpan(
 		"Discard",
 		func(context.Context) error {
 			tr.Transaction.Discard()
 			return nil
 		},
-		trace.WithAttributes(semconv.DBOperationKey.String("Discard")),
 	)
 }
 
@@ -163,11 +151,9 @@ func (tr *Transaction) Discard() {
 //
 // It is safe to modify the contents of the arguments after Put returns.
 func (tr *Transaction) Put(key, value []byte, wo *opt.WriteOptions) error {
-	return tr.cfg.WithSpan(
-		tr.cfg.ctx,
+	return tr.cfg.withSpan(
 		"Put",
 		func(context.Context) error { return tr.Transaction.Put(key, value, wo) },
-		trace.WithAttributes(semconv.DBOperationKey.String("Put")),
 	)
 }
 
@@ -178,10 +164,8 @@ func (tr *Transaction) Put(key, value []byte, wo *opt.WriteOptions) error {
 //
 // It is safe to modify the contents of the arguments after Write returns.
 func (tr *Transaction) Write(b *leveldb.Batch, wo *opt.WriteOptions) error {
-	return tr.cfg.WithSpan(
-		tr.cfg.ctx,
+	return tr.cfg.withSpan(
 		"Write",
 		func(context.Context) error { return tr.Transaction.Write(b, wo) },
-		trace.WithAttributes(semconv.DBOperationKey.String("Write")),
 	)
 }
@@ -18,7 +18,9 @@ import (
 	"context"
 
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
 	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	semconvnew "go.opentelemetry.io/otel/semconv/v1.37.0"
 	"go.opentelemetry.io/otel/trace"
 
 	"github.com/signalfx/splunk-otel-go/instrumentation/internal"
@@ -39,11 +41,14 @@ func newConfig(options ...Option) *config {
 			instrumentationName, internal.OptionFunc(
 				func(c *internal.Config) {
 					c.Version = Version()
+					c.SpanSemconv = c.DBSemconv
+					c.MetricSemconv = internal.SemconvStable
+					c.RequireParentSpan = internal.RequireParentSpanFromEnv()
 					c.DefaultStartOpts = []trace.SpanStartOption{
-						trace.WithAttributes(
+						trace.WithAttributes(c.DBAttributes(
 							semconv.DBSystemKey.String("buntdb"),
 							semconv.NetTransportInProc,
-						),
+						)...),
 						// From the specification: span kind MUST always be CLIENT.
 						trace.WithSpanKind(trace.SpanKindClient),
 					}
@@ -58,6 +63,9 @@ func newConfig(options ...Option) *config {
 			o.apply(&c)
 		}
 	}
+	// The options may have set the Tracer or Meter of a disabled
+	// instrumentation.
+	c.ApplyEnabled()
 
 	return &c
 }
@@ -70,6 +78,32 @@ func (c *config) copy() *config {
 	}
 }
 
+// withSpan wraps the function f with a span for the method.
+func (c *config) withSpan(method string, f func(context.Context) error) error {
+	return c.WithOperation(
+		c.ctx,
+		internal.Operation{
+			Name:      method,
+			Details:   Operation{Method: method},
+			Histogram: internal.DBClientOperationDuration,
+			Attributes: []attribute.KeyValue{
+				semconvnew.DBSystemNameKey.String("buntdb"),
+				semconvnew.DBOperationName(method),
+			},
+		},
+		f,
+		trace.WithAttributes(c.DBAttributes(semconv.DBOperationKey.String(method))...),
+	)
+}
+
+// Operation describes a buntdb operation traced by this instrumentation
+// library.
+type Operation struct {
+	// Method is the name of the traced transaction method (e.g. "Get",
+	// "Set", "Ascend").
+	Method string
+}
+
 // Option applies options to a configuration.
 type Option interface {
 	apply(*config)
@@ -89,12 +123,40 @@ func WithTracerProvider(tp trace.TracerProvider) Option {
 	return optionConv{iOpt: internal.WithTracerProvider(tp)}
 }
 
+// WithMeterProvider returns an Option that sets the MeterProvider used with
+// this instrumentation library.
+func WithMeterProvider(mp metric.MeterProvider) Option {
+	return optionConv{iOpt: internal.WithMeterProvider(mp)}
+}
+
 // WithAttributes returns an Option that appends attr to the attributes set
 // for every span created with this instrumentation library.
 func WithAttributes(attr []attribute.KeyValue) Option {
 	return optionConv{iOpt: internal.WithAttributes(attr)}
 }
 
+// WithSpanNameFormatter returns an Option that sets f to name the spans
+// created for operations. If f returns an empty string the default span
+// name, the method name, is used.
+func WithSpanNameFormatter(f func(Operation) string) Option {
+	return optionConv{iOpt: internal.WithSpanNameFormatter(f)}
+}
+
+// WithFilter returns an Option that adds f to the filters of the traced
+// operations. Operations f returns false for are not traced.
+func WithFilter(f func(Operation) bool) Option {
+	return optionConv{iOpt: internal.WithFilter(f)}
+}
+
+// WithRequireParentSpan returns an Option that sets if operations are only
+// traced when their context contains a valid parent span. Operations performed
+// outside of any trace, e.g. by background pollers, are then not traced. The
+// default is true if the SPLUNK_INSTRUMENTATION_REQUIRE_PARENT_SPAN
+// environment variable is set to "true".
+func WithRequireParentSpan(require bool) Option {
+	return optionConv{iOpt: internal.WithRequireParentSpan(require)}
+}
+
 type optionFunc func(*config)
 
 func (o optionFunc) apply(c *config) {
@@ -25,10 +25,14 @@ import (
 
 	"github.com/tidwall/buntdb"
 
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
 	"go.opentelemetry.io/otel/sdk/trace"
 	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 
 	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	semconvnew "go.opentelemetry.io/otel/semconv/v1.37.0"
 	traceapi "go.opentelemetry.io/otel/trace"
 
 	//nolint:staticcheck // Deprecated package, but still used here.
@@ -500,6 +504,130 @@ func TestRollback(t *testing.T) {
 	assertSpan(t, "Get", spans[2])
 }
 
+func TestSpanNameFormatter(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := trace.NewTracerProvider(trace.WithSpanProcessor(sr))
+
+	db := getDatabase(
+		t,
+		splunkbuntdb.WithTracerProvider(tp),
+		splunkbuntdb.WithSpanNameFormatter(func(op splunkbuntdb.Operation) string {
+			return "buntdb." + op.Method
+		}),
+	)
+	t.Cleanup(func() { assert.NoError(t, db.Close()) })
+
+	err := db.View(func(tx *splunkbuntdb.Tx) error {
+		_, errIn := tx.Get("regular:a")
+		return errIn
+	})
+	assert.NoError(t, err)
+
+	ctx := withTestingDeadline(context.Background(), t)
+	require.NoError(t, tp.Shutdown(ctx))
+	spans := sr.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "buntdb.Get", spans[0].Name())
+}
+
+func TestFilter(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := trace.NewTracerProvider(trace.WithSpanProcessor(sr))
+
+	db := getDatabase(
+		t,
+		splunkbuntdb.WithTracerProvider(tp),
+		splunkbuntdb.WithFilter(func(op splunkbuntdb.Operation) bool {
+			return op.Method != "Get"
+		}),
+	)
+	t.Cleanup(func() { assert.NoError(t, db.Close()) })
+
+	err := db.View(func(tx *splunkbuntdb.Tx) error {
+		_, errIn := tx.Get("regular:a")
+		if errIn != nil {
+			return errIn
+		}
+		_, errIn = tx.Len()
+		return errIn
+	})
+	assert.NoError(t, err)
+
+	ctx := withTestingDeadline(context.Background(), t)
+	require.NoError(t, tp.Shutdown(ctx))
+	spans := sr.Ended()
+	require.Len(t, spans, 1)
+	assertSpan(t, "Len", spans[0])
+}
+
+func TestDuration(t *testing.T) {
+	r := sdkmetric.NewManualReader()
+	db := getDatabase(
+		t,
+		// Durations are recorded even if the spans are not sampled.
+		splunkbuntdb.WithTracerProvider(trace.NewTracerProvider(trace.WithSampler(trace.NeverSample()))),
+		splunkbuntdb.WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(r))),
+	)
+	t.Cleanup(func() { assert.NoError(t, db.Close()) })
+
+	err := db.View(func(tx *splunkbuntdb.Tx) error {
+		_, errIn := tx.Get("regular:a")
+		if errIn != nil {
+			return errIn
+		}
+		_, errIn = tx.Get("missing")
+		return errIn
+	})
+	require.ErrorIs(t, err, buntdb.ErrNotFound)
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, r.Collect(context.Background(), &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+	require.Len(t, rm.ScopeMetrics[0].Metrics, 1)
+	m := rm.ScopeMetrics[0].Metrics[0]
+	assert.Equal(t, "db.client.operation.duration", m.Name)
+	h, ok := m.Data.(metricdata.Histogram[float64])
+	require.True(t, ok)
+
+	attrs := []attribute.KeyValue{
+		semconvnew.DBSystemNameKey.String("buntdb"),
+		semconvnew.DBOperationName("Get"),
+	}
+	got := make([]attribute.Set, 0, len(h.DataPoints))
+	for _, dp := range h.DataPoints {
+		assert.Equal(t, uint64(1), dp.Count)
+		got = append(got, dp.Attributes)
+	}
+	assert.ElementsMatch(t, []attribute.Set{
+		attribute.NewSet(attrs...),
+		attribute.NewSet(append(attrs, semconvnew.ErrorTypeKey.String("*errors.errorString"))...),
+	}, got)
+}
+
+func TestDisabled(t *testing.T) {
+	t.Setenv("SPLUNK_INSTRUMENTATION_BUNTDB_ENABLED", "false")
+
+	sr := tracetest.NewSpanRecorder()
+	r := sdkmetric.NewManualReader()
+	db := getDatabase(
+		t,
+		splunkbuntdb.WithTracerProvider(trace.NewTracerProvider(trace.WithSpanProcessor(sr))),
+		splunkbuntdb.WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(r))),
+	)
+	t.Cleanup(func() { assert.NoError(t, db.Close()) })
+
+	err := db.View(func(tx *splunkbuntdb.Tx) error {
+		_, errIn := tx.Get("regular:a")
+		return errIn
+	})
+	require.NoError(t, err)
+
+	assert.Empty(t, sr.Ended())
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, r.Collect(context.Background(), &rm))
+	assert.Empty(t, rm.ScopeMetrics)
+}
+
 func getDatabase(t *testing.T, opts ...splunkbuntdb.Option) *splunkbuntdb.DB {
 	bdb, err := buntdb.Open(":memory:")
 	require.NoError(t, err)
@@ -21,12 +21,16 @@ package splunkelastic
 
 import (
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/propagation"
 	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
 	"go.opentelemetry.io/otel/semconv/v1.17.0/httpconv"
+	semconvnew "go.opentelemetry.io/otel/semconv/v1.37.0"
 	"go.opentelemetry.io/otel/trace"
 
 	"github.com/signalfx/splunk-otel-go/instrumentation/internal"
@@ -49,13 +53,20 @@ func WrapRoundTripper(rt http.RoundTripper, opts ...Option) http.RoundTripper {
 	o := append([]internal.Option{
 		internal.OptionFunc(func(c *internal.Config) {
 			c.Version = Version()
+			c.MetricSemconv = internal.SemconvStable
+			// Both HTTP and database attributes are emitted.
+			c.SpanSemconv = internal.SemconvOld
+			if c.HTTPSemconv == internal.SemconvStable && c.DBSemconv == internal.SemconvStable {
+				c.SpanSemconv = internal.SemconvStable
+			}
+			c.RequireParentSpan = internal.RequireParentSpanFromEnv()
 		}),
 	}, localToInternal(opts)...)
 
 	cfg := internal.NewConfig(instrumentationName, o...)
 	cfg.DefaultStartOpts = append([]trace.SpanStartOption{
 		trace.WithSpanKind(trace.SpanKindClient),
-		trace.WithAttributes(semconv.DBSystemElasticsearch),
+		trace.WithAttributes(cfg.DBAttributes(semconv.DBSystemElasticsearch)...),
 	}, cfg.DefaultStartOpts...)
 
 	return &roundTripper{RoundTripper: rt, cfg: cfg}
@@ -71,29 +82,84 @@ type roundTripper struct {
 var _ http.RoundTripper = (*roundTripper)(nil)
 
 func (rt *roundTripper) RoundTrip(r *http.Request) (resp *http.Response, err error) {
+	d := describe(r)
+	op := internal.Operation{
+		Name:       d.name(),
+		Details:    d,
+		Histogram:  internal.DBClientOperationDuration,
+		Attributes: d.attributes(),
+	}
+	if !rt.cfg.ShouldTrace(r.Context(), op) {
+		return rt.RoundTripper.RoundTrip(r)
+	}
+
 	opts := rt.cfg.MergedSpanStartOptions(
-		trace.WithAttributes(httpconv.ClientRequest(r)...),
+		trace.WithAttributes(rt.cfg.HTTPAttributes(httpconv.ClientRequest(r)...)...),
+		trace.WithAttributes(rt.cfg.PeerServices.URLAttributes(r.URL)...),
 	)
 
 	tracer := rt.cfg.ResolveTracer(r.Context())
-	ctx, span := tracer.Start(r.Context(), name(r), opts...)
+	ctx, span := tracer.Start(r.Context(), rt.cfg.SpanName(op), opts...)
 
 	// Ensure anything downstream knows about the started span.
 	r = r.WithContext(ctx)
 	rt.cfg.Propagator.Inject(ctx, propagation.HeaderCarrier(r.Header))
 
+	start := time.Now()
 	resp, err = rt.RoundTripper.RoundTrip(r)
+	elapsed := time.Since(start)
 	defer span.End()
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
+		rt.cfg.RecordDuration(ctx, op, elapsed, internal.ErrorType(err))
 		return resp, err
 	}
-	span.SetAttributes(httpconv.ClientResponse(resp)...)
+	span.SetAttributes(rt.cfg.HTTPAttributes(httpconv.ClientResponse(resp)...)...)
 	span.SetStatus(httpconv.ClientStatus(resp.StatusCode))
+	attrs := []attribute.KeyValue{semconvnew.HTTPResponseStatusCode(resp.StatusCode)}
+	if resp.StatusCode >= http.StatusBadRequest {
+		attrs = append(attrs, semconvnew.ErrorTypeKey.String(strconv.Itoa(resp.StatusCode)))
+	}
+	rt.cfg.RecordDuration(ctx, op, elapsed, attrs...)
 	return resp, err
 }
 
+// describe returns the Operation r performs.
+func describe(r *http.Request) Operation {
+	op := Operation{Request: r}
+
+	path := r.URL.Path
+	if path == "" {
+		path = "/"
+	}
+
+	op.Endpoint = tokenize(path)
+	if op.Endpoint == "" {
+		// Unrecognized Elasticsearch path.
+		return op
+	}
+
+	op.Operation = operations[url{method: r.Method, path: op.Endpoint}]
+	if op.Operation != "" && strings.HasPrefix(op.Endpoint, "/{index}") {
+		p := strings.TrimPrefix(path, "/")
+		// Either: ["example-index"] or ["example-index", "*"]
+		const nParts = 2
+		op.Index = strings.SplitN(p, "/", nParts)[0]
+	}
+	return op
+}
+
+// attributes returns the low cardinality attributes the duration of o is
+// recorded with.
+func (o Operation) attributes() []attribute.KeyValue {
+	attrs := []attribute.KeyValue{semconvnew.DBSystemNameElasticsearch}
+	if o.Operation != "" {
+		attrs = append(attrs, semconvnew.DBOperationName(o.Operation))
+	}
+	return attrs
+}
+
 // name returns an appropriate span name based on the client request.
 // OpenTelemetry semantic conventions require this name to be low cardinality,
 // but since the Elasticsearch API is somewhat predictable we can usually
@@ -101,33 +167,26 @@ func (rt *roundTripper) RoundTrip(r *http.Request) (resp *http.Response, err err
 // Elasticsearch operation the returned span name will conform with
 // OpenTelemetry database semantics, otherwise HTTP semantics will be used.
 func name(r *http.Request) string {
-	path := r.URL.Path
-	if path == "" {
-		path = "/"
-	}
+	return describe(r).name()
+}
 
-	tokenized := tokenize(path)
-	if tokenized == "" {
+func (o Operation) name() string {
+	if o.Endpoint == "" {
 		// Unrecognized Elasticsearch path, default to HTTP semantics.
-		return "HTTP " + r.Method
+		return "HTTP " + o.Request.Method
 	}
 
-	op, ok := operations[url{method: r.Method, path: tokenized}]
-	if !ok {
+	if o.Operation == "" {
 		// Unrecognized Elasticsearch operation, default to HTTP semantics.
-		return "HTTP " + r.Method + " " + tokenized
+		return "HTTP " + o.Request.Method + " " + o.Endpoint
 	}
 
-	if strings.HasPrefix(tokenized, "/{index}") {
+	if o.Index != "" {
 		// Use the {index} as the OpenTelemetry semantic for DB name.
-		p := strings.TrimPrefix(path, "/")
-		// Either: ["example-index"] or ["example-index", "*"]
-		const nParts = 2
-		idx := strings.SplitN(p, "/", nParts)[0]
 		// <db.operation> <db.name>
-		return op + " " + idx
+		return o.Operation + " " + o.Index
 	}
 
 	// <db.operation>
-	return op
+	return o.Operation
 }
@@ -21,8 +21,16 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
 	"go.opentelemetry.io/otel/trace"
+
+	"github.com/signalfx/splunk-otel-go/instrumentation/internal"
 )
 
 const aliasPath = "/_alias"
@@ -105,3 +113,152 @@ func TestName(t *testing.T) {
 		assert.Equal(t, test.name, name(req))
 	}
 }
+
+func TestDescribe(t *testing.T) {
+	req, err := http.NewRequestWithContext(context.Background(), "PUT", "http://localhost:9200/example_index/_bulk", http.NoBody)
+	require.NoError(t, err)
+	assert.Equal(t, Operation{
+		Request:   req,
+		Endpoint:  "/{index}/_bulk",
+		Operation: "bulk",
+		Index:     "example_index",
+	}, describe(req))
+}
+
+func TestSpanNameFormatter(t *testing.T) {
+	rt, ok := WrapRoundTripper(nil, WithSpanNameFormatter(func(op Operation) string {
+		return op.Operation
+	})).(*roundTripper)
+	require.True(t, ok)
+
+	req, err := http.NewRequestWithContext(context.Background(), "DELETE", "http://localhost:9200/example_index", http.NoBody)
+	require.NoError(t, err)
+	op := describe(req)
+	assert.Equal(t, "indices.delete", rt.cfg.SpanName(internal.Operation{Name: op.name(), Details: op}))
+
+	req, err = http.NewRequestWithContext(context.Background(), "GET", "http://localhost:9200/junk/path", http.NoBody)
+	require.NoError(t, err)
+	op = describe(req)
+	assert.Equal(t, "HTTP GET", rt.cfg.SpanName(internal.Operation{Name: op.name(), Details: op}))
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+type countingProp struct {
+	propagation.TextMapPropagator
+
+	injected int
+}
+
+func (p *countingProp) Inject(context.Context, propagation.TextMapCarrier) {
+	p.injected++
+}
+
+func TestFilter(t *testing.T) {
+	var sent int
+	base := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		sent++
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: r}, nil
+	})
+	p := new(countingProp)
+	rt := WrapRoundTripper(base, WithPropagator(p), WithFilter(func(op Operation) bool {
+		return op.Operation != "ping"
+	}))
+
+	for _, method := range []string{"HEAD", "GET"} {
+		req, err := http.NewRequestWithContext(context.Background(), method, "http://localhost:9200/", http.NoBody)
+		require.NoError(t, err)
+		resp, err := rt.RoundTrip(req)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+	}
+	assert.Equal(t, 2, sent, "request not sent")
+	assert.Equal(t, 1, p.injected, "filtered request traced")
+}
+
+func TestDuration(t *testing.T) {
+	base := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		code := http.StatusOK
+		if r.Method == http.MethodGet {
+			code = http.StatusServiceUnavailable
+		}
+		return &http.Response{StatusCode: code, Body: http.NoBody, Request: r}, nil
+	})
+	r := sdkmetric.NewManualReader()
+	rt := WrapRoundTripper(base, WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(r))))
+
+	for _, method := range []string{"HEAD", "GET"} {
+		req, err := http.NewRequestWithContext(context.Background(), method, "http://localhost:9200/", http.NoBody)
+		require.NoError(t, err)
+		resp, err := rt.RoundTrip(req)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+	}
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, r.Collect(context.Background(), &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+	require.Len(t, rm.ScopeMetrics[0].Metrics, 1)
+	m := rm.ScopeMetrics[0].Metrics[0]
+	assert.Equal(t, "db.client.operation.duration", m.Name)
+	h, ok := m.Data.(metricdata.Histogram[float64])
+	require.True(t, ok)
+	got := make([]attribute.Set, 0, len(h.DataPoints))
+	for _, dp := range h.DataPoints {
+		got = append(got, dp.Attributes)
+	}
+	assert.ElementsMatch(t, []attribute.Set{
+		attribute.NewSet(
+			semconv.DBSystemNameElasticsearch,
+			semconv.DBOperationName("ping"),
+			semconv.HTTPResponseStatusCode(http.StatusOK),
+		),
+		attribute.NewSet(
+			semconv.DBSystemNameElasticsearch,
+			semconv.DBOperationName("info"),
+			semconv.HTTPResponseStatusCode(http.StatusServiceUnavailable),
+			semconv.ErrorTypeKey.String("503"),
+		),
+	}, got)
+}
+
+func TestPeerService(t *testing.T) {
+	t.Setenv("OTEL_INSTRUMENTATION_COMMON_PEER_SERVICE_MAPPING", "localhost=env-search")
+
+	base := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: r}, nil
+	})
+
+	tests := map[string]struct {
+		opts []Option
+		want attribute.KeyValue
+	}{
+		"env": {want: semconv.PeerService("env-search")},
+		"option": {
+			opts: []Option{WithPeerServiceMapping(map[string]string{"localhost:9200": "option-search"})},
+			want: semconv.PeerService("option-search"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			sr := tracetest.NewSpanRecorder()
+			tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+			rt := WrapRoundTripper(base, append(test.opts, WithTracerProvider(tp))...)
+
+			req, err := http.NewRequestWithContext(context.Background(), "GET", "http://localhost:9200/", http.NoBody)
+			require.NoError(t, err)
+			resp, err := rt.RoundTrip(req)
+			require.NoError(t, err)
+			require.NoError(t, resp.Body.Close())
+
+			spans := sr.Ended()
+			require.Len(t, spans, 1)
+			assert.Contains(t, spans[0].Attributes(), test.want)
+		})
+	}
+}
@@ -15,7 +15,10 @@
 package splunkelastic
 
 import (
+	"net/http"
+
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/trace"
 
@@ -41,6 +44,12 @@ func WithTracerProvider(tp trace.TracerProvider) Option {
 	return Option(internal.WithTracerProvider(tp))
 }
 
+// WithMeterProvider returns an Option that sets the MeterProvider used with
+// this instrumentation library.
+func WithMeterProvider(mp metric.MeterProvider) Option {
+	return Option(internal.WithMeterProvider(mp))
+}
+
 // WithAttributes returns an Option that appends attr to the attributes set
 // for every span created with this instrumentation library.
 func WithAttributes(attr []attribute.KeyValue) Option {
@@ -52,3 +61,52 @@ func WithAttributes(attr []attribute.KeyValue) Option {
 func WithPropagator(p propagation.TextMapPropagator) Option {
 	return Option(internal.WithPropagator(p))
 }
+
+// Operation describes an Elasticsearch request traced by this
+// instrumentation library.
+type Operation struct {
+	// Request is the HTTP request sent to the Elasticsearch server.
+	Request *http.Request
+	// Endpoint is the tokenized path of the request (e.g.
+	// "/{index}/_search"). It is empty if the path is not recognized.
+	Endpoint string
+	// Operation is the Elasticsearch operation performed (e.g.
+	// "search"). It is empty if the operation is not recognized.
+	Operation string
+	// Index is the Elasticsearch index the operation is performed on, if
+	// any.
+	Index string
+}
+
+// WithSpanNameFormatter returns an Option that sets f to name the spans
+// created for requests. If f returns an empty string the default span name,
+// based on the recognized Elasticsearch operation and index, is used.
+func WithSpanNameFormatter(f func(Operation) string) Option {
+	return Option(internal.WithSpanNameFormatter(f))
+}
+
+// WithFilter returns an Option that adds f to the filters of the traced
+// requests. Requests f returns false for, e.g. "ping" operations, are not
+// traced.
+func WithFilter(f func(Operation) bool) Option {
+	return Option(internal.WithFilter(f))
+}
+
+// WithRequireParentSpan returns an Option that sets if requests are only
+// traced when their context contains a valid parent span. Requests performed
+// outside of any trace, e.g. by background pollers, are then not traced. The
+// default is true if the SPLUNK_INSTRUMENTATION_REQUIRE_PARENT_SPAN
+// environment variable is set to "true".
+func WithRequireParentSpan(require bool) Option {
+	return Option(internal.WithRequireParentSpan(require))
+}
+
+// WithPeerServiceMapping returns an Option that maps Elasticsearch hosts to
+// the logical name of the service they are part of, set as the peer.service
+// attribute of the spans. Hosts are identified by their name or IP address,
+// optionally followed by ":<port>". The entries of m take precedence over the
+// ones set with the OTEL_INSTRUMENTATION_COMMON_PEER_SERVICE_MAPPING
+// environment variable.
+func WithPeerServiceMapping(m map[string]string) Option {
+	return Option(internal.WithPeerServiceMapping(m))
+}
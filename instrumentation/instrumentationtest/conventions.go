@@ -0,0 +1,199 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instrumentationtest
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Convention describes the attributes a semantic convention requires on the
+// spans it applies to.
+type Convention struct {
+	// Name describes the convention in assertion failures.
+	Name string
+	// Kind is the kind of the spans the convention applies to.
+	Kind trace.SpanKind
+	// Identifying are the attribute keys identifying the spans of the
+	// convention, e.g. db.system. The convention applies to the spans of Kind
+	// with any of these attributes.
+	Identifying []attribute.Key
+	// Scopes are the names of the instrumentation scopes producing the spans
+	// of the convention. The convention also applies to the spans of Kind
+	// from these scopes, which then fail it when they have none of the
+	// Identifying attributes.
+	Scopes []string
+	// Required are the attributes required by the convention. Each
+	// requirement lists alternative keys, e.g. of the v1.17.0 and stable
+	// semantic conventions, at least one of which has to be set.
+	Required [][]attribute.Key
+}
+
+// Applies returns true if c applies to span.
+func (c Convention) Applies(span sdktrace.ReadOnlySpan) bool {
+	if span.SpanKind() != c.Kind {
+		return false
+	}
+	return hasAny(span.Attributes(), c.Identifying) || slices.Contains(c.Scopes, span.InstrumentationScope().Name)
+}
+
+// Missing returns the requirements of c span does not satisfy. The
+// Identifying attributes are required first.
+func (c Convention) Missing(span sdktrace.ReadOnlySpan) [][]attribute.Key {
+	var missing [][]attribute.Key
+	attrs := span.Attributes()
+	if len(c.Identifying) > 0 && !hasAny(attrs, c.Identifying) {
+		missing = append(missing, c.Identifying)
+	}
+	for _, req := range c.Required {
+		if !hasAny(attrs, req) {
+			missing = append(missing, req)
+		}
+	}
+	return missing
+}
+
+func hasAny(attrs []attribute.KeyValue, keys []attribute.Key) bool {
+	for _, kv := range attrs {
+		for _, k := range keys {
+			if kv.Key == k {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// scopePrefix is the prefix of the instrumentation scope names of this
+// project.
+const scopePrefix = "github.com/signalfx/splunk-otel-go/instrumentation/"
+
+// DefaultConventions returns the conventions of the database, HTTP, and
+// messaging spans produced by the instrumentations of this project. Both the
+// v1.17.0 and stable semantic conventions attributes are accepted.
+//
+// Besides the attributes identifying the system, the conventions require the
+// attributes identifying the database, host, and operation of a span, e.g.
+// db.name, net.peer.name, and db.statement for database client spans. The
+// client, server, producer, and consumer spans of the instrumentations of
+// this project have to be identified by the attributes of their convention.
+// The database/sql spans of calls without a statement, e.g. Ping or Commit,
+// do not satisfy the database client convention, custom conventions need to
+// be passed to assert them.
+func DefaultConventions() []Convention {
+	dbScopes := []string{
+		scopePrefix + "database/sql/splunksql",
+		scopePrefix + "github.com/gomodule/redigo/splunkredigo",
+		scopePrefix + "gopkg.in/olivere/elastic/splunkelastic",
+	}
+	msgScopes := []string{
+		scopePrefix + "github.com/confluentinc/confluent-kafka-go/kafka/splunkkafka",
+		scopePrefix + "github.com/confluentinc/confluent-kafka-go/v2/kafka/splunkkafka",
+	}
+	httpMethod := []attribute.Key{"http.method", "http.request.method"}
+	msgOperation := []attribute.Key{"messaging.operation", "messaging.operation.type"}
+	return []Convention{
+		{
+			Name:        "database client",
+			Kind:        trace.SpanKindClient,
+			Identifying: []attribute.Key{"db.system", "db.system.name"},
+			Scopes:      dbScopes,
+			Required: [][]attribute.Key{
+				{"db.name", "db.namespace", "db.redis.database_index"},
+				{"net.peer.name", "net.sock.peer.addr", "server.address", "network.peer.address"},
+				{"db.operation", "db.statement", "db.operation.name", "db.query.text"},
+			},
+		},
+		{
+			Name:        "HTTP client",
+			Kind:        trace.SpanKindClient,
+			Identifying: httpMethod,
+			Scopes:      []string{scopePrefix + "k8s.io/client-go/splunkclient-go"},
+			Required: [][]attribute.Key{
+				{"http.url", "url.full"},
+				{"net.peer.name", "server.address"},
+			},
+		},
+		{
+			Name:        "HTTP server",
+			Kind:        trace.SpanKindServer,
+			Identifying: httpMethod,
+			Scopes:      []string{scopePrefix + "github.com/go-chi/chi/splunkchi"},
+			Required: [][]attribute.Key{
+				{"http.scheme", "url.scheme"},
+				{"net.host.name", "server.address"},
+			},
+		},
+		{
+			Name:        "messaging producer",
+			Kind:        trace.SpanKindProducer,
+			Identifying: []attribute.Key{"messaging.system"},
+			Scopes:      msgScopes,
+			Required: [][]attribute.Key{
+				{"messaging.destination.name"},
+				msgOperation,
+			},
+		},
+		{
+			Name:        "messaging consumer",
+			Kind:        trace.SpanKindConsumer,
+			Identifying: []attribute.Key{"messaging.system"},
+			Scopes:      msgScopes,
+			Required: [][]attribute.Key{
+				{"messaging.source.name", "messaging.destination.name"},
+				msgOperation,
+			},
+		},
+	}
+}
+
+// AssertConventions asserts that spans satisfy all the conventions that
+// apply to them. DefaultConventions are used if no conventions are passed.
+// It returns true if all spans satisfy their conventions.
+func AssertConventions(t testing.TB, spans []sdktrace.ReadOnlySpan, conventions ...Convention) bool {
+	t.Helper()
+
+	if len(conventions) == 0 {
+		conventions = DefaultConventions()
+	}
+
+	ok := true
+	for _, span := range spans {
+		for _, c := range conventions {
+			if !c.Applies(span) {
+				continue
+			}
+			for _, req := range c.Missing(span) {
+				ok = false
+				t.Errorf("span %q: %s convention requires attribute %s", span.Name(), c.Name, keyList(req))
+			}
+		}
+	}
+	return ok
+}
+
+// keyList returns keys formatted as a list of alternatives.
+func keyList(keys []attribute.Key) string {
+	s := make([]string, len(keys))
+	for i, k := range keys {
+		s[i] = string(k)
+	}
+	return strings.Join(s, " or ")
+}
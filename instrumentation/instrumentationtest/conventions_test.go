@@ -0,0 +1,138 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instrumentationtest
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func startSpan(tracer trace.Tracer, name string, kind trace.SpanKind, attrs ...attribute.KeyValue) {
+	_, span := tracer.Start(context.Background(), name, trace.WithSpanKind(kind), trace.WithAttributes(attrs...))
+	span.End()
+}
+
+func TestAssertConventions(t *testing.T) {
+	h := New(t)
+	tracer := h.TracerProvider.Tracer("test")
+
+	startSpan(tracer, "db", trace.SpanKindClient,
+		attribute.String("db.system", "mysql"),
+		attribute.String("db.name", "test"),
+		attribute.String("net.peer.name", "localhost"),
+		attribute.String("db.statement", "SELECT 1"),
+	)
+	startSpan(tracer, "db stable", trace.SpanKindClient,
+		attribute.String("db.system.name", "redis"),
+		attribute.Int("db.redis.database_index", 0),
+		attribute.String("server.address", "localhost"),
+		attribute.String("db.operation.name", "GET"),
+	)
+	startSpan(tracer, "http client", trace.SpanKindClient,
+		attribute.String("http.request.method", "GET"),
+		attribute.String("url.full", "http://localhost"),
+		attribute.String("server.address", "localhost"),
+	)
+	startSpan(tracer, "http server", trace.SpanKindServer,
+		attribute.String("http.method", "GET"),
+		attribute.String("http.scheme", "http"),
+		attribute.String("net.host.name", "localhost"),
+	)
+	startSpan(tracer, "produce", trace.SpanKindProducer,
+		attribute.String("messaging.system", "kafka"),
+		attribute.String("messaging.destination.name", "topic"),
+		attribute.String("messaging.operation", "publish"),
+	)
+	startSpan(tracer, "receive", trace.SpanKindConsumer,
+		attribute.String("messaging.system", "kafka"),
+		attribute.String("messaging.source.name", "topic"),
+		attribute.String("messaging.operation", "receive"),
+	)
+	startSpan(tracer, "internal", trace.SpanKindInternal, attribute.String("db.system", "leveldb"))
+	if !AssertConventions(t, h.Spans()) {
+		t.Error("AssertConventions() = false, want true")
+	}
+}
+
+func TestAssertConventionsMissing(t *testing.T) {
+	h := New(t)
+	tracer := h.TracerProvider.Tracer("test")
+	startSpan(tracer, "GET", trace.SpanKindClient, attribute.String("http.method", "GET"))
+	startSpan(tracer, "SELECT", trace.SpanKindClient,
+		attribute.String("db.system", "mysql"),
+		attribute.String("db.name", "test"),
+	)
+
+	tb := &recordingTB{TB: t}
+	if AssertConventions(tb, h.Spans()) {
+		t.Error("AssertConventions() = true, want false")
+	}
+	want := []string{
+		`span "GET": HTTP client convention requires attribute http.url or url.full`,
+		`span "GET": HTTP client convention requires attribute net.peer.name or server.address`,
+		`span "SELECT": database client convention requires attribute net.peer.name or net.sock.peer.addr or server.address or network.peer.address`,
+		`span "SELECT": database client convention requires attribute db.operation or db.statement or db.operation.name or db.query.text`,
+	}
+	if !slices.Equal(tb.errs, want) {
+		t.Errorf("errors = %q, want %q", tb.errs, want)
+	}
+}
+
+func TestAssertConventionsUnidentified(t *testing.T) {
+	h := New(t)
+	// A client span of the database/sql instrumentation without db.system.
+	tracer := h.TracerProvider.Tracer(scopePrefix + "database/sql/splunksql")
+	startSpan(tracer, "test", trace.SpanKindClient,
+		attribute.String("db.name", "test"),
+		attribute.String("net.peer.name", "localhost"),
+		attribute.String("db.statement", "SELECT 1"),
+	)
+	// Internal spans are not covered by the conventions.
+	startSpan(tracer, "internal", trace.SpanKindInternal)
+
+	tb := &recordingTB{TB: t}
+	if AssertConventions(tb, h.Spans()) {
+		t.Error("AssertConventions() = true, want false")
+	}
+	want := []string{
+		`span "test": database client convention requires attribute db.system or db.system.name`,
+	}
+	if !slices.Equal(tb.errs, want) {
+		t.Errorf("errors = %q, want %q", tb.errs, want)
+	}
+}
+
+func TestAssertConventionsCustom(t *testing.T) {
+	h := New(t)
+	startSpan(h.TracerProvider.Tracer("test"), "QUERY", trace.SpanKindClient, attribute.String("rpc.system", "dns"))
+
+	dns := Convention{
+		Name:        "DNS client",
+		Kind:        trace.SpanKindClient,
+		Identifying: []attribute.Key{"rpc.system"},
+		Required:    [][]attribute.Key{{"dns.question.name"}},
+	}
+	tb := &recordingTB{TB: t}
+	if AssertConventions(tb, h.Spans(), dns) {
+		t.Error("AssertConventions() = true, want false")
+	}
+	if len(tb.errs) != 1 {
+		t.Errorf("got %d errors, want 1: %q", len(tb.errs), tb.errs)
+	}
+}
@@ -0,0 +1,34 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package instrumentationtest provides a harness to test OpenTelemetry
+// instrumentation libraries.
+//
+// A [Harness] is an in-memory trace, metric, and log pipeline the
+// instrumentation under test is configured with. The telemetry it records
+// can then be validated with the provided assertions:
+//
+//   - [AssertConventions] checks spans have the attributes required by the
+//     semantic conventions for their kind and system, and that the spans of
+//     the instrumentations of this project identify their system.
+//   - [AssertGolden] compares spans to a golden file snapshot in which the
+//     trace and span IDs are normalized and the timestamps are omitted.
+//   - [Harness.InjectRemoteParent], [Harness.AssertPropagated], and
+//     [AssertParent] check the trace context is extracted from incoming, and
+//     injected into outgoing, requests and messages.
+//
+// Assertion failures are reported with the Errorf method of the passed
+// [testing.TB]. The instrumentations of this project are tested with it, and
+// so can be any other instrumentation.
+package instrumentationtest
@@ -0,0 +1,250 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instrumentationtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// UpdateGoldenEnv is the environment variable that, when set to "true", makes
+// AssertGolden write the golden files instead of comparing spans with them.
+const UpdateGoldenEnv = "INSTRUMENTATIONTEST_UPDATE_GOLDEN"
+
+// ignoredValue replaces the values of the ignored attributes in snapshots.
+const ignoredValue = "<ignored>"
+
+// SpanSnapshot is the deterministic representation of a span stored in
+// golden files. The trace and span IDs are replaced by their order of
+// appearance, e.g. "span-1", and timestamps are omitted.
+type SpanSnapshot struct {
+	Name              string          `json:"name"`
+	Kind              string          `json:"kind"`
+	Scope             string          `json:"scope"`
+	TraceID           string          `json:"trace_id"`
+	SpanID            string          `json:"span_id"`
+	ParentSpanID      string          `json:"parent_span_id,omitempty"`
+	StatusCode        string          `json:"status_code"`
+	StatusDescription string          `json:"status_description,omitempty"`
+	Attributes        map[string]any  `json:"attributes,omitempty"`
+	Events            []EventSnapshot `json:"events,omitempty"`
+	Links             []LinkSnapshot  `json:"links,omitempty"`
+}
+
+// EventSnapshot is the deterministic representation of a span event.
+type EventSnapshot struct {
+	Name       string         `json:"name"`
+	Attributes map[string]any `json:"attributes,omitempty"`
+}
+
+// LinkSnapshot is the deterministic representation of a span link.
+type LinkSnapshot struct {
+	TraceID    string         `json:"trace_id"`
+	SpanID     string         `json:"span_id"`
+	Attributes map[string]any `json:"attributes,omitempty"`
+}
+
+// SnapshotOption configures how spans are snapshot.
+type SnapshotOption interface {
+	apply(*snapshotConfig)
+}
+
+type snapshotConfig struct {
+	ignored map[attribute.Key]bool
+}
+
+type snapshotOptionFunc func(*snapshotConfig)
+
+func (fn snapshotOptionFunc) apply(c *snapshotConfig) {
+	fn(c)
+}
+
+// WithIgnoredAttributes returns a SnapshotOption that replaces the values of
+// the attributes with keys by "<ignored>", e.g. for the ports of test
+// servers. Only the presence of these attributes is then compared. The value
+// of exception.stacktrace is always ignored.
+func WithIgnoredAttributes(keys ...attribute.Key) SnapshotOption {
+	return snapshotOptionFunc(func(c *snapshotConfig) {
+		for _, k := range keys {
+			c.ignored[k] = true
+		}
+	})
+}
+
+// Snapshot returns the snapshots of spans, in the same order.
+func Snapshot(spans []sdktrace.ReadOnlySpan, opts ...SnapshotOption) []SpanSnapshot {
+	c := snapshotConfig{ignored: map[attribute.Key]bool{"exception.stacktrace": true}}
+	for _, o := range opts {
+		o.apply(&c)
+	}
+
+	n := newNormalizer()
+	snapshots := make([]SpanSnapshot, 0, len(spans))
+	for _, s := range spans {
+		snapshot := SpanSnapshot{
+			Name:              s.Name(),
+			Kind:              s.SpanKind().String(),
+			Scope:             s.InstrumentationScope().Name,
+			TraceID:           n.traceID(s.SpanContext().TraceID()),
+			SpanID:            n.spanID(s.SpanContext().SpanID()),
+			StatusCode:        s.Status().Code.String(),
+			StatusDescription: s.Status().Description,
+			Attributes:        c.attributes(s.Attributes()),
+		}
+		if s.Parent().IsValid() {
+			snapshot.ParentSpanID = n.spanID(s.Parent().SpanID())
+		}
+		for _, e := range s.Events() {
+			snapshot.Events = append(snapshot.Events, EventSnapshot{
+				Name:       e.Name,
+				Attributes: c.attributes(e.Attributes),
+			})
+		}
+		for _, l := range s.Links() {
+			snapshot.Links = append(snapshot.Links, LinkSnapshot{
+				TraceID:    n.traceID(l.SpanContext.TraceID()),
+				SpanID:     n.spanID(l.SpanContext.SpanID()),
+				Attributes: c.attributes(l.Attributes),
+			})
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots
+}
+
+func (c snapshotConfig) attributes(attrs []attribute.KeyValue) map[string]any {
+	if len(attrs) == 0 {
+		return nil
+	}
+	m := make(map[string]any, len(attrs))
+	for _, kv := range attrs {
+		if c.ignored[kv.Key] {
+			m[string(kv.Key)] = ignoredValue
+			continue
+		}
+		m[string(kv.Key)] = kv.Value.AsInterface()
+	}
+	return m
+}
+
+// normalizer replaces trace and span IDs by their order of appearance.
+type normalizer struct {
+	traces map[trace.TraceID]string
+	spans  map[trace.SpanID]string
+}
+
+func newNormalizer() *normalizer {
+	return &normalizer{
+		traces: make(map[trace.TraceID]string),
+		spans:  make(map[trace.SpanID]string),
+	}
+}
+
+func (n *normalizer) traceID(id trace.TraceID) string {
+	if s, ok := n.traces[id]; ok {
+		return s
+	}
+	s := "trace-" + strconv.Itoa(len(n.traces)+1)
+	n.traces[id] = s
+	return s
+}
+
+func (n *normalizer) spanID(id trace.SpanID) string {
+	if s, ok := n.spans[id]; ok {
+		return s
+	}
+	s := "span-" + strconv.Itoa(len(n.spans)+1)
+	n.spans[id] = s
+	return s
+}
+
+// AssertGolden asserts that the snapshot of spans, see Snapshot, matches the
+// golden file at path. The golden file is written instead if the
+// UpdateGoldenEnv environment variable is set to "true". It returns true if
+// the snapshot matches.
+func AssertGolden(t testing.TB, path string, spans []sdktrace.ReadOnlySpan, opts ...SnapshotOption) bool {
+	t.Helper()
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(Snapshot(spans, opts...)); err != nil {
+		t.Errorf("failed to marshal span snapshot: %v", err)
+		return false
+	}
+	got := buf.Bytes()
+
+	if strings.EqualFold(os.Getenv(UpdateGoldenEnv), "true") {
+		if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+			t.Errorf("failed to create golden file directory: %v", err)
+			return false
+		}
+		if err := os.WriteFile(path, got, 0o600); err != nil {
+			t.Errorf("failed to write golden file: %v", err)
+			return false
+		}
+		return true
+	}
+
+	want, err := os.ReadFile(path) //nolint:gosec // Golden files are read from the paths set by tests.
+	if err != nil {
+		t.Errorf("failed to read golden file, set %s=true to create it: %v", UpdateGoldenEnv, err)
+		return false
+	}
+	if bytes.Equal(want, got) {
+		return true
+	}
+	t.Errorf("span snapshot differs from golden file %s, set %s=true to update it:\n%s", path, UpdateGoldenEnv, lineDiff(string(want), string(got)))
+	return false
+}
+
+// lineDiff returns the lines differing between want and got, i.e. the lines
+// after their common prefix and before their common suffix, prefixed by "-"
+// and "+" respectively.
+func lineDiff(want, got string) string {
+	w := strings.Split(want, "\n")
+	g := strings.Split(got, "\n")
+
+	start := 0
+	for start < len(w) && start < len(g) && w[start] == g[start] {
+		start++
+	}
+	wEnd, gEnd := len(w), len(g)
+	for wEnd > start && gEnd > start && w[wEnd-1] == g[gEnd-1] {
+		wEnd--
+		gEnd--
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "@@ line %d @@\n", start+1)
+	for _, l := range w[start:wEnd] {
+		b.WriteString("-" + l + "\n")
+	}
+	for _, l := range g[start:gEnd] {
+		b.WriteString("+" + l + "\n")
+	}
+	return b.String()
+}
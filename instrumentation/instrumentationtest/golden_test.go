@@ -0,0 +1,132 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instrumentationtest
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func goldenSpans(t *testing.T) []sdktrace.ReadOnlySpan {
+	h := New(t)
+	tracer := h.TracerProvider.Tracer("test")
+
+	ctx, parent := tracer.Start(context.Background(), "parent", trace.WithSpanKind(trace.SpanKindServer))
+	_, child := tracer.Start(ctx, "child",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", "redis"),
+			attribute.Int("net.peer.port", 49152),
+		),
+		trace.WithLinks(trace.Link{SpanContext: RemoteParent()}),
+	)
+	child.RecordError(errors.New("boom"), trace.WithStackTrace(true))
+	child.SetStatus(codes.Error, "boom")
+	child.End()
+	parent.End()
+
+	return h.Spans()
+}
+
+func TestAssertGolden(t *testing.T) {
+	spans := goldenSpans(t)
+	opt := WithIgnoredAttributes("net.peer.port")
+	if !AssertGolden(t, filepath.Join("testdata", "spans.json"), spans, opt) {
+		t.Error("AssertGolden() = false, want true")
+	}
+
+	// Spans of another run only differ by their IDs and timestamps.
+	if !AssertGolden(t, filepath.Join("testdata", "spans.json"), goldenSpans(t), opt) {
+		t.Error("AssertGolden() of another run = false, want true")
+	}
+
+	// Do not overwrite the golden files when they are updated.
+	t.Setenv(UpdateGoldenEnv, "false")
+	tb := &recordingTB{TB: t}
+	if AssertGolden(tb, filepath.Join("testdata", "spans.json"), spans) {
+		t.Error("AssertGolden() with a different snapshot = true, want false")
+	}
+	if AssertGolden(tb, filepath.Join("testdata", "missing.json"), spans) {
+		t.Error("AssertGolden() with a missing golden file = true, want false")
+	}
+	if len(tb.errs) != 2 {
+		t.Fatalf("got %d errors, want 2: %q", len(tb.errs), tb.errs)
+	}
+	if want := `+      "net.peer.port": 49152`; !strings.Contains(tb.errs[0], want) {
+		t.Errorf("error %q does not contain the diff line %q", tb.errs[0], want)
+	}
+}
+
+func TestAssertGoldenUpdate(t *testing.T) {
+	t.Setenv(UpdateGoldenEnv, "true")
+
+	path := filepath.Join(t.TempDir(), "golden", "spans.json")
+	spans := goldenSpans(t)
+	if !AssertGolden(t, path, spans) {
+		t.Error("AssertGolden() = false, want true")
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv(UpdateGoldenEnv, "")
+	if !AssertGolden(t, path, spans) {
+		t.Error("AssertGolden() of the updated golden file = false, want true")
+	}
+}
+
+func TestLineDiff(t *testing.T) {
+	got := lineDiff("a\nb\nc\n", "a\nx\ny\nc\n")
+	want := "@@ line 2 @@\n-b\n+x\n+y\n"
+	if got != want {
+		t.Errorf("lineDiff() = %q, want %q", got, want)
+	}
+}
+
+func TestSnapshotNormalizesIDs(t *testing.T) {
+	snapshots := Snapshot(goldenSpans(t))
+	if len(snapshots) != 2 {
+		t.Fatalf("got %d snapshots, want 2", len(snapshots))
+	}
+
+	child, parent := snapshots[0], snapshots[1]
+	for _, c := range []struct{ name, got, want string }{
+		{"child trace ID", child.TraceID, "trace-1"},
+		{"child span ID", child.SpanID, "span-1"},
+		{"child parent span ID", child.ParentSpanID, "span-2"},
+		{"parent trace ID", parent.TraceID, "trace-1"},
+		{"parent span ID", parent.SpanID, "span-2"},
+		{"parent parent span ID", parent.ParentSpanID, ""},
+	} {
+		if c.got != c.want {
+			t.Errorf("%s = %q, want %q", c.name, c.got, c.want)
+		}
+	}
+	if len(child.Links) != 1 {
+		t.Fatalf("got %d child links, want 1", len(child.Links))
+	}
+	if l := child.Links[0]; l.TraceID != "trace-2" || l.SpanID != "span-3" {
+		t.Errorf("child link = %s/%s, want trace-2/span-3", l.TraceID, l.SpanID)
+	}
+}
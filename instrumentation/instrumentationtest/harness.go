@@ -0,0 +1,144 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instrumentationtest
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"go.opentelemetry.io/otel/propagation"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// Harness is an in-memory telemetry pipeline. All the spans, metrics, and log
+// records produced with its providers are recorded.
+type Harness struct {
+	TracerProvider *sdktrace.TracerProvider
+	MeterProvider  *sdkmetric.MeterProvider
+	LoggerProvider *sdklog.LoggerProvider
+	// Propagator propagates the W3C trace context and baggage.
+	Propagator propagation.TextMapPropagator
+
+	t      testing.TB
+	spans  *tracetest.SpanRecorder
+	reader *sdkmetric.ManualReader
+	logs   *logExporter
+}
+
+// New returns a new Harness for the test t. Its providers are shut down when
+// t and all its subtests complete.
+func New(t testing.TB) *Harness {
+	t.Helper()
+
+	h := &Harness{
+		Propagator: propagation.NewCompositeTextMapPropagator(
+			propagation.TraceContext{},
+			propagation.Baggage{},
+		),
+		t:      t,
+		spans:  tracetest.NewSpanRecorder(),
+		reader: sdkmetric.NewManualReader(),
+		logs:   &logExporter{},
+	}
+	h.TracerProvider = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(h.spans))
+	h.MeterProvider = sdkmetric.NewMeterProvider(sdkmetric.WithReader(h.reader))
+	h.LoggerProvider = sdklog.NewLoggerProvider(
+		sdklog.WithProcessor(sdklog.NewSimpleProcessor(h.logs)),
+	)
+
+	t.Cleanup(func() {
+		ctx := context.Background()
+		err := errors.Join(
+			h.TracerProvider.Shutdown(ctx),
+			h.MeterProvider.Shutdown(ctx),
+			h.LoggerProvider.Shutdown(ctx),
+		)
+		if err != nil {
+			t.Errorf("failed to shut down the telemetry pipeline: %v", err)
+		}
+	})
+
+	return h
+}
+
+// Spans returns the ended spans in the order they were ended.
+func (h *Harness) Spans() []sdktrace.ReadOnlySpan {
+	return h.spans.Ended()
+}
+
+// Metrics returns the metrics recorded.
+func (h *Harness) Metrics() metricdata.ResourceMetrics {
+	h.t.Helper()
+
+	var rm metricdata.ResourceMetrics
+	if err := h.reader.Collect(context.Background(), &rm); err != nil {
+		h.t.Errorf("failed to collect metrics: %v", err)
+	}
+	return rm
+}
+
+// Metric returns the recorded metric named name. False is returned if no
+// such metric was recorded.
+func (h *Harness) Metric(name string) (metricdata.Metrics, bool) {
+	h.t.Helper()
+
+	for _, sm := range h.Metrics().ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name == name {
+				return m, true
+			}
+		}
+	}
+	return metricdata.Metrics{}, false
+}
+
+// LogRecords returns the emitted log records in the order they were emitted.
+func (h *Harness) LogRecords() []sdklog.Record {
+	return h.logs.Records()
+}
+
+// logExporter is an sdklog.Exporter recording the exported log records.
+type logExporter struct {
+	mu      sync.Mutex
+	records []sdklog.Record
+}
+
+var _ sdklog.Exporter = (*logExporter)(nil)
+
+func (e *logExporter) Export(_ context.Context, records []sdklog.Record) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, r := range records {
+		e.records = append(e.records, r.Clone())
+	}
+	return nil
+}
+
+func (*logExporter) Shutdown(context.Context) error { return nil }
+
+func (*logExporter) ForceFlush(context.Context) error { return nil }
+
+// Records returns a copy of the recorded log records.
+func (e *logExporter) Records() []sdklog.Record {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]sdklog.Record(nil), e.records...)
+}
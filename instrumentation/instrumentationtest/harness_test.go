@@ -0,0 +1,80 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instrumentationtest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/log"
+)
+
+// recordingTB is a testing.TB recording the reported errors instead of
+// failing the test.
+type recordingTB struct {
+	testing.TB
+
+	errs []string
+}
+
+func (*recordingTB) Helper() {}
+
+func (tb *recordingTB) Errorf(format string, args ...any) {
+	tb.errs = append(tb.errs, fmt.Sprintf(format, args...))
+}
+
+func TestHarness(t *testing.T) {
+	h := New(t)
+	ctx := context.Background()
+
+	_, span := h.TracerProvider.Tracer("test").Start(ctx, "span")
+	span.End()
+	spans := h.Spans()
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(spans))
+	}
+	if got := spans[0].Name(); got != "span" {
+		t.Errorf("span name = %q, want %q", got, "span")
+	}
+
+	c, err := h.MeterProvider.Meter("test").Int64Counter("test.counter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Add(ctx, 1)
+	m, ok := h.Metric("test.counter")
+	if !ok {
+		t.Fatal("test.counter metric not recorded")
+	}
+	if m.Name != "test.counter" {
+		t.Errorf("metric name = %q, want %q", m.Name, "test.counter")
+	}
+	if _, ok = h.Metric("missing"); ok {
+		t.Error("missing metric recorded")
+	}
+
+	var r log.Record
+	r.SetBody(attribute.StringValue("message"))
+	h.LoggerProvider.Logger("test").Emit(ctx, r)
+	records := h.LogRecords()
+	if len(records) != 1 {
+		t.Fatalf("got %d log records, want 1", len(records))
+	}
+	if got := records[0].Body().AsString(); got != "message" {
+		t.Errorf("log record body = %q, want %q", got, "message")
+	}
+}
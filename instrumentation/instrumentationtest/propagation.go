@@ -0,0 +1,85 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instrumentationtest
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RemoteParent returns the sampled span context of a remote parent span, e.g.
+// of the client of a request served by the instrumentation under test.
+func RemoteParent() trace.SpanContext {
+	return trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f, 0x10},
+		SpanID:     trace.SpanID{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08},
+		TraceFlags: trace.FlagsSampled,
+		Remote:     true,
+	})
+}
+
+// InjectRemoteParent injects RemoteParent into carrier, e.g. the headers of a
+// request served by the instrumentation under test, with the Propagator of h.
+// The injected span context is returned so it can be asserted to be the
+// parent of the span created for the request, see AssertParent.
+func (h *Harness) InjectRemoteParent(carrier propagation.TextMapCarrier) trace.SpanContext {
+	sc := RemoteParent()
+	h.Propagator.Inject(trace.ContextWithRemoteSpanContext(context.Background(), sc), carrier)
+	return sc
+}
+
+// Extract returns the span context extracted from carrier with the
+// Propagator of h.
+func (h *Harness) Extract(carrier propagation.TextMapCarrier) trace.SpanContext {
+	return trace.SpanContextFromContext(h.Propagator.Extract(context.Background(), carrier))
+}
+
+// AssertPropagated asserts that the span context of span has been injected
+// into carrier, e.g. the headers of a request sent by the instrumentation
+// under test. It returns true if it has.
+func (h *Harness) AssertPropagated(t testing.TB, carrier propagation.TextMapCarrier, span sdktrace.ReadOnlySpan) bool {
+	t.Helper()
+
+	got := h.Extract(carrier)
+	want := span.SpanContext()
+	if !got.IsValid() {
+		t.Errorf("span %q: no span context propagated", span.Name())
+		return false
+	}
+	if got.TraceID() != want.TraceID() || got.SpanID() != want.SpanID() {
+		t.Errorf("span %q: propagated span context %s-%s, want %s-%s",
+			span.Name(), got.TraceID(), got.SpanID(), want.TraceID(), want.SpanID())
+		return false
+	}
+	return true
+}
+
+// AssertParent asserts that parent is the parent of span. It returns true if
+// it is.
+func AssertParent(t testing.TB, span sdktrace.ReadOnlySpan, parent trace.SpanContext) bool {
+	t.Helper()
+
+	got := span.Parent()
+	if got.TraceID() != parent.TraceID() || got.SpanID() != parent.SpanID() {
+		t.Errorf("span %q: parent %s-%s, want %s-%s",
+			span.Name(), got.TraceID(), got.SpanID(), parent.TraceID(), parent.SpanID())
+		return false
+	}
+	return true
+}
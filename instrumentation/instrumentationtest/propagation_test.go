@@ -0,0 +1,72 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instrumentationtest
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestPropagation(t *testing.T) {
+	h := New(t)
+	tracer := h.TracerProvider.Tracer("test")
+
+	// Served request.
+	incoming := propagation.MapCarrier{}
+	parent := h.InjectRemoteParent(incoming)
+	if got := h.Extract(incoming); !got.Equal(parent) {
+		t.Errorf("Extract() = %v, want %v", got, parent)
+	}
+	ctx := h.Propagator.Extract(context.Background(), incoming)
+	ctx, server := tracer.Start(ctx, "server", trace.WithSpanKind(trace.SpanKindServer))
+
+	// Sent request.
+	outgoing := propagation.MapCarrier{}
+	ctx, client := tracer.Start(ctx, "client", trace.WithSpanKind(trace.SpanKindClient))
+	h.Propagator.Inject(ctx, outgoing)
+	client.End()
+	server.End()
+
+	spans := h.Spans()
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, want 2", len(spans))
+	}
+	if !AssertParent(t, spans[1], parent) {
+		t.Error("AssertParent() of the server span = false, want true")
+	}
+	if !AssertParent(t, spans[0], spans[1].SpanContext()) {
+		t.Error("AssertParent() of the client span = false, want true")
+	}
+	if !h.AssertPropagated(t, outgoing, spans[0]) {
+		t.Error("AssertPropagated() = false, want true")
+	}
+
+	tb := &recordingTB{TB: t}
+	if AssertParent(tb, spans[0], parent) {
+		t.Error("AssertParent() with another parent = true, want false")
+	}
+	if h.AssertPropagated(tb, outgoing, spans[1]) {
+		t.Error("AssertPropagated() of another span = true, want false")
+	}
+	if h.AssertPropagated(tb, propagation.MapCarrier{}, spans[1]) {
+		t.Error("AssertPropagated() of an empty carrier = true, want false")
+	}
+	if len(tb.errs) != 3 {
+		t.Errorf("got %d errors, want 3: %q", len(tb.errs), tb.errs)
+	}
+}
@@ -16,13 +16,20 @@ package internal
 
 import (
 	"context"
+	"maps"
+	"slices"
+	"time"
 
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/metric"
+	metricnoop "go.opentelemetry.io/otel/metric/noop"
 	"go.opentelemetry.io/otel/propagation"
-	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
 	"go.opentelemetry.io/otel/trace"
+	tracenoop "go.opentelemetry.io/otel/trace/noop"
+
+	splunkotel "github.com/signalfx/splunk-otel-go"
 )
 
 // Config contains configuration options.
@@ -38,14 +45,77 @@ type Config struct {
 	Propagator propagation.TextMapPropagator
 
 	DefaultStartOpts []trace.SpanStartOption
+
+	// SpanNameFormatter, if set, returns the name of the span created for an
+	// Operation. An empty name means the default name of the Operation is
+	// used.
+	SpanNameFormatter func(Operation) string
+	// Filters are the predicates an Operation has to satisfy to be traced.
+	Filters []func(Operation) bool
+
+	// HTTPSemconv and DBSemconv are the semantic conventions emitted for the
+	// HTTP and database attributes. They are set from the
+	// OTEL_SEMCONV_STABILITY_OPT_IN environment variable before options are
+	// applied.
+	HTTPSemconv SemconvStability
+	DBSemconv   SemconvStability
+	// SpanSemconv is the stability of the semantic conventions of the span
+	// attributes emitted, e.g. the HTTPSemconv of an HTTP instrumentation.
+	// The schema URL of the Tracer is the one of the stable semantic
+	// conventions if only they are emitted, and v1.17.0 otherwise. It has to
+	// be set before user-provided options are applied.
+	SpanSemconv SemconvStability
+	// MetricSemconv is the stability of the semantic conventions of the
+	// metrics recorded, e.g. SemconvStable for the duration histograms. It
+	// sets the schema URL of the Meter the same way SpanSemconv does for the
+	// Tracer.
+	MetricSemconv SemconvStability
+
+	// RequireParentSpan, if true, means operations are only traced if their
+	// context contains a valid parent span.
+	RequireParentSpan bool
+
+	// PeerServices maps the peers of client operations to their peer.service
+	// attribute. It is set from the
+	// OTEL_INSTRUMENTATION_COMMON_PEER_SERVICE_MAPPING environment variable
+	// before options are applied.
+	PeerServices PeerServiceMapping
+
+	// disabled is true if the instrumentation is disabled with its
+	// EnabledKey environment variable.
+	disabled bool
+
+	// histograms are the duration histograms created by the Meter.
+	histograms *histograms
+}
+
+// Operation describes an operation traced by an instrumentation.
+type Operation struct {
+	// Name is the default name of the span created for the operation.
+	Name string
+	// Details is the instrumentation specific description of the operation.
+	// Its type is defined by each instrumentation.
+	Details any
+
+	// Histogram is the histogram the duration of the operation is recorded
+	// in. The duration is not recorded if it is nil.
+	Histogram *DurationHistogram
+	// Attributes are the low cardinality attributes the duration of the
+	// operation is recorded with.
+	Attributes []attribute.KeyValue
 }
 
 // NewConfig returns a Config for instrumentation with all options applied.
 //
 // If no TracerProvider or Propagator are specified with options, the default
 // OpenTelemetry globals will be used.
+//
+// If the instrumentation is disabled with its EnabledKey environment
+// variable, a no-op Tracer and Meter are used regardless of the options.
 func NewConfig(instrumentationName string, options ...Option) *Config {
-	c := Config{instName: instrumentationName}
+	c := Config{instName: instrumentationName, histograms: &histograms{}}
+	c.HTTPSemconv, c.DBSemconv = semconvStability()
+	c.PeerServices = PeerServiceMappingFromEnv()
 
 	for _, o := range options {
 		if o != nil {
@@ -53,6 +123,8 @@ func NewConfig(instrumentationName string, options ...Option) *Config {
 		}
 	}
 
+	c.ApplyEnabled()
+
 	if c.Tracer == nil {
 		c.Tracer = c.tracer(otel.GetTracerProvider())
 	}
@@ -75,6 +147,20 @@ func (c *Config) Copy() *Config {
 		Meter:            c.Meter,
 		Propagator:       c.Propagator,
 		DefaultStartOpts: make([]trace.SpanStartOption, len(c.DefaultStartOpts)),
+
+		SpanNameFormatter: c.SpanNameFormatter,
+		Filters:           slices.Clone(c.Filters),
+
+		HTTPSemconv:   c.HTTPSemconv,
+		DBSemconv:     c.DBSemconv,
+		SpanSemconv:   c.SpanSemconv,
+		MetricSemconv: c.MetricSemconv,
+
+		RequireParentSpan: c.RequireParentSpan,
+		PeerServices:      maps.Clone(c.PeerServices),
+
+		disabled:   c.disabled,
+		histograms: c.histograms,
 	}
 
 	copy(newC.DefaultStartOpts, c.DefaultStartOpts)
@@ -82,13 +168,30 @@ func (c *Config) Copy() *Config {
 	return &newC
 }
 
+// ApplyEnabled makes c use a no-op Tracer and Meter if the instrumentation is
+// disabled with its EnabledKey environment variable. NewConfig calls it once
+// all its options are applied. Instrumentations applying options of their own
+// to c afterwards need to call it again.
+func (c *Config) ApplyEnabled() {
+	if enabled(c.instName) {
+		return
+	}
+	c.disabled = true
+	c.Tracer = c.tracer(tracenoop.NewTracerProvider())
+	c.Meter = c.meter(metricnoop.NewMeterProvider())
+}
+
 // ResolveTracer returns an OpenTelemetry tracer from the appropriate
 // TracerProvider.
 //
 // If the passed context contains a span, the TracerProvider that created the
 // tracer that created that span will be used. Otherwise, the TracerProvider
-// from c is used.
+// from c is used. The no-op Tracer of a disabled instrumentation is always
+// used.
 func (c *Config) ResolveTracer(ctx context.Context) trace.Tracer {
+	if c.disabled {
+		return c.Tracer
+	}
 	if span := trace.SpanFromContext(ctx); span.SpanContext().IsValid() {
 		return c.tracer(span.TracerProvider())
 	}
@@ -119,14 +222,74 @@ func (c *Config) MergedSpanStartOptions(opts ...trace.SpanStartOption) []trace.S
 	return merged
 }
 
+// SpanName returns the name of the span created for op.
+func (c *Config) SpanName(op Operation) string {
+	if c == nil || c.SpanNameFormatter == nil {
+		return op.Name
+	}
+	if name := c.SpanNameFormatter(op); name != "" {
+		return name
+	}
+	return op.Name
+}
+
+// ShouldTrace returns true if op needs to be traced in ctx: tracing is not
+// suppressed in ctx with splunkotel.SuppressTracing, ctx contains a valid
+// parent span if c requires one, and op satisfies all the Filters c is
+// configured with.
+func (c *Config) ShouldTrace(ctx context.Context, op Operation) bool {
+	if splunkotel.IsTracingSuppressed(ctx) {
+		return false
+	}
+	if c == nil {
+		return true
+	}
+	if c.RequireParentSpan && !trace.SpanContextFromContext(ctx).IsValid() {
+		return false
+	}
+	for _, f := range c.Filters {
+		if !f(op) {
+			return false
+		}
+	}
+	return true
+}
+
 // WithSpan wraps the function f with a span named name.
 func (c *Config) WithSpan(ctx context.Context, name string, f func(context.Context) error, opts ...trace.SpanStartOption) error {
+	return c.WithOperation(ctx, Operation{Name: name}, f, opts...)
+}
+
+// WithOperation wraps the function f with a span for the operation op. If op
+// has a Histogram, the duration of f is recorded in it regardless of the span
+// being sampled. If op is not to be traced in ctx, see ShouldTrace, f is
+// called without a span and its duration is not recorded. If f panics, the
+// panic is recorded on the span, see RecordPanic, and the span is ended before
+// the panic is resumed.
+func (c *Config) WithOperation(ctx context.Context, op Operation, f func(context.Context) error, opts ...trace.SpanStartOption) error {
+	if !c.ShouldTrace(ctx, op) {
+		return f(ctx)
+	}
+
 	sso := c.MergedSpanStartOptions(opts...)
-	ctx, span := c.ResolveTracer(ctx).Start(ctx, name, sso...)
+	ctx, span := c.ResolveTracer(ctx).Start(ctx, c.SpanName(op), sso...)
+	start := time.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			RecordPanic(span, r)
+			c.RecordDuration(ctx, op, time.Since(start), PanicErrorType(r))
+			span.End()
+			panic(r)
+		}
+	}()
 	err := f(ctx)
+	elapsed := time.Since(start)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
+		c.RecordDuration(ctx, op, elapsed, ErrorType(err))
+	} else {
+		c.RecordDuration(ctx, op, elapsed)
 	}
 	span.End()
 
@@ -135,7 +298,7 @@ func (c *Config) WithSpan(ctx context.Context, name string, f func(context.Conte
 
 // tracer creates a tracer using the passed TracerProvider.
 func (c *Config) tracer(tp trace.TracerProvider) trace.Tracer {
-	opts := []trace.TracerOption{trace.WithSchemaURL(semconv.SchemaURL)}
+	opts := []trace.TracerOption{trace.WithSchemaURL(c.SpanSemconv.schemaURL())}
 	if c.Version != "" {
 		opts = append(opts, trace.WithInstrumentationVersion(c.Version))
 	}
@@ -144,7 +307,7 @@ func (c *Config) tracer(tp trace.TracerProvider) trace.Tracer {
 
 // meter creates a meter using the passed MeterProvider.
 func (c *Config) meter(mp metric.MeterProvider) metric.Meter {
-	opts := []metric.MeterOption{metric.WithSchemaURL(semconv.SchemaURL)}
+	opts := []metric.MeterOption{metric.WithSchemaURL(c.MetricSemconv.schemaURL())}
 	if c.Version != "" {
 		opts = append(opts, metric.WithInstrumentationVersion(c.Version))
 	}
@@ -28,6 +28,8 @@ import (
 	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
 	"go.opentelemetry.io/otel/trace"
 	"go.opentelemetry.io/otel/trace/noop"
+
+	splunkotel "github.com/signalfx/splunk-otel-go"
 )
 
 const iName = "github.com/signalfx/splunk-otel-go/instrumentation/internal"
@@ -96,10 +98,21 @@ type mockSpan struct {
 	StartOpts []trace.SpanStartOption
 
 	RecordedErrs []error
+	Events       []event
 	Statuses     []status
 	Ended        bool
 }
 
+type event struct {
+	Name  string
+	Attrs []attribute.KeyValue
+}
+
+func (s *mockSpan) AddEvent(name string, opts ...trace.EventOption) {
+	cfg := trace.NewEventConfig(opts...)
+	s.Events = append(s.Events, event{Name: name, Attrs: cfg.Attributes()})
+}
+
 func (s *mockSpan) RecordError(err error, _ ...trace.EventOption) {
 	s.RecordedErrs = append(s.RecordedErrs, err)
 }
@@ -155,6 +168,24 @@ func TestConfigTracerFromConfig(t *testing.T) {
 	assert.Equal(t, expected, got)
 }
 
+func TestConfigTracerSchemaURL(t *testing.T) {
+	tests := map[SemconvStability]string{
+		SemconvOld:    semconv.SchemaURL,
+		SemconvDup:    semconv.SchemaURL,
+		SemconvStable: "https://opentelemetry.io/schemas/1.37.0",
+	}
+	for s, want := range tests {
+		mtp := mockTracerProvider(nil)
+		c := NewConfig(iName, OptionFunc(func(c *Config) {
+			c.SpanSemconv = s
+		}), WithTracerProvider(mtp))
+		tracer, ok := c.ResolveTracer(context.Background()).(*fnTracer)
+		require.True(t, ok)
+		conf := trace.NewTracerConfig(tracer.opts...)
+		assert.Equal(t, want, conf.SchemaURL(), "stability %d", s)
+	}
+}
+
 func TestConfigTracerFromContext(t *testing.T) {
 	sc := trace.NewSpanContext(trace.SpanContextConfig{
 		TraceID: trace.TraceID{0x01},
@@ -300,3 +331,91 @@ func TestWithSpan(t *testing.T) {
 
 	assert.True(t, span.Ended, "mockSpan not ended by WithSpan")
 }
+
+func TestWithSpanPanic(t *testing.T) {
+	const spanName = "TestWithSpanPanic span"
+	spanRecorder := make(map[string]*mockSpan)
+	c := NewConfig(iName, WithTracerProvider(mockTracerProvider(spanRecorder)))
+
+	assert.PanicsWithValue(t, "TestWithSpanPanic panic", func() {
+		_ = c.WithSpan(context.Background(), spanName, func(context.Context) error {
+			panic("TestWithSpanPanic panic")
+		})
+	})
+
+	require.Contains(t, spanRecorder, spanName)
+	span := spanRecorder[spanName]
+
+	require.Len(t, span.Events, 1)
+	assert.Equal(t, semconv.ExceptionEventName, span.Events[0].Name)
+	require.Len(t, span.Statuses, 1)
+	assert.Equal(t, status{Code: codes.Error, Description: "panic: TestWithSpanPanic panic"}, span.Statuses[0])
+	assert.True(t, span.Ended, "mockSpan not ended by WithSpan")
+}
+
+func TestWithOperation(t *testing.T) {
+	spanRecorder := make(map[string]*mockSpan)
+	c := NewConfig(
+		iName,
+		WithTracerProvider(mockTracerProvider(spanRecorder)),
+		WithSpanNameFormatter(func(op string) string { return "formatted " + op }),
+	)
+
+	err := c.WithOperation(context.Background(), Operation{Name: "default", Details: "op"}, func(context.Context) error {
+		return nil
+	})
+	require.NoError(t, err)
+	require.Contains(t, spanRecorder, "formatted op")
+	assert.True(t, spanRecorder["formatted op"].Ended, "mockSpan not ended by WithOperation")
+
+	require.NoError(t, c.WithSpan(context.Background(), "unformatted", func(context.Context) error {
+		return nil
+	}))
+	assert.Contains(t, spanRecorder, "unformatted")
+}
+
+func TestWithOperationFiltered(t *testing.T) {
+	spanRecorder := make(map[string]*mockSpan)
+	c := NewConfig(
+		iName,
+		WithTracerProvider(mockTracerProvider(spanRecorder)),
+		WithFilter(func(string) bool { return false }),
+	)
+
+	var called bool
+	err := c.WithOperation(context.Background(), Operation{Name: "filtered", Details: "op"}, func(ctx context.Context) error {
+		called = true
+		assert.False(t, trace.SpanFromContext(ctx).SpanContext().IsValid(), "span started")
+		return nil
+	})
+	require.NoError(t, err)
+	assert.True(t, called, "WithOperation did not call passed func")
+	assert.NotContains(t, spanRecorder, "filtered")
+}
+
+func TestWithSpanSuppressed(t *testing.T) {
+	spanRecorder := make(map[string]*mockSpan)
+	c := NewConfig(iName, WithTracerProvider(mockTracerProvider(spanRecorder)))
+
+	ctx := splunkotel.SuppressTracing(context.Background())
+	assert.False(t, c.ShouldTrace(ctx, Operation{Name: "suppressed"}))
+
+	var called bool
+	err := c.WithSpan(ctx, "suppressed", func(ctx context.Context) error {
+		// Suppression propagates to the nested calls.
+		return c.WithSpan(ctx, "nested", func(context.Context) error {
+			called = true
+			return nil
+		})
+	})
+	require.NoError(t, err)
+	assert.True(t, called, "WithSpan did not call passed func")
+	assert.Empty(t, spanRecorder)
+
+	var nilConfig *Config
+	assert.False(t, nilConfig.ShouldTrace(ctx, Operation{}))
+
+	ctx = splunkotel.UnsuppressTracing(ctx)
+	require.NoError(t, c.WithSpan(ctx, "unsuppressed", func(context.Context) error { return nil }))
+	assert.Contains(t, spanRecorder, "unsuppressed")
+}
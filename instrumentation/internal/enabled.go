@@ -0,0 +1,45 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"os"
+	"path"
+	"strings"
+	"unicode"
+)
+
+// EnabledKey returns the environment variable that disables the
+// instrumentation named instrumentationName when set to "false". The key is
+// SPLUNK_INSTRUMENTATION_<NAME>_ENABLED where <NAME> is the last element of
+// instrumentationName without its "splunk" prefix, upper-cased, and with all
+// non-alphanumeric characters replaced by underscores (e.g.
+// SPLUNK_INSTRUMENTATION_CLIENT_GO_ENABLED for splunkclient-go).
+func EnabledKey(instrumentationName string) string {
+	name := strings.TrimPrefix(path.Base(instrumentationName), "splunk")
+	name = strings.Map(func(r rune) rune {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			return unicode.ToUpper(r)
+		}
+		return '_'
+	}, name)
+	return "SPLUNK_INSTRUMENTATION_" + name + "_ENABLED"
+}
+
+// enabled returns false if the instrumentation named instrumentationName is
+// disabled with its EnabledKey environment variable (case-insensitive).
+func enabled(instrumentationName string) bool {
+	return !strings.EqualFold(os.Getenv(EnabledKey(instrumentationName)), "false")
+}
@@ -0,0 +1,105 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestEnabledKey(t *testing.T) {
+	const prefix = "github.com/signalfx/splunk-otel-go/instrumentation/"
+	tests := map[string]string{
+		"database/sql/splunksql":                                          "SPLUNK_INSTRUMENTATION_SQL_ENABLED",
+		"k8s.io/client-go/splunkclient-go":                                "SPLUNK_INSTRUMENTATION_CLIENT_GO_ENABLED",
+		"gopkg.in/olivere/elastic/splunkelastic":                          "SPLUNK_INSTRUMENTATION_ELASTIC_ENABLED",
+		"github.com/tidwall/buntdb/splunkbuntdb":                          "SPLUNK_INSTRUMENTATION_BUNTDB_ENABLED",
+		"github.com/miekg/dns/splunkdns":                                  "SPLUNK_INSTRUMENTATION_DNS_ENABLED",
+		"github.com/graph-gophers/graphql-go/splunkgraphql":               "SPLUNK_INSTRUMENTATION_GRAPHQL_ENABLED",
+		"github.com/gomodule/redigo/splunkredigo":                         "SPLUNK_INSTRUMENTATION_REDIGO_ENABLED",
+		"github.com/syndtr/goleveldb/leveldb/splunkleveldb":               "SPLUNK_INSTRUMENTATION_LEVELDB_ENABLED",
+		"github.com/go-chi/chi/splunkchi":                                 "SPLUNK_INSTRUMENTATION_CHI_ENABLED",
+		"github.com/confluentinc/confluent-kafka-go/kafka/splunkkafka":    "SPLUNK_INSTRUMENTATION_KAFKA_ENABLED",
+		"github.com/confluentinc/confluent-kafka-go/v2/kafka/splunkkafka": "SPLUNK_INSTRUMENTATION_KAFKA_ENABLED",
+	}
+
+	for name, want := range tests {
+		assert.Equal(t, want, EnabledKey(prefix+name), name)
+	}
+}
+
+func TestNewConfigDisabled(t *testing.T) {
+	for _, v := range []string{"false", "FALSE"} {
+		t.Run(v, func(t *testing.T) {
+			t.Setenv(EnabledKey(iName), v)
+
+			spans := make(map[string]*mockSpan)
+			r := sdkmetric.NewManualReader()
+			c := NewConfig(
+				iName,
+				WithTracerProvider(mockTracerProvider(spans)),
+				WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(r))),
+			)
+
+			op := Operation{Name: "test", Histogram: DBClientOperationDuration}
+			ctx := context.Background()
+			require.NoError(t, c.WithOperation(ctx, op, func(ctx context.Context) error {
+				assert.False(t, trace.SpanFromContext(ctx).IsRecording())
+				return nil
+			}))
+			assert.Empty(t, spans)
+
+			var rm metricdata.ResourceMetrics
+			require.NoError(t, r.Collect(ctx, &rm))
+			assert.Empty(t, rm.ScopeMetrics)
+
+			// A span from an enabled instrumentation does not enable it.
+			ctx, _ = mockTracerProvider(nil).Tracer("parent").Start(ctx, "parent")
+			ctx = trace.ContextWithSpanContext(ctx, trace.NewSpanContext(trace.SpanContextConfig{
+				TraceID: trace.TraceID{0x01},
+				SpanID:  trace.SpanID{0x01},
+			}))
+			assert.Equal(t, c.Tracer, c.ResolveTracer(ctx))
+			assert.Equal(t, c.Tracer, c.Copy().ResolveTracer(ctx))
+		})
+	}
+}
+
+func TestNewConfigEnabled(t *testing.T) {
+	t.Setenv(EnabledKey(iName), "true")
+
+	spans := make(map[string]*mockSpan)
+	c := NewConfig(iName, WithTracerProvider(mockTracerProvider(spans)))
+	require.NoError(t, c.WithSpan(context.Background(), "test", func(context.Context) error { return nil }))
+	assert.Contains(t, spans, "test")
+}
+
+func TestApplyEnabled(t *testing.T) {
+	t.Setenv(EnabledKey(iName), "false")
+
+	spans := make(map[string]*mockSpan)
+	c := NewConfig(iName)
+	// Options applied after NewConfig do not enable the instrumentation.
+	WithTracerProvider(mockTracerProvider(spans)).Apply(c)
+	c.ApplyEnabled()
+	require.NoError(t, c.WithSpan(context.Background(), "test", func(context.Context) error { return nil }))
+	assert.Empty(t, spans)
+}
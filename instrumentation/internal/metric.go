@@ -0,0 +1,181 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/semconv/v1.37.0/dbconv"
+	"go.opentelemetry.io/otel/semconv/v1.37.0/dnsconv"
+	"go.opentelemetry.io/otel/semconv/v1.37.0/httpconv"
+	"go.opentelemetry.io/otel/semconv/v1.37.0/messagingconv"
+)
+
+// DurationHistogram describes the histogram the durations of operations are
+// recorded in.
+type DurationHistogram struct {
+	Name        string
+	Description string
+	// Boundaries are the explicit bucket boundaries, in seconds, advised for
+	// the histogram.
+	Boundaries []float64
+}
+
+var (
+	// dbBoundaries are the bucket boundaries advised by the semantic
+	// conventions for database and messaging durations.
+	dbBoundaries = []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5, 10}
+	// httpBoundaries are the bucket boundaries advised by the semantic
+	// conventions for HTTP durations.
+	httpBoundaries = []float64{0.005, 0.01, 0.025, 0.05, 0.075, 0.1, 0.25, 0.5, 0.75, 1, 2.5, 5, 7.5, 10}
+)
+
+var (
+	// DBClientOperationDuration is the db.client.operation.duration
+	// histogram.
+	DBClientOperationDuration = &DurationHistogram{
+		Name:        dbconv.ClientOperationDuration{}.Name(),
+		Description: dbconv.ClientOperationDuration{}.Description(),
+		Boundaries:  dbBoundaries,
+	}
+	// HTTPServerRequestDuration is the http.server.request.duration
+	// histogram.
+	HTTPServerRequestDuration = &DurationHistogram{
+		Name:        httpconv.ServerRequestDuration{}.Name(),
+		Description: httpconv.ServerRequestDuration{}.Description(),
+		Boundaries:  httpBoundaries,
+	}
+	// HTTPClientRequestDuration is the http.client.request.duration
+	// histogram.
+	HTTPClientRequestDuration = &DurationHistogram{
+		Name:        httpconv.ClientRequestDuration{}.Name(),
+		Description: httpconv.ClientRequestDuration{}.Description(),
+		Boundaries:  httpBoundaries,
+	}
+	// MessagingClientOperationDuration is the
+	// messaging.client.operation.duration histogram.
+	MessagingClientOperationDuration = &DurationHistogram{
+		Name:        messagingconv.ClientOperationDuration{}.Name(),
+		Description: messagingconv.ClientOperationDuration{}.Description(),
+		Boundaries:  dbBoundaries,
+	}
+	// MessagingProcessDuration is the messaging.process.duration histogram.
+	MessagingProcessDuration = &DurationHistogram{
+		Name:        messagingconv.ProcessDuration{}.Name(),
+		Description: messagingconv.ProcessDuration{}.Description(),
+		Boundaries:  dbBoundaries,
+	}
+	// DNSLookupDuration is the dns.lookup.duration histogram.
+	DNSLookupDuration = &DurationHistogram{
+		Name:        dnsconv.LookupDuration{}.Name(),
+		Description: dnsconv.LookupDuration{}.Description(),
+		Boundaries:  httpBoundaries,
+	}
+)
+
+// histogramKey identifies a histogram created by a meter.
+type histogramKey struct {
+	meter metric.Meter
+	h     *DurationHistogram
+}
+
+// histograms caches the histograms created for a Config. It is shared by
+// copies of the Config.
+type histograms struct {
+	mu sync.Mutex
+	m  map[histogramKey]metric.Float64Histogram
+}
+
+// histogram returns the histogram h created by the meter of c.
+func (c *Config) histogram(h *DurationHistogram) metric.Float64Histogram {
+	meter := c.ResolveMeter()
+	if c.histograms == nil {
+		return newHistogram(meter, h)
+	}
+
+	key := histogramKey{meter: meter, h: h}
+	c.histograms.mu.Lock()
+	defer c.histograms.mu.Unlock()
+	if hist, ok := c.histograms.m[key]; ok {
+		return hist
+	}
+	if c.histograms.m == nil {
+		c.histograms.m = make(map[histogramKey]metric.Float64Histogram)
+	}
+	hist := newHistogram(meter, h)
+	c.histograms.m[key] = hist
+	return hist
+}
+
+func newHistogram(meter metric.Meter, h *DurationHistogram) metric.Float64Histogram {
+	hist, err := meter.Float64Histogram(
+		h.Name,
+		metric.WithDescription(h.Description),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(h.Boundaries...),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+	return hist
+}
+
+// RecordDuration records d as the duration of op in the histogram of op. The
+// attributes of op are recorded along with attrs. Nothing is recorded if op
+// has no histogram.
+func (c *Config) RecordDuration(ctx context.Context, op Operation, d time.Duration, attrs ...attribute.KeyValue) {
+	if c == nil || op.Histogram == nil || c.ResolveMeter() == nil {
+		return
+	}
+
+	all := make([]attribute.KeyValue, 0, len(op.Attributes)+len(attrs))
+	all = append(all, op.Attributes...)
+	all = append(all, attrs...)
+	c.histogram(op.Histogram).Record(ctx, d.Seconds(), metric.WithAttributes(all...))
+}
+
+// ErrorType returns the error.type attribute describing err.
+func ErrorType(err error) attribute.KeyValue {
+	return semconv.ErrorTypeKey.String(fmt.Sprintf("%T", err))
+}
+
+// PanicErrorType returns the error.type attribute describing the value
+// recovered from a panic.
+func PanicErrorType(recovered any) attribute.KeyValue {
+	return semconv.ErrorTypeKey.String(fmt.Sprintf("%T", recovered))
+}
+
+// HTTPRequestMethod returns the http.request.method attribute for method.
+// Methods not known by the semantic conventions are reported as _OTHER to
+// bound the cardinality of the attribute.
+func HTTPRequestMethod(method string) attribute.KeyValue {
+	switch method {
+	case http.MethodConnect, http.MethodDelete, http.MethodGet, http.MethodHead,
+		http.MethodOptions, http.MethodPatch, http.MethodPost, http.MethodPut,
+		http.MethodTrace:
+		return semconv.HTTPRequestMethodKey.String(method)
+	case "":
+		return semconv.HTTPRequestMethodGet
+	}
+	return semconv.HTTPRequestMethodKey.String("_OTHER")
+}
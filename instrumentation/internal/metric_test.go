@@ -0,0 +1,140 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func collectHistogram(t *testing.T, r sdkmetric.Reader, name string) metricdata.Histogram[float64] {
+	t.Helper()
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, r.Collect(context.Background(), &rm))
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name == name {
+				assert.Equal(t, "s", m.Unit)
+				h, ok := m.Data.(metricdata.Histogram[float64])
+				require.True(t, ok, "not a histogram")
+				return h
+			}
+		}
+	}
+	require.Failf(t, "histogram not found", "%s", name)
+	return metricdata.Histogram[float64]{}
+}
+
+func TestWithOperationRecordsDuration(t *testing.T) {
+	r := sdkmetric.NewManualReader()
+	c := NewConfig(
+		iName,
+		WithTracerProvider(noop.NewTracerProvider()),
+		WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(r))),
+	)
+
+	attr := attribute.String("db.operation.name", "GET")
+	op := Operation{
+		Name:       "test",
+		Histogram:  DBClientOperationDuration,
+		Attributes: []attribute.KeyValue{attr},
+	}
+	ctx := context.Background()
+	require.NoError(t, c.WithOperation(ctx, op, func(context.Context) error { return nil }))
+	errTest := errors.New("test")
+	require.ErrorIs(t, c.WithOperation(ctx, op, func(context.Context) error { return errTest }), errTest)
+	require.NoError(t, c.WithSpan(ctx, "no histogram", func(context.Context) error { return nil }))
+
+	h := collectHistogram(t, r, "db.client.operation.duration")
+	require.Len(t, h.DataPoints, 2)
+	got := make(map[attribute.Set]uint64)
+	for _, dp := range h.DataPoints {
+		got[dp.Attributes] = dp.Count
+		assert.Equal(t, []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5, 10}, dp.Bounds)
+	}
+	assert.Equal(t, map[attribute.Set]uint64{
+		attribute.NewSet(attr):                     1,
+		attribute.NewSet(attr, ErrorType(errTest)): 1,
+	}, got)
+}
+
+func TestMeterSchemaURL(t *testing.T) {
+	for _, tc := range []struct {
+		stability SemconvStability
+		want      string
+	}{
+		{SemconvOld, "https://opentelemetry.io/schemas/1.17.0"},
+		{SemconvStable, "https://opentelemetry.io/schemas/1.37.0"},
+	} {
+		r := sdkmetric.NewManualReader()
+		c := NewConfig(
+			iName,
+			OptionFunc(func(c *Config) { c.MetricSemconv = tc.stability }),
+			WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(r))),
+		)
+		c.RecordDuration(context.Background(), Operation{Histogram: DBClientOperationDuration}, 0)
+
+		var rm metricdata.ResourceMetrics
+		require.NoError(t, r.Collect(context.Background(), &rm))
+		require.Len(t, rm.ScopeMetrics, 1)
+		assert.Equal(t, tc.want, rm.ScopeMetrics[0].Scope.SchemaURL)
+	}
+}
+
+func TestWithOperationFilteredDuration(t *testing.T) {
+	r := sdkmetric.NewManualReader()
+	c := NewConfig(
+		iName,
+		WithTracerProvider(noop.NewTracerProvider()),
+		WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(r))),
+		WithFilter(func(string) bool { return false }),
+	)
+
+	op := Operation{Name: "test", Details: "", Histogram: DBClientOperationDuration}
+	require.NoError(t, c.WithOperation(context.Background(), op, func(context.Context) error { return nil }))
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, r.Collect(context.Background(), &rm))
+	assert.Empty(t, rm.ScopeMetrics)
+}
+
+func TestRecordDurationCopy(t *testing.T) {
+	r := sdkmetric.NewManualReader()
+	c := NewConfig(iName, WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(r))))
+	cp := c.Copy()
+
+	op := Operation{Histogram: HTTPServerRequestDuration}
+	c.RecordDuration(context.Background(), op, 0)
+	cp.RecordDuration(context.Background(), op, 0)
+
+	h := collectHistogram(t, r, "http.server.request.duration")
+	require.Len(t, h.DataPoints, 1)
+	assert.Equal(t, uint64(2), h.DataPoints[0].Count)
+}
+
+func TestHTTPRequestMethod(t *testing.T) {
+	assert.Equal(t, "GET", HTTPRequestMethod("").Value.AsString())
+	assert.Equal(t, "PATCH", HTTPRequestMethod("PATCH").Value.AsString())
+	assert.Equal(t, "_OTHER", HTTPRequestMethod("PURGE").Value.AsString())
+}
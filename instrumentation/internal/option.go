@@ -15,6 +15,8 @@
 package internal
 
 import (
+	"maps"
+
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/propagation"
@@ -68,3 +70,62 @@ func WithPropagator(p propagation.TextMapPropagator) Option {
 		c.Propagator = p
 	})
 }
+
+// WithSpanNameFormatter returns an Option that sets f to name the spans
+// created for operations with Details of type T. If f returns an empty
+// string, or the Details of an operation are not of type T, the default span
+// name of the operation is used.
+func WithSpanNameFormatter[T any](f func(T) string) Option {
+	return OptionFunc(func(c *Config) {
+		if f == nil {
+			c.SpanNameFormatter = nil
+			return
+		}
+		c.SpanNameFormatter = func(op Operation) string {
+			if d, ok := op.Details.(T); ok {
+				return f(d)
+			}
+			return ""
+		}
+	})
+}
+
+// WithFilter returns an Option that adds f to the filters of operations with
+// Details of type T. Operations f returns false for are not traced.
+// Operations with Details of another type are not filtered by f.
+func WithFilter[T any](f func(T) bool) Option {
+	return OptionFunc(func(c *Config) {
+		if f == nil {
+			return
+		}
+		c.Filters = append(c.Filters, func(op Operation) bool {
+			if d, ok := op.Details.(T); ok {
+				return f(d)
+			}
+			return true
+		})
+	})
+}
+
+// WithRequireParentSpan returns an Option that sets if operations are only
+// traced when their context contains a valid parent span. This avoids the
+// single span traces of client operations performed outside of any request,
+// e.g. by background pollers.
+func WithRequireParentSpan(require bool) Option {
+	return OptionFunc(func(c *Config) {
+		c.RequireParentSpan = require
+	})
+}
+
+// WithPeerServiceMapping returns an Option that maps the peers of client
+// operations to their peer.service attribute, see PeerServiceMapping. The
+// entries of m take precedence over the ones set with the
+// OTEL_INSTRUMENTATION_COMMON_PEER_SERVICE_MAPPING environment variable.
+func WithPeerServiceMapping(m map[string]string) Option {
+	return OptionFunc(func(c *Config) {
+		if c.PeerServices == nil {
+			c.PeerServices = PeerServiceMapping{}
+		}
+		maps.Copy(c.PeerServices, m)
+	})
+}
@@ -15,9 +15,11 @@
 package internal
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/trace"
@@ -44,3 +46,32 @@ func TestWithPropagator(t *testing.T) {
 	p = propagation.NewCompositeTextMapPropagator(p)
 	assert.Equal(t, p, NewConfig(iName, WithPropagator(p)).Propagator)
 }
+
+func TestWithSpanNameFormatter(t *testing.T) {
+	type details struct{ name string }
+	c := NewConfig(iName, WithSpanNameFormatter(func(d details) string {
+		return d.name
+	}))
+
+	assert.Equal(t, "custom", c.SpanName(Operation{Name: "default", Details: details{name: "custom"}}))
+	assert.Equal(t, "default", c.SpanName(Operation{Name: "default", Details: details{}}), "empty name")
+	assert.Equal(t, "default", c.SpanName(Operation{Name: "default", Details: "other"}), "other details type")
+
+	c = NewConfig(iName, WithSpanNameFormatter[details](nil))
+	assert.Nil(t, c.SpanNameFormatter)
+}
+
+func TestWithFilter(t *testing.T) {
+	c := NewConfig(
+		iName,
+		WithFilter(func(d string) bool { return d != "skip" }),
+		WithFilter(func(d string) bool { return d != "ignore" }),
+		WithFilter[string](nil),
+	)
+	require.Len(t, c.Filters, 2)
+
+	assert.True(t, c.ShouldTrace(context.Background(), Operation{Details: "trace"}))
+	assert.False(t, c.ShouldTrace(context.Background(), Operation{Details: "skip"}))
+	assert.False(t, c.ShouldTrace(context.Background(), Operation{Details: "ignore"}))
+	assert.True(t, c.ShouldTrace(context.Background(), Operation{Details: 1}), "other details type")
+}
@@ -0,0 +1,38 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"fmt"
+	"runtime/debug"
+
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RecordPanic records the value recovered from a panic as an exception event
+// of span, including the stack trace of the panicking goroutine, and sets the
+// span status to error. It is meant to be called by the deferred function
+// that recovered the panic, before the span is ended and the panic resumed.
+func RecordPanic(span trace.Span, recovered any) {
+	msg := fmt.Sprint(recovered)
+	span.AddEvent(semconv.ExceptionEventName, trace.WithAttributes(
+		semconv.ExceptionTypeKey.String(fmt.Sprintf("%T", recovered)),
+		semconv.ExceptionMessageKey.String(msg),
+		semconv.ExceptionStacktraceKey.String(string(debug.Stack())),
+	))
+	span.SetStatus(codes.Error, "panic: "+msg)
+}
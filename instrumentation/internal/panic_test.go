@@ -0,0 +1,76 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+)
+
+func TestRecordPanic(t *testing.T) {
+	span := &mockSpan{}
+	func() {
+		defer func() { RecordPanic(span, recover()) }()
+		panic(errors.New("boom"))
+	}()
+
+	require.Len(t, span.Events, 1)
+	assert.Equal(t, semconv.ExceptionEventName, span.Events[0].Name)
+	attrs := attribute.NewSet(span.Events[0].Attrs...)
+	got, _ := attrs.Value(semconv.ExceptionTypeKey)
+	assert.Equal(t, "*errors.errorString", got.AsString())
+	got, _ = attrs.Value(semconv.ExceptionMessageKey)
+	assert.Equal(t, "boom", got.AsString())
+	got, _ = attrs.Value(semconv.ExceptionStacktraceKey)
+	assert.Contains(t, got.AsString(), "TestRecordPanic", "stack trace of the panic")
+
+	assert.Equal(t, []status{{Code: codes.Error, Description: "panic: boom"}}, span.Statuses)
+}
+
+func TestWithOperationPanicDuration(t *testing.T) {
+	r := sdkmetric.NewManualReader()
+	c := NewConfig(
+		iName,
+		WithTracerProvider(mockTracerProvider(nil)),
+		WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(r))),
+	)
+
+	op := Operation{Name: "test", Histogram: DBClientOperationDuration}
+	assert.Panics(t, func() {
+		_ = c.WithOperation(context.Background(), op, func(context.Context) error {
+			panic("boom")
+		})
+	})
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, r.Collect(context.Background(), &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+	require.Len(t, rm.ScopeMetrics[0].Metrics, 1)
+	h, ok := rm.ScopeMetrics[0].Metrics[0].Data.(metricdata.Histogram[float64])
+	require.True(t, ok)
+	require.Len(t, h.DataPoints, 1)
+	v, ok := h.DataPoints[0].Attributes.Value("error.type")
+	require.True(t, ok)
+	assert.Equal(t, "string", v.AsString())
+}
@@ -0,0 +1,32 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"os"
+	"strings"
+)
+
+// requireParentSpanKey is the environment variable setting the default of
+// the RequireParentSpan of the client instrumentations.
+const requireParentSpanKey = "SPLUNK_INSTRUMENTATION_REQUIRE_PARENT_SPAN"
+
+// RequireParentSpanFromEnv returns true if the
+// SPLUNK_INSTRUMENTATION_REQUIRE_PARENT_SPAN environment variable is set to
+// "true" (case-insensitive). Client instrumentations use it as the default of
+// RequireParentSpan before options are applied.
+func RequireParentSpanFromEnv() bool {
+	return strings.EqualFold(os.Getenv(requireParentSpanKey), "true")
+}
@@ -0,0 +1,66 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestRequireParentSpanFromEnv(t *testing.T) {
+	for env, want := range map[string]bool{
+		"":      false,
+		"false": false,
+		"1":     false,
+		"true":  true,
+		"TRUE":  true,
+	} {
+		t.Setenv(requireParentSpanKey, env)
+		assert.Equal(t, want, RequireParentSpanFromEnv(), env)
+	}
+}
+
+func TestWithRequireParentSpan(t *testing.T) {
+	spanRecorder := make(map[string]*mockSpan)
+	c := NewConfig(
+		iName,
+		WithTracerProvider(mockTracerProvider(spanRecorder)),
+		WithRequireParentSpan(true),
+	)
+	assert.True(t, c.RequireParentSpan)
+	assert.True(t, c.Copy().RequireParentSpan)
+
+	var called bool
+	require.NoError(t, c.WithSpan(context.Background(), "orphan", func(context.Context) error {
+		called = true
+		return nil
+	}))
+	assert.True(t, called, "WithSpan did not call passed func")
+	assert.NotContains(t, spanRecorder, "orphan")
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{0x01},
+		SpanID:  trace.SpanID{0x01},
+	})
+	ctx := trace.ContextWithSpanContext(context.Background(), sc)
+	assert.True(t, c.ShouldTrace(ctx, Operation{}))
+
+	c = NewConfig(iName, WithRequireParentSpan(true), WithRequireParentSpan(false))
+	assert.True(t, c.ShouldTrace(context.Background(), Operation{}))
+}
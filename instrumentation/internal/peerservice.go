@@ -0,0 +1,95 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+)
+
+// peerServiceMappingKey is the environment variable mapping the peers of
+// client operations to the logical names of the services they are part of.
+const peerServiceMappingKey = "OTEL_INSTRUMENTATION_COMMON_PEER_SERVICE_MAPPING"
+
+// PeerServiceMapping maps the peers of client operations to the logical name
+// of the service they are part of, the peer.service attribute. Peers are
+// identified by their host name or IP address, optionally followed by a
+// ":<port>" suffix to only match that port.
+type PeerServiceMapping map[string]string
+
+// ParsePeerServiceMapping parses a comma-separated list of <peer>=<service>
+// entries, e.g. "1.2.3.4=cats-service,redis.local:6380=cache". Invalid
+// entries are ignored.
+func ParsePeerServiceMapping(s string) PeerServiceMapping {
+	m := PeerServiceMapping{}
+	for _, entry := range strings.Split(s, ",") {
+		peer, service, ok := strings.Cut(entry, "=")
+		peer, service = strings.TrimSpace(peer), strings.TrimSpace(service)
+		if !ok || peer == "" || service == "" {
+			continue
+		}
+		m[peer] = service
+	}
+	return m
+}
+
+// PeerServiceMappingFromEnv returns the PeerServiceMapping set with the
+// OTEL_INSTRUMENTATION_COMMON_PEER_SERVICE_MAPPING environment variable.
+func PeerServiceMappingFromEnv() PeerServiceMapping {
+	return ParsePeerServiceMapping(os.Getenv(peerServiceMappingKey))
+}
+
+// Attributes returns the peer.service attribute of the peer at host and port,
+// or nil if the peer is not mapped. An entry for the host and port takes
+// precedence over one for only the host. A zero port means it is unknown.
+func (m PeerServiceMapping) Attributes(host string, port int) []attribute.KeyValue {
+	if host == "" || len(m) == 0 {
+		return nil
+	}
+	if port > 0 {
+		if s, ok := m[net.JoinHostPort(host, strconv.Itoa(port))]; ok {
+			return []attribute.KeyValue{semconv.PeerServiceKey.String(s)}
+		}
+	}
+	if s, ok := m[host]; ok {
+		return []attribute.KeyValue{semconv.PeerServiceKey.String(s)}
+	}
+	return nil
+}
+
+// URLAttributes returns the peer.service attribute of the peer u is addressed
+// to, see Attributes. The default port of the http and https schemes is used
+// if u does not contain a port.
+func (m PeerServiceMapping) URLAttributes(u *url.URL) []attribute.KeyValue {
+	if u == nil {
+		return nil
+	}
+	port, _ := strconv.Atoi(u.Port())
+	if port == 0 {
+		switch u.Scheme {
+		case "http":
+			port = 80
+		case "https":
+			port = 443
+		}
+	}
+	return m.Attributes(u.Hostname(), port)
+}
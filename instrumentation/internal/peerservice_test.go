@@ -0,0 +1,107 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+)
+
+func TestParsePeerServiceMapping(t *testing.T) {
+	tests := map[string]PeerServiceMapping{
+		"":                          {},
+		"1.2.3.4=cats-service":      {"1.2.3.4": "cats-service"},
+		" db = users , cache=redis": {"db": "users", "cache": "redis"},
+		"redis.local:6380=cache":    {"redis.local:6380": "cache"},
+		"invalid,=svc,host=,a=b":    {"a": "b"},
+	}
+
+	for in, want := range tests {
+		assert.Equal(t, want, ParsePeerServiceMapping(in), in)
+	}
+}
+
+func TestPeerServiceMappingAttributes(t *testing.T) {
+	m := PeerServiceMapping{
+		"db.local":      "users",
+		"db.local:5433": "users-replica",
+		"::1":           "local",
+		"[::1]:6379":    "local-cache",
+	}
+
+	tests := []struct {
+		host string
+		port int
+		want []attribute.KeyValue
+	}{
+		{host: "db.local", want: []attribute.KeyValue{semconv.PeerServiceKey.String("users")}},
+		{host: "db.local", port: 5432, want: []attribute.KeyValue{semconv.PeerServiceKey.String("users")}},
+		{host: "db.local", port: 5433, want: []attribute.KeyValue{semconv.PeerServiceKey.String("users-replica")}},
+		{host: "::1", port: 6379, want: []attribute.KeyValue{semconv.PeerServiceKey.String("local-cache")}},
+		{host: "::1", port: 80, want: []attribute.KeyValue{semconv.PeerServiceKey.String("local")}},
+		{host: "other.local", port: 5432},
+		{host: ""},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.want, m.Attributes(test.host, test.port), "%s:%d", test.host, test.port)
+	}
+}
+
+func TestPeerServiceMappingURLAttributes(t *testing.T) {
+	m := PeerServiceMapping{
+		"es.local":         "search",
+		"es.local:443":     "search-tls",
+		"k8s.local:6443":   "kubernetes",
+		"other.local:8080": "other",
+	}
+
+	tests := map[string][]attribute.KeyValue{
+		"http://es.local/_search":     {semconv.PeerServiceKey.String("search")},
+		"https://es.local/_search":    {semconv.PeerServiceKey.String("search-tls")},
+		"http://es.local:443/_search": {semconv.PeerServiceKey.String("search-tls")},
+		"https://k8s.local:6443/api":  {semconv.PeerServiceKey.String("kubernetes")},
+		"http://other.local/":         nil,
+	}
+
+	for raw, want := range tests {
+		u, err := url.Parse(raw)
+		require.NoError(t, err)
+		assert.Equal(t, want, m.URLAttributes(u), raw)
+	}
+	assert.Nil(t, m.URLAttributes(nil))
+}
+
+func TestWithPeerServiceMapping(t *testing.T) {
+	t.Setenv(peerServiceMappingKey, "db.local=users,cache.local=redis")
+
+	c := NewConfig(iName, WithPeerServiceMapping(map[string]string{
+		"cache.local":  "sessions",
+		"search.local": "products",
+	}))
+	assert.Equal(t, PeerServiceMapping{
+		"db.local":     "users",
+		"cache.local":  "sessions",
+		"search.local": "products",
+	}, c.PeerServices)
+
+	c.Copy().PeerServices["db.local"] = "changed"
+	assert.Equal(t, "users", c.PeerServices["db.local"], "Copy shares PeerServices")
+}
@@ -0,0 +1,243 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"maps"
+	"os"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+)
+
+// semconvStabilityOptInKey is the environment variable listing the domains
+// the stable semantic conventions are emitted for.
+const semconvStabilityOptInKey = "OTEL_SEMCONV_STABILITY_OPT_IN"
+
+// SemconvStability defines which semantic conventions are emitted for a
+// domain during the migration from the v1.17.0 to the stable conventions.
+type SemconvStability int
+
+const (
+	// SemconvOld emits only the v1.17.0 semantic conventions. This is the
+	// default.
+	SemconvOld SemconvStability = iota
+	// SemconvStable emits only the stable semantic conventions.
+	SemconvStable
+	// SemconvDup emits both the v1.17.0 and the stable semantic conventions.
+	SemconvDup
+)
+
+// Old returns true if the v1.17.0 semantic conventions are emitted.
+func (s SemconvStability) Old() bool {
+	return s != SemconvStable
+}
+
+// Stable returns true if the stable semantic conventions are emitted.
+func (s SemconvStability) Stable() bool {
+	return s != SemconvOld
+}
+
+// v117SchemaURL is the schema URL of the v1.17.0 semantic conventions.
+const v117SchemaURL = "https://opentelemetry.io/schemas/1.17.0"
+
+// schemaURL returns the schema URL of the semantic conventions emitted with
+// s. The v1.17.0 one is used as long as they are emitted.
+func (s SemconvStability) schemaURL() string {
+	if s.Old() {
+		return v117SchemaURL
+	}
+	return semconv.SchemaURL
+}
+
+// semconvStability returns the stability of the HTTP and database semantic
+// conventions opted into with the OTEL_SEMCONV_STABILITY_OPT_IN environment
+// variable. The duplicate emission wins if a domain is listed twice.
+func semconvStability() (httpStability, dbStability SemconvStability) {
+	for _, v := range strings.Split(os.Getenv(semconvStabilityOptInKey), ",") {
+		switch strings.TrimSpace(v) {
+		case "http":
+			httpStability = max(httpStability, SemconvStable)
+		case "http/dup":
+			httpStability = SemconvDup
+		case "database":
+			dbStability = max(dbStability, SemconvStable)
+		case "database/dup":
+			dbStability = SemconvDup
+		}
+	}
+	return httpStability, dbStability
+}
+
+// translation returns the stable attributes replacing a v1.17.0 attribute.
+type translation func(attribute.KeyValue) []attribute.KeyValue
+
+// rename returns a translation setting the value of the v1.17.0 attribute
+// to key.
+func rename(key attribute.Key) translation {
+	return func(kv attribute.KeyValue) []attribute.KeyValue {
+		return []attribute.KeyValue{{Key: key, Value: kv.Value}}
+	}
+}
+
+// drop is the translation of v1.17.0 attributes removed from the stable
+// semantic conventions.
+func drop(attribute.KeyValue) []attribute.KeyValue { return nil }
+
+// netTranslations are the translations of the v1.17.0 network attributes
+// shared by the HTTP and database semantic conventions.
+var netTranslations = map[attribute.Key]translation{
+	"net.peer.name":        rename(semconv.ServerAddressKey),
+	"net.peer.port":        rename(semconv.ServerPortKey),
+	"net.host.name":        rename(semconv.ServerAddressKey),
+	"net.host.port":        rename(semconv.ServerPortKey),
+	"net.sock.peer.addr":   rename(semconv.NetworkPeerAddressKey),
+	"net.sock.peer.port":   rename(semconv.NetworkPeerPortKey),
+	"net.protocol.name":    rename(semconv.NetworkProtocolNameKey),
+	"net.protocol.version": rename(semconv.NetworkProtocolVersionKey),
+	"net.transport": func(kv attribute.KeyValue) []attribute.KeyValue {
+		switch kv.Value.AsString() {
+		case "ip_tcp":
+			return []attribute.KeyValue{semconv.NetworkTransportTCP}
+		case "ip_udp":
+			return []attribute.KeyValue{semconv.NetworkTransportUDP}
+		case "pipe":
+			return []attribute.KeyValue{semconv.NetworkTransportPipe}
+		case "unix":
+			return []attribute.KeyValue{semconv.NetworkTransportUnix}
+		}
+		// The in-process and other transports have no stable equivalent.
+		return nil
+	},
+	"net.sock.family": func(kv attribute.KeyValue) []attribute.KeyValue {
+		switch kv.Value.AsString() {
+		case "inet":
+			return []attribute.KeyValue{semconv.NetworkTypeIPv4}
+		case "inet6":
+			return []attribute.KeyValue{semconv.NetworkTypeIPv6}
+		}
+		return nil
+	},
+}
+
+// httpTranslations are the translations of the v1.17.0 HTTP attributes.
+var httpTranslations = merge(netTranslations, map[attribute.Key]translation{
+	"http.method": func(kv attribute.KeyValue) []attribute.KeyValue {
+		m := HTTPRequestMethod(kv.Value.AsString())
+		if m.Value.AsString() != kv.Value.AsString() {
+			return []attribute.KeyValue{m, semconv.HTTPRequestMethodOriginal(kv.Value.AsString())}
+		}
+		return []attribute.KeyValue{m}
+	},
+	"http.status_code":             rename(semconv.HTTPResponseStatusCodeKey),
+	"http.url":                     rename(semconv.URLFullKey),
+	"http.scheme":                  rename(semconv.URLSchemeKey),
+	"http.flavor":                  rename(semconv.NetworkProtocolVersionKey),
+	"http.user_agent":              rename(semconv.UserAgentOriginalKey),
+	"http.request_content_length":  rename(semconv.HTTPRequestBodySizeKey),
+	"http.response_content_length": rename(semconv.HTTPResponseBodySizeKey),
+	"http.client_ip":               rename(semconv.ClientAddressKey),
+	"http.target": func(kv attribute.KeyValue) []attribute.KeyValue {
+		path, query, ok := strings.Cut(kv.Value.AsString(), "?")
+		attrs := []attribute.KeyValue{semconv.URLPath(path)}
+		if ok {
+			attrs = append(attrs, semconv.URLQuery(query))
+		}
+		return attrs
+	},
+})
+
+// dbSystemNames are the values of the db.system attribute renamed in the
+// stable db.system.name attribute.
+var dbSystemNames = map[string]string{
+	"cosmosdb": "azure.cosmosdb",
+	"db2":      "ibm.db2",
+	"hanadb":   "sap.hana",
+	"informix": "ibm.informix",
+	"maxdb":    "sap.maxdb",
+	"mssql":    "microsoft.sql_server",
+	"oracle":   "oracle.db",
+	"spanner":  "gcp.spanner",
+}
+
+// dbTranslations are the translations of the v1.17.0 database attributes.
+var dbTranslations = merge(netTranslations, map[attribute.Key]translation{
+	"db.system": func(kv attribute.KeyValue) []attribute.KeyValue {
+		name := kv.Value.AsString()
+		if n, ok := dbSystemNames[name]; ok {
+			name = n
+		}
+		return []attribute.KeyValue{semconv.DBSystemNameKey.String(name)}
+	},
+	"db.statement": rename(semconv.DBQueryTextKey),
+	"db.operation": rename(semconv.DBOperationNameKey),
+	"db.name":      rename(semconv.DBNamespaceKey),
+	"db.redis.database_index": func(kv attribute.KeyValue) []attribute.KeyValue {
+		return []attribute.KeyValue{semconv.DBNamespace(strconv.FormatInt(kv.Value.AsInt64(), 10))}
+	},
+	"db.user":              drop,
+	"db.connection_string": drop,
+})
+
+// merge returns the translations of base and m. Those of m take precedence.
+func merge(base, m map[attribute.Key]translation) map[attribute.Key]translation {
+	out := make(map[attribute.Key]translation, len(base)+len(m))
+	maps.Copy(out, base)
+	maps.Copy(out, m)
+	return out
+}
+
+// translate returns the attributes emitted for the v1.17.0 attrs with the
+// stability s.
+func translate(s SemconvStability, t map[attribute.Key]translation, attrs []attribute.KeyValue) []attribute.KeyValue {
+	if !s.Stable() {
+		return attrs
+	}
+
+	out := make([]attribute.KeyValue, 0, len(attrs))
+	for _, kv := range attrs {
+		f, ok := t[kv.Key]
+		if !ok {
+			// Attribute shared by all the semantic conventions.
+			out = append(out, kv)
+			continue
+		}
+		if s.Old() {
+			out = append(out, kv)
+		}
+		out = append(out, f(kv)...)
+	}
+	return out
+}
+
+// HTTPAttributes returns the attributes emitted for the v1.17.0 HTTP and
+// network attributes attrs according to the HTTPSemconv stability of c.
+func (c *Config) HTTPAttributes(attrs ...attribute.KeyValue) []attribute.KeyValue {
+	if c == nil {
+		return attrs
+	}
+	return translate(c.HTTPSemconv, httpTranslations, attrs)
+}
+
+// DBAttributes returns the attributes emitted for the v1.17.0 database and
+// network attributes attrs according to the DBSemconv stability of c.
+func (c *Config) DBAttributes(attrs ...attribute.KeyValue) []attribute.KeyValue {
+	if c == nil {
+		return attrs
+	}
+	return translate(c.DBSemconv, dbTranslations, attrs)
+}
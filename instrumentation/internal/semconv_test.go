@@ -0,0 +1,134 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+)
+
+func TestSemconvStability(t *testing.T) {
+	tests := []struct {
+		env      string
+		wantHTTP SemconvStability
+		wantDB   SemconvStability
+	}{
+		{env: "", wantHTTP: SemconvOld, wantDB: SemconvOld},
+		{env: "http", wantHTTP: SemconvStable, wantDB: SemconvOld},
+		{env: "http/dup", wantHTTP: SemconvDup, wantDB: SemconvOld},
+		{env: "database", wantHTTP: SemconvOld, wantDB: SemconvStable},
+		{env: " http , database/dup", wantHTTP: SemconvStable, wantDB: SemconvDup},
+		{env: "http/dup,http", wantHTTP: SemconvDup, wantDB: SemconvOld},
+		{env: "messaging,unknown", wantHTTP: SemconvOld, wantDB: SemconvOld},
+	}
+
+	for _, test := range tests {
+		t.Run(test.env, func(t *testing.T) {
+			t.Setenv(semconvStabilityOptInKey, test.env)
+			c := NewConfig(iName)
+			assert.Equal(t, test.wantHTTP, c.HTTPSemconv)
+			assert.Equal(t, test.wantDB, c.DBSemconv)
+			cp := c.Copy()
+			assert.Equal(t, test.wantHTTP, cp.HTTPSemconv)
+			assert.Equal(t, test.wantDB, cp.DBSemconv)
+		})
+	}
+}
+
+func TestSemconvStabilityEmission(t *testing.T) {
+	assert.True(t, SemconvOld.Old())
+	assert.False(t, SemconvOld.Stable())
+	assert.False(t, SemconvStable.Old())
+	assert.True(t, SemconvStable.Stable())
+	assert.True(t, SemconvDup.Old())
+	assert.True(t, SemconvDup.Stable())
+}
+
+func TestDBAttributes(t *testing.T) {
+	old := []attribute.KeyValue{
+		semconv.DBSystemKey.String("mssql"),
+		semconv.DBStatementKey.String("SELECT 1"),
+		semconv.DBOperationKey.String("SELECT"),
+		semconv.DBNameKey.String("test"),
+		semconv.DBUserKey.String("user"),
+		semconv.DBRedisDBIndexKey.Int(3),
+		semconv.NetPeerNameKey.String("db.local"),
+		semconv.NetPeerPortKey.Int(1433),
+		semconv.NetTransportTCP,
+		semconv.NetTransportInProc,
+		attribute.String("custom", "value"),
+	}
+	stable := []attribute.KeyValue{
+		attribute.String("db.system.name", "microsoft.sql_server"),
+		attribute.String("db.query.text", "SELECT 1"),
+		attribute.String("db.operation.name", "SELECT"),
+		attribute.String("db.namespace", "test"),
+		attribute.String("db.namespace", "3"),
+		attribute.String("server.address", "db.local"),
+		attribute.Int("server.port", 1433),
+		attribute.String("network.transport", "tcp"),
+		attribute.String("custom", "value"),
+	}
+
+	assert.Equal(t, old, (&Config{DBSemconv: SemconvOld}).DBAttributes(old...))
+	assert.Equal(t, stable, (&Config{DBSemconv: SemconvStable}).DBAttributes(old...))
+	got := (&Config{DBSemconv: SemconvDup}).DBAttributes(old...)
+	assert.Subset(t, got, old)
+	assert.Subset(t, got, stable)
+	assert.Len(t, got, len(old)+len(stable)-1)
+
+	var c *Config
+	assert.Equal(t, old, c.DBAttributes(old...))
+	// HTTP stability does not apply to database attributes.
+	assert.Equal(t, old, (&Config{HTTPSemconv: SemconvStable}).DBAttributes(old...))
+}
+
+func TestHTTPAttributes(t *testing.T) {
+	old := []attribute.KeyValue{
+		semconv.HTTPMethodKey.String("PURGE"),
+		semconv.HTTPStatusCodeKey.Int(200),
+		semconv.HTTPTargetKey.String("/users?id=1"),
+		semconv.HTTPSchemeHTTPS,
+		semconv.HTTPFlavorKey.String("1.1"),
+		semconv.HTTPUserAgentKey.String("test"),
+		semconv.HTTPRouteKey.String("/users"),
+		semconv.NetHostNameKey.String("example.com"),
+		semconv.NetSockPeerAddrKey.String("127.0.0.1"),
+		semconv.NetSockFamilyInet6,
+	}
+	stable := []attribute.KeyValue{
+		attribute.String("http.request.method", "_OTHER"),
+		attribute.String("http.request.method_original", "PURGE"),
+		attribute.Int("http.response.status_code", 200),
+		attribute.String("url.path", "/users"),
+		attribute.String("url.query", "id=1"),
+		attribute.String("url.scheme", "https"),
+		attribute.String("network.protocol.version", "1.1"),
+		attribute.String("user_agent.original", "test"),
+		semconv.HTTPRouteKey.String("/users"),
+		attribute.String("server.address", "example.com"),
+		attribute.String("network.peer.address", "127.0.0.1"),
+		attribute.String("network.type", "ipv6"),
+	}
+
+	assert.Equal(t, old, (&Config{}).HTTPAttributes(old...))
+	assert.Equal(t, stable, (&Config{HTTPSemconv: SemconvStable}).HTTPAttributes(old...))
+	got := (&Config{HTTPSemconv: SemconvDup}).HTTPAttributes(old...)
+	assert.Subset(t, got, old)
+	assert.Subset(t, got, stable)
+}
@@ -15,7 +15,10 @@
 package option
 
 import (
+	"net/http"
+
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/trace"
 
@@ -33,6 +36,12 @@ func WithTracerProvider(tp trace.TracerProvider) Option {
 	return Option(internal.WithTracerProvider(tp))
 }
 
+// WithMeterProvider returns an Option that sets the MeterProvider used with
+// this instrumentation library.
+func WithMeterProvider(mp metric.MeterProvider) Option {
+	return Option(internal.WithMeterProvider(mp))
+}
+
 // WithAttributes returns an Option that appends attr to the attributes set
 // for every span created with this instrumentation library.
 func WithAttributes(attr []attribute.KeyValue) Option {
@@ -44,3 +53,34 @@ func WithAttributes(attr []attribute.KeyValue) Option {
 func WithPropagator(p propagation.TextMapPropagator) Option {
 	return Option(internal.WithPropagator(p))
 }
+
+// Operation describes a Kubernetes API request traced by this
+// instrumentation library.
+type Operation struct {
+	// Request is the HTTP request sent to the Kubernetes API server.
+	Request *http.Request
+}
+
+// WithSpanNameFormatter returns an Option that sets f to name the spans
+// created for requests. If f returns an empty string the default span name,
+// "HTTP " followed by the method and tokenized API path, is used.
+func WithSpanNameFormatter(f func(Operation) string) Option {
+	return Option(internal.WithSpanNameFormatter(f))
+}
+
+// WithFilter returns an Option that adds f to the filters of the traced
+// requests. Requests f returns false for, e.g. watch requests, are not
+// traced.
+func WithFilter(f func(Operation) bool) Option {
+	return Option(internal.WithFilter(f))
+}
+
+// WithPeerServiceMapping returns an Option that maps Kubernetes API server
+// hosts to the logical name of the service they are part of, set as the
+// peer.service attribute of the spans. Hosts are identified by their name or
+// IP address, optionally followed by ":<port>". The entries of m take
+// precedence over the ones set with the
+// OTEL_INSTRUMENTATION_COMMON_PEER_SERVICE_MAPPING environment variable.
+func WithPeerServiceMapping(m map[string]string) Option {
+	return Option(internal.WithPeerServiceMapping(m))
+}
@@ -19,17 +19,26 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strconv"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	semconvnew "go.opentelemetry.io/otel/semconv/v1.37.0"
 	"go.opentelemetry.io/otel/trace"
 
+	splunkotel "github.com/signalfx/splunk-otel-go"
+	"github.com/signalfx/splunk-otel-go/instrumentation/instrumentationtest"
 	//nolint:staticcheck // Deprecated package, but still used.
 	splunkclientgo "github.com/signalfx/splunk-otel-go/instrumentation/k8s.io/client-go/splunkclient-go"
 	//nolint:staticcheck // Deprecated package, but still used.
@@ -38,7 +47,7 @@ import (
 	"github.com/signalfx/splunk-otel-go/instrumentation/k8s.io/client-go/splunkclient-go/transport"
 )
 
-func request(t *testing.T, handle func(http.ResponseWriter, *http.Request)) (*tracetest.SpanRecorder, *http.Response, string) {
+func request(t *testing.T, handle func(http.ResponseWriter, *http.Request), opts ...option.Option) (*tracetest.SpanRecorder, *http.Response, string) {
 	sr := tracetest.NewSpanRecorder()
 	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
 	prop := propagation.TraceContext{}
@@ -58,10 +67,11 @@ func request(t *testing.T, handle func(http.ResponseWriter, *http.Request)) (*tr
 	r, err := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL, http.NoBody)
 	require.NoError(t, err)
 
-	tr := transport.NewWrapperFunc(
+	opts = append([]option.Option{
 		option.WithPropagator(prop),
 		option.WithTracerProvider(tp),
-	)(http.DefaultTransport)
+	}, opts...)
+	tr := transport.NewWrapperFunc(opts...)(http.DefaultTransport)
 
 	c := http.Client{Transport: tr}
 	resp, err := c.Do(r)
@@ -110,3 +120,189 @@ func TestWrappedTransportErrorResponse(t *testing.T) {
 	assert.Equal(t, codes.Error, span.Status().Code)
 	assert.Equal(t, "", span.Status().Description)
 }
+
+func TestWrappedTransportSpanNameFormatter(t *testing.T) {
+	sr, resp, _ := request(t, func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}, option.WithSpanNameFormatter(func(op option.Operation) string {
+		return "k8s " + op.Request.Method
+	}))
+	require.NoError(t, resp.Body.Close())
+
+	require.Len(t, sr.Ended(), 1)
+	assert.Equal(t, "k8s GET", sr.Ended()[0].Name())
+}
+
+func TestWrappedTransportFilter(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+	t.Cleanup(func() { assert.NoError(t, tp.Shutdown(context.Background())) })
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(ts.Close)
+
+	tr := transport.NewWrapperFunc(
+		option.WithTracerProvider(tp),
+		option.WithFilter(func(op option.Operation) bool {
+			return op.Request.URL.Path != "/healthz"
+		}),
+	)(http.DefaultTransport)
+	c := http.Client{Transport: tr}
+
+	r, err := http.NewRequestWithContext(context.Background(), http.MethodGet, ts.URL+"/healthz", http.NoBody)
+	require.NoError(t, err)
+	resp, err := c.Do(r)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	assert.Empty(t, sr.Ended())
+}
+
+func TestWrappedTransportSuppressTracing(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+	t.Cleanup(func() { assert.NoError(t, tp.Shutdown(context.Background())) })
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(ts.Close)
+
+	tr := transport.NewWrapperFunc(option.WithTracerProvider(tp))(http.DefaultTransport)
+	c := http.Client{Transport: tr}
+
+	ctx := splunkotel.SuppressTracing(context.Background())
+	r, err := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL, http.NoBody)
+	require.NoError(t, err)
+	resp, err := c.Do(r)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	assert.Empty(t, sr.Ended())
+}
+
+func TestWrappedTransportDuration(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/namespaces" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(ts.Close)
+	u, err := url.Parse(ts.URL)
+	require.NoError(t, err)
+	port, err := strconv.Atoi(u.Port())
+	require.NoError(t, err)
+
+	reader := sdkmetric.NewManualReader()
+	tr := transport.NewWrapperFunc(
+		option.WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))),
+	)(http.DefaultTransport)
+	c := http.Client{Transport: tr}
+
+	for _, path := range []string{"/api/v1/namespaces", "/missing"} {
+		r, err := http.NewRequestWithContext(context.Background(), http.MethodGet, ts.URL+path, http.NoBody)
+		require.NoError(t, err)
+		resp, err := c.Do(r)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+	}
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+	require.Len(t, rm.ScopeMetrics[0].Metrics, 1)
+	m := rm.ScopeMetrics[0].Metrics[0]
+	assert.Equal(t, "http.client.request.duration", m.Name)
+	h, ok := m.Data.(metricdata.Histogram[float64])
+	require.True(t, ok)
+
+	attrs := []attribute.KeyValue{
+		semconvnew.HTTPRequestMethodGet,
+		semconvnew.ServerAddress(u.Hostname()),
+		semconvnew.ServerPort(port),
+	}
+	got := make([]attribute.Set, 0, len(h.DataPoints))
+	for _, dp := range h.DataPoints {
+		got = append(got, dp.Attributes)
+	}
+	assert.ElementsMatch(t, []attribute.Set{
+		attribute.NewSet(append(attrs, semconvnew.HTTPResponseStatusCode(http.StatusOK))...),
+		attribute.NewSet(append(attrs,
+			semconvnew.HTTPResponseStatusCode(http.StatusNotFound),
+			semconvnew.ErrorTypeKey.String("404"),
+		)...),
+	}, got)
+}
+
+func TestWrappedTransportPeerService(t *testing.T) {
+	t.Setenv("OTEL_INSTRUMENTATION_COMMON_PEER_SERVICE_MAPPING", "127.0.0.1=env-k8s")
+
+	tests := map[string]struct {
+		opts []option.Option
+		want string
+	}{
+		"env": {want: "env-k8s"},
+		"option": {
+			opts: []option.Option{option.WithPeerServiceMapping(map[string]string{"127.0.0.1": "option-k8s"})},
+			want: "option-k8s",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			sr, resp, _ := request(t, func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}, test.opts...)
+			require.NoError(t, resp.Body.Close())
+
+			spans := sr.Ended()
+			require.Len(t, spans, 1)
+			assert.Contains(t, spans[0].Attributes(), semconv.PeerServiceKey.String(test.want))
+		})
+	}
+}
+
+func TestConformance(t *testing.T) {
+	h := instrumentationtest.New(t)
+
+	var header http.Header
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(ts.Close)
+
+	tr := transport.NewWrapperFunc(
+		option.WithTracerProvider(h.TracerProvider),
+		option.WithMeterProvider(h.MeterProvider),
+		option.WithPropagator(h.Propagator),
+	)(http.DefaultTransport)
+	c := http.Client{Transport: tr}
+
+	ctx, parent := h.TracerProvider.Tracer("test").Start(context.Background(), "parent")
+	r, err := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+"/api/v1/namespaces/default/pods", http.NoBody)
+	require.NoError(t, err)
+	resp, err := c.Do(r)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	parent.End()
+
+	spans := h.Spans()
+	require.Len(t, spans, 2)
+	instrumentationtest.AssertConventions(t, spans)
+	instrumentationtest.AssertParent(t, spans[0], spans[1].SpanContext())
+	h.AssertPropagated(t, propagation.HeaderCarrier(header), spans[0])
+	instrumentationtest.AssertGolden(t, filepath.Join("testdata", "conformance.json"), spans,
+		// The port of the test server changes for every run.
+		instrumentationtest.WithIgnoredAttributes(semconv.HTTPURLKey, semconv.NetPeerPortKey),
+	)
+
+	_, ok := h.Metric("http.client.request.duration")
+	assert.True(t, ok, "request duration not recorded")
+}
@@ -18,11 +18,15 @@ import (
 	"context"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/semconv/v1.17.0/httpconv"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
 	"go.opentelemetry.io/otel/trace"
 	"k8s.io/client-go/transport"
 
@@ -47,6 +51,8 @@ func NewWrapperFunc(opts ...option.Option) transport.WrapperFunc {
 		o := append([]internal.Option{
 			internal.OptionFunc(func(c *internal.Config) {
 				c.Version = splunkclientgo.Version()
+				c.SpanSemconv = c.HTTPSemconv
+				c.MetricSemconv = internal.SemconvStable
 			}),
 		}, localToInternal(opts)...)
 
@@ -80,37 +86,80 @@ type roundTripper struct {
 var _ http.RoundTripper = (*roundTripper)(nil)
 
 func (rt *roundTripper) RoundTrip(r *http.Request) (resp *http.Response, err error) {
+	op := internal.Operation{
+		Name:       name(r),
+		Details:    option.Operation{Request: r},
+		Histogram:  internal.HTTPClientRequestDuration,
+		Attributes: metricAttributes(r),
+	}
+	if !rt.cfg.ShouldTrace(r.Context(), op) {
+		return rt.RoundTripper.RoundTrip(r)
+	}
+
 	const nLocalOpts = 2
 	opts := make([]trace.SpanStartOption, len(rt.cfg.DefaultStartOpts), len(rt.cfg.DefaultStartOpts)+nLocalOpts)
 	copy(opts, rt.cfg.DefaultStartOpts)
 	opts = append(
 		opts,
 		trace.WithSpanKind(trace.SpanKindClient),
-		trace.WithAttributes(httpconv.ClientRequest(r)...),
+		trace.WithAttributes(append(
+			rt.cfg.HTTPAttributes(httpconv.ClientRequest(r)...),
+			rt.cfg.PeerServices.URLAttributes(r.URL)...,
+		)...),
 	)
 
 	tracer := rt.cfg.ResolveTracer(r.Context())
-	ctx, span := tracer.Start(r.Context(), name(r), opts...)
+	ctx, span := tracer.Start(r.Context(), rt.cfg.SpanName(op), opts...)
 
 	// Ensure anything downstream knows about the started span.
 	r = r.WithContext(ctx)
 	rt.cfg.Propagator.Inject(ctx, propagation.HeaderCarrier(r.Header))
 
+	start := time.Now()
 	resp, err = rt.RoundTripper.RoundTrip(r)
+	elapsed := time.Since(start)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 		span.End()
+		rt.cfg.RecordDuration(ctx, op, elapsed, internal.ErrorType(err))
 		return resp, err
 	}
 
-	span.SetAttributes(httpconv.ClientResponse(resp)...)
+	span.SetAttributes(rt.cfg.HTTPAttributes(httpconv.ClientResponse(resp)...)...)
 	span.SetStatus(httpconv.ClientStatus(resp.StatusCode))
+	attrs := []attribute.KeyValue{semconv.HTTPResponseStatusCode(resp.StatusCode)}
+	if resp.StatusCode >= http.StatusBadRequest {
+		attrs = append(attrs, semconv.ErrorTypeKey.String(strconv.Itoa(resp.StatusCode)))
+	}
+	rt.cfg.RecordDuration(ctx, op, elapsed, attrs...)
 	resp.Body = &wrappedBody{ctx: ctx, span: span, body: resp.Body}
 
 	return resp, err
 }
 
+// metricAttributes returns the low cardinality attributes the duration of r
+// is recorded with.
+func metricAttributes(r *http.Request) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		internal.HTTPRequestMethod(r.Method),
+		semconv.ServerAddress(r.URL.Hostname()),
+	}
+	port, err := strconv.Atoi(r.URL.Port())
+	if err != nil {
+		switch r.URL.Scheme {
+		case "http":
+			port = 80
+		case "https":
+			port = 443
+		}
+	}
+	if port > 0 {
+		attrs = append(attrs, semconv.ServerPort(port))
+	}
+	return attrs
+}
+
 const (
 	prefixAPI   = "/api/v1/"
 	prefixWatch = "watch/"
@@ -0,0 +1,40 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkotel
+
+import "context"
+
+// suppressTracingKey is the context key tracing suppression is stored at.
+type suppressTracingKey struct{}
+
+// SuppressTracing returns a copy of ctx in which the Splunk instrumentations
+// do not create spans. The suppression applies to all the operations called
+// with ctx or a context derived from it, e.g. the requests of a background
+// maintenance job.
+func SuppressTracing(ctx context.Context) context.Context {
+	return context.WithValue(ctx, suppressTracingKey{}, true)
+}
+
+// UnsuppressTracing returns a copy of ctx in which the tracing suppressed by
+// SuppressTracing is enabled again.
+func UnsuppressTracing(ctx context.Context) context.Context {
+	return context.WithValue(ctx, suppressTracingKey{}, false)
+}
+
+// IsTracingSuppressed returns true if tracing is suppressed in ctx.
+func IsTracingSuppressed(ctx context.Context) bool {
+	suppressed, _ := ctx.Value(suppressTracingKey{}).(bool)
+	return suppressed
+}
@@ -0,0 +1,41 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkotel_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	splunkotel "github.com/signalfx/splunk-otel-go"
+)
+
+type ctxKey struct{}
+
+func TestSuppressTracing(t *testing.T) {
+	ctx := context.Background()
+	assert.False(t, splunkotel.IsTracingSuppressed(ctx))
+
+	ctx = splunkotel.SuppressTracing(ctx)
+	assert.True(t, splunkotel.IsTracingSuppressed(ctx))
+
+	// Suppression propagates to derived contexts.
+	nested, cancel := context.WithCancel(context.WithValue(ctx, ctxKey{}, "value"))
+	defer cancel()
+	assert.True(t, splunkotel.IsTracingSuppressed(nested))
+
+	assert.False(t, splunkotel.IsTracingSuppressed(splunkotel.UnsuppressTracing(nested)))
+}
@@ -14,7 +14,8 @@
 
 /*
 Package splunkotel provides version information for the Splunk distribution of
-OpenTelemetry Go.
+OpenTelemetry Go and the context helpers suppressing the tracing done by its
+instrumentations.
 */
 package splunkotel // import "github.com/signalfx/splunk-otel-go"
 